@@ -0,0 +1,105 @@
+package main
+
+import (
+	"os"
+	"time"
+
+	"github.com/pgvillage-tools/dbtwool/internal/arguments"
+	"github.com/pgvillage-tools/dbtwool/internal/profile"
+	db2 "github.com/pgvillage-tools/dbtwool/pkg/db2client"
+	"github.com/pgvillage-tools/dbtwool/pkg/secrets"
+)
+
+// newClient returns a DB2 client for the connection parameters and pool settings, where flags override the dsn, which
+// overrides env variables, which override the selected connection profile, which overrides the defaults
+func newClient(args arguments.Args) (db2.Client, error) {
+	prof, err := profile.Select(args.GetString(arguments.ArgCfgFile), args.GetString(arguments.ArgProfile))
+	if err != nil {
+		return db2.Client{}, err
+	}
+	flagPassword, err := passwordFromArgs(args)
+	if err != nil {
+		return db2.Client{}, err
+	}
+	fromDSN := db2.DB2ConnParams{}
+	if dsn := args.GetString(arguments.ArgDSN); dsn != "" {
+		if fromDSN, err = db2.ParseDSN(dsn); err != nil {
+			return db2.Client{}, err
+		}
+	}
+	// the passwords of the profile are only resolved when they are used, as they may run a password command
+	password, err := prof.Password.ResolveUnless(flagPassword, fromDSN["PWD"], os.Getenv("DB2_PASSWORD"))
+	if err != nil {
+		return db2.Client{}, err
+	}
+	keystorePassword, err := prof.TLS.KeystorePassword.ResolveUnless(fromDSN["SSLCLIENTKEYSTOREDBPASSWORD"],
+		os.Getenv("DB2_SSL_CLIENT_KEYSTOREDB_PASSWORD"))
+	if err != nil {
+		return db2.Client{}, err
+	}
+	defaults := db2.DB2ConnParams{}
+	for key, value := range prof.Options {
+		defaults[key] = value
+	}
+	for key, value := range map[string]string{
+		"HOSTNAME":                    prof.Host,
+		"PORT":                        prof.Port,
+		"DATABASE":                    prof.Database,
+		"UID":                         prof.User,
+		"PWD":                         password,
+		"PROTOCOL":                    prof.Protocol,
+		"SECURITY":                    prof.Security,
+		"SSLSERVERCERTIFICATE":        prof.TLS.RootCert,
+		"SSLCLIENTKEYSTOREDB":         prof.TLS.Keystore,
+		"SSLCLIENTKEYSTASH":           prof.TLS.Keystash,
+		"SSLCLIENTKEYSTOREDBPASSWORD": keystorePassword,
+		"SSLCLIENTLABEL":              prof.TLS.Label,
+		"ALTHOSTNAME":                 prof.AltHost,
+		"ALTPORT":                     prof.AltPort,
+	} {
+		if value != "" {
+			defaults[key] = value
+		}
+	}
+	params := db2.NewDB2ConnparamsFromEnvWithDefaults(defaults)
+	for key, value := range fromDSN {
+		params[key] = value
+	}
+	for key, arg := range map[string]string{
+		"HOSTNAME": arguments.ArgHost,
+		"PORT":     arguments.ArgPort,
+		"DATABASE": arguments.ArgDatabase,
+		"UID":      arguments.ArgUser,
+	} {
+		params[key] = profile.First(args.GetString(arg), params[key])
+	}
+	params["PWD"] = profile.First(flagPassword, params["PWD"])
+	for _, secret := range []string{params["PWD"], params["SSLCLIENTKEYSTOREDBPASSWORD"]} {
+		if err = secrets.Register(secret); err != nil {
+			logger.Warn().Err(err).Msg("A password is not masked in the output")
+		}
+	}
+	client := db2.NewClient(params)
+	client.PoolSettings = prof.Pool.Override(poolFromArgs(args)).Settings()
+	return client, nil
+}
+
+// passwordFromArgs returns the password from --passwordFile or --passwordCommand, or an empty string when neither is
+// set
+func passwordFromArgs(args arguments.Args) (string, error) {
+	return profile.Password{
+		File:    args.GetString(arguments.ArgPasswordFile),
+		Command: args.GetString(arguments.ArgPasswordCommand),
+	}.Resolve()
+}
+
+// poolFromArgs returns the pool settings which are set with flags
+func poolFromArgs(args arguments.Args) profile.Pool {
+	return profile.Pool{
+		MaxConns:        int(args.GetUint(arguments.ArgMaxConns)),
+		MinConns:        int(args.GetUint(arguments.ArgMinConns)),
+		MaxConnLifetime: time.Duration(args.GetUint(arguments.ArgMaxConnLifetime)) * time.Second,
+		MaxConnIdleTime: time.Duration(args.GetUint(arguments.ArgMaxConnIdleTime)) * time.Second,
+		ConnectTimeout:  time.Duration(args.GetUint(arguments.ArgConnectTimeout)) * time.Second,
+	}
+}
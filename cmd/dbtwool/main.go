@@ -1,52 +1,35 @@
 // Package main is the main entrypoint for dbwtool
 package main
 
-// cobra and viper are used to create a uniform interface on CLI and configuration file.
+// cobra is used to create a uniform interface on CLI, with connection profiles from the configuration file.
 import (
-	"fmt"
 	"os"
 	"strings"
 
+	"github.com/pgvillage-tools/dbtwool/internal/arguments"
+	"github.com/pgvillage-tools/dbtwool/internal/commands"
 	"github.com/pgvillage-tools/dbtwool/internal/version"
+	"github.com/pgvillage-tools/dbtwool/pkg/logging"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
-var (
-	cfgFile    string
-	globalArgs = []string{
-		"cfgFile",
-	}
-)
+var logger = logging.Logger()
 
-// requireSubcommand returns an error if no sub command is provided
-// This was copied from skopeo, which copied it from podman: `github.com/containers/podman/cmd/podman/validate/args.go
-// Some small style changes to match skopeo were applied, but try to apply any
-// bugfixes there first.
-func requireSubcommand(cmd *cobra.Command, args []string) error {
-	if len(args) > 0 {
-		suggestions := cmd.SuggestionsFor(args[0])
-		if len(suggestions) == 0 {
-			return fmt.Errorf("unrecognized command `%[1]s %[2]s`\nTry '%[1]s --help' for more information",
-				cmd.CommandPath(), args[0])
-		}
-		return fmt.Errorf(strings.Join(
-			[]string{"unrecognized command `%[1]s %[2]s`",
-				"",
-				"Did you mean this?",
-				"\t%[3]s",
-				"",
-				"Try '%[1]s --help' for more information"}, "\n"),
-			cmd.CommandPath(),
-			args[0],
-			strings.Join(suggestions, "\n\t"))
-	}
-	return fmt.Errorf(
-		strings.Join([]string{
-			"missing command '%[1]s COMMAND'",
-			"Try '%[1]s --help' for more information",
-		}, "\n"),
-		cmd.CommandPath())
+var globalArgs = []string{
+	arguments.ArgCfgFile,
+	arguments.ArgProfile,
+	arguments.ArgHost,
+	arguments.ArgPort,
+	arguments.ArgDatabase,
+	arguments.ArgUser,
+	arguments.ArgDSN,
+	arguments.ArgPasswordFile,
+	arguments.ArgPasswordCommand,
+	arguments.ArgMaxConns,
+	arguments.ArgMinConns,
+	arguments.ArgMaxConnLifetime,
+	arguments.ArgMaxConnIdleTime,
+	arguments.ArgConnectTimeout,
 }
 
 // createApp returns either a validly formed command for main() to run, or
@@ -54,15 +37,15 @@ func requireSubcommand(cmd *cobra.Command, args []string) error {
 // configuration file. Override the default location with -c,--cfgFile).
 // Override the target pg_hba.conf file with -f, --hbaFile
 func createApp() *cobra.Command {
-	// var appArgs args
-	cobra.OnInitialize(initConfig)
+	var rootArgs arguments.Args
 	rootCmd := &cobra.Command{
 		Use:   "dbtwool",
 		Short: "Run tests against DB2 and PostgreSQL",
 		Long: strings.Join([]string{
 			`dbtwool can be used to generate testdata and run tests against",
 			"PostgreSQL and DB2`}, " "),
-		RunE:              requireSubcommand,
+		RunE:              commands.RequireSubcommand,
+		PersistentPreRunE: commands.SetupLogging(&rootArgs),
 		CompletionOptions: cobra.CompletionOptions{},
 		TraverseChildren:  true,
 		Version:           version.GetAppVersion(),
@@ -70,51 +53,31 @@ func createApp() *cobra.Command {
 		// SilenceUsage: true,
 	}
 
-	viper.AddConfigPath(viper.GetString("cfgFile"))
-	err := viper.ReadInConfig()
-	if err == nil {
-		fmt.Printf("dbtwool is reading config from this config file: %s", viper.ConfigFileUsed())
-	}
-
 	rootCmd.AddCommand(
 		consistencyCommand(),
+		connectPerformanceCommand(),
+		failoverDrillCommand(),
+		commands.HistoryCommand(),
 		lobPerformanceCommand(),
+		commands.ReportCommand(),
 		ruCommand(),
+		workloadCommand(),
 	)
+	rootArgs = arguments.AllArgs.CommandArgs(rootCmd, commands.LogArgs)
 	return rootCmd
 }
 
 // Execute the fully formed pgcustodian command and handle any errors.
 func main() {
-	initLogger("")
 	rootCmd := createApp()
-	if err := rootCmd.Execute(); err != nil {
-		log.Fatal(err)
-	}
-	log.Info("finished")
-}
-
-// Read settings as key value pairs from the ".dbtwool" config file in the home directory.
-// This is (obscurely) referenced from the "createApp" function above.
-// TODO would this be clearer if moved above createApp?
-func initConfig() {
-	if cfgFile != "" {
-		// Use config file from the flag.
-		viper.SetConfigFile(cfgFile)
+	err := rootCmd.Execute()
+	if err != nil {
+		logger.Error().Err(err).Msg("failed")
 	} else {
-		// Find home directory.
-		home, err := os.UserHomeDir()
-		cobra.CheckErr(err)
-
-		// Search config in home directory with name ".cobra" (without extension).
-		viper.AddConfigPath(home)
-		viper.SetConfigType("yaml")
-		viper.SetConfigName(".dbtwool")
+		logger.Info().Msg("finished")
 	}
-
-	viper.AutomaticEnv()
-
-	if err := viper.ReadInConfig(); err == nil {
-		fmt.Println("Using config file:", viper.ConfigFileUsed())
+	commands.CloseLog()
+	if err != nil {
+		os.Exit(1)
 	}
 }
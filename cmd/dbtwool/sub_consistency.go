@@ -2,40 +2,51 @@ package main
 
 import (
 	"context"
-	"strconv"
+	"fmt"
 
+	"github.com/pgvillage-tools/dbtwool/internal/arguments"
 	db2 "github.com/pgvillage-tools/dbtwool/pkg/db2client"
 	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
 	"github.com/spf13/cobra"
 )
 
 func consistencyCommand() *cobra.Command {
-	var consistencyArgs args
+	var consistencyArgs arguments.Args
 	consistencyCommand := &cobra.Command{
 		Use:   "consistency",
 		Short: "Run a consistency test.",
 		Long:  `Use this command to test consistency with different transaction isolation levels.`,
-		Run: func(_ *cobra.Command, _ []string) {
-			iLevel, err := strconv.Atoi(consistencyArgs.GetString("isolationLevel"))
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			// the arguments are fine, so failures from here on do not need the usage
+			cmd.SilenceUsage = true
+			isolationLevel, err := db2.ParseIsolationLevel(consistencyArgs.GetString(arguments.ArgIsolationLevel))
 			if err != nil {
-				log.Info("Warning: invalid isolationLevel, defaulting to 1")
-				iLevel = 1
+				return fmt.Errorf("failed to parse the isolation level: %w", err)
 			}
 
-			cl1 := db2.NewClient(db2.NewDB2ConnparamsFromEnv())
-			dbinterface.ConsistencyTest(
+			cl1, clientErr := newClient(consistencyArgs)
+			if clientErr != nil {
+				return fmt.Errorf("failed to read the connection settings: %w", clientErr)
+			}
+			if testErr := dbinterface.ConsistencyTest(
 				context.Background(),
 				&cl1,
 				"SELECT AVG(price) AS avgprice FROM gotest.products;",
-				db2.GetIsolationLevel(iLevel),
+				isolationLevel,
+				sessionSettingsFromArgs(consistencyArgs),
 				"SELECT * FROM gotest.products FOR UPDATE;",
 				"UPDATE gotest.products SET price = 5000 where product_id = 1;",
-			)
+				dbinterface.DefaultConsistencyHoldTime,
+			); testErr != nil {
+				return fmt.Errorf("the consistency test failed: %w", testErr)
+			}
+			return nil
 		},
 	}
 
-	consistencyArgs = allArgs.commandArgs(consistencyCommand, append(globalArgs,
-		"isolationLevel",
+	consistencyArgs = arguments.AllArgs.CommandArgs(consistencyCommand, append(globalArgs,
+		arguments.ArgIsolationLevel,
+		arguments.ArgLockTimeout,
 	))
 	return consistencyCommand
 }
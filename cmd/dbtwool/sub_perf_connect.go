@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/pgvillage-tools/dbtwool/internal/arguments"
+	"github.com/pgvillage-tools/dbtwool/pkg/connperformance"
+	"github.com/pgvillage-tools/dbtwool/pkg/testrunner"
+	"github.com/spf13/cobra"
+)
+
+func connectPerformanceCommand() *cobra.Command {
+	var connectArgs arguments.Args
+	connectCommand := &cobra.Command{
+		Use:   "connect-performance",
+		Short: "test how fast new connections can be set up",
+		Long: "Use this command to repeatedly open and close new connections (outside of the pool) on parallel " +
+			"workers, and report the connections per second and the connect latency, including TLS and authentication.",
+		Run: func(_ *cobra.Command, _ []string) {
+			db2Client, clientErr := newClient(connectArgs)
+			if clientErr != nil {
+				logger.Error().Err(clientErr).Msg("An error occurred while reading the connection settings")
+				return
+			}
+
+			err := connperformance.ExecuteTest(
+				context.Background(),
+				&db2Client,
+				int(connectArgs.GetUint(arguments.ArgParallel)),
+				int(connectArgs.GetUint(arguments.ArgWarmupTime)),
+				int(connectArgs.GetUint(arguments.ArgExecutionTime)),
+				connectArgs.GetBool(arguments.ArgConnectQuery),
+				testrunner.ReconnectPolicy{
+					Backoff:    time.Duration(connectArgs.GetUint(arguments.ArgReconnectBackoff)) * time.Millisecond,
+					MaxBackoff: time.Duration(connectArgs.GetUint(arguments.ArgReconnectMaxBackoff)) * time.Millisecond,
+				})
+			if err != nil {
+				logger.Error().Err(err).Msg("An error occurred while trying to execute the connect test")
+			}
+		},
+	}
+
+	connectArgs = arguments.AllArgs.CommandArgs(
+		connectCommand,
+		append(
+			globalArgs,
+			arguments.ArgParallel,
+			arguments.ArgWarmupTime,
+			arguments.ArgExecutionTime,
+			arguments.ArgConnectQuery,
+			arguments.ArgReconnectBackoff,
+			arguments.ArgReconnectMaxBackoff))
+
+	return connectCommand
+}
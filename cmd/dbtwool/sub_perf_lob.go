@@ -6,7 +6,8 @@ import (
 	"fmt"
 	"strings"
 
-	db2 "github.com/pgvillage-tools/dbtwool/pkg/db2client"
+	"github.com/pgvillage-tools/dbtwool/internal/arguments"
+	"github.com/pgvillage-tools/dbtwool/internal/commands"
 	"github.com/pgvillage-tools/dbtwool/pkg/dbclient"
 	"github.com/pgvillage-tools/dbtwool/pkg/lobperformance"
 	"github.com/spf13/cobra"
@@ -19,7 +20,7 @@ func lobPerformanceCommand() *cobra.Command {
 		Long: "Use this command to create a test environment, " +
 			"create a workload, " +
 			"and execute a performance test for large objects.",
-		RunE: requireSubcommand,
+		RunE: commands.RequireSubcommand,
 	}
 
 	lobPerformanceCommand.AddCommand(
@@ -32,130 +33,209 @@ func lobPerformanceCommand() *cobra.Command {
 }
 
 func lobStageCommand() *cobra.Command {
-	var stageArgs args
+	var stageArgs arguments.Args
 	stageCommand := &cobra.Command{
 		Use:   "stage",
 		Short: "create tables",
 		Long:  "Create the necessary schema and table(s)",
 		Run: func(_ *cobra.Command, _ []string) {
-			schema, table, err := parseSchemaTable(stageArgs.GetString(ArgTable))
+			schema, table, err := parseSchemaTable(stageArgs.GetString(arguments.ArgTable))
 
 			if err == nil {
-				params := db2.NewDB2ConnparamsFromEnv()
-				db2Client := db2.NewClient(params)
-				lobperformance.Stage(context.Background(), dbclient.DB2, &db2Client, schema, table)
+				db2Client, clientErr := newClient(stageArgs)
+				if clientErr != nil {
+					logger.Error().Err(clientErr).Msg("An error occurred while reading the connection settings")
+					return
+				}
+
+				stopTracing, tracingErr := commands.StartTracing(stageArgs, dbclient.DB2)
+				if tracingErr != nil {
+					logger.Error().Err(tracingErr).Msg("An error occurred while setting up tracing")
+					return
+				}
+				defer stopTracing()
+				if stageErr := lobperformance.Stage(
+					context.Background(),
+					dbclient.DB2,
+					&db2Client,
+					schema, table); stageErr != nil {
+					logger.Error().Err(stageErr).Msg("An error occurred while staging LOB performance")
+				}
 			} else {
-				fmt.Printf("An error occurred while parsing the schema + table: %v", err)
+				logger.Error().Err(err).Msg("An error occurred while parsing the schema + table")
 			}
 		},
 	}
 
-	stageArgs = allArgs.commandArgs(stageCommand, append(globalArgs, ArgTable))
+	stageArgs = arguments.AllArgs.CommandArgs(stageCommand, append(globalArgs, arguments.ArgTable,
+		arguments.ArgTraceEndpoint, arguments.ArgTraceFile, arguments.ArgTraceQuerySample))
 
 	return stageCommand
 }
 
 func lobGenCommand() *cobra.Command {
-	var genArgs args
+	var genArgs arguments.Args
 	genCommand := &cobra.Command{
 		Use:   "gen",
 		Short: "generate all the things",
 		Long:  "Use this command to generate data to test with.",
 		Run: func(_ *cobra.Command, _ []string) {
-			schema, table, err := parseSchemaTable(genArgs.GetString(ArgTable))
+			schema, table, err := parseSchemaTable(genArgs.GetString(arguments.ArgTable))
 			if err == nil {
-				useBulkInsertion := genArgs.GetBool(ArgBulkInsert)
-				params := db2.NewDB2ConnparamsFromEnv()
-				db2Client := db2.NewClient(params)
+				useBulkInsertion := genArgs.GetBool(arguments.ArgBulkInsert)
+				db2Client, clientErr := newClient(genArgs)
+				if clientErr != nil {
+					logger.Error().Err(clientErr).Msg("An error occurred while reading the connection settings")
+					return
+				}
+
+				stopTracing, tracingErr := commands.StartTracing(genArgs, dbclient.DB2)
+				if tracingErr != nil {
+					logger.Error().Err(tracingErr).Msg("An error occurred while setting up tracing")
+					return
+				}
+				defer stopTracing()
+
+				stopMetrics, metricsErr := commands.ServeMetrics(genArgs)
+				if metricsErr != nil {
+					logger.Error().Err(metricsErr).Msg("An error occurred while serving the metrics")
+					return
+				}
+				defer stopMetrics()
 
 				if useBulkInsertion {
-					lobperformance.GenerateBulk(
+					if genErr := lobperformance.GenerateBulk(
 						context.Background(),
 						dbclient.DB2,
 						&db2Client,
 						schema,
 						table,
-						genArgs.GetStringSlice(ArgSpread),
-						int64(genArgs.GetUint(ArgEmptyLobs)),
-						genArgs.GetString(ArgByteSize),
-						int(genArgs.GetUint(ArgBatchSize)),
-						genArgs.GetString(ArgLobType))
+						genArgs.GetStringSlice(arguments.ArgSpread),
+						int64(genArgs.GetUint(arguments.ArgEmptyLobs)),
+						genArgs.GetString(arguments.ArgByteSize),
+						int(genArgs.GetUint(arguments.ArgBatchSize)),
+						genArgs.GetString(arguments.ArgLobType)); genErr != nil {
+						logger.Error().Err(genErr).Msg("An error occurred while generating LOB data")
+					}
 				} else {
-					lobperformance.Generate(
+					if genErr := lobperformance.Generate(
 						context.Background(),
 						dbclient.DB2,
 						&db2Client,
 						schema,
 						table,
-						genArgs.GetStringSlice(ArgSpread),
-						int64(genArgs.GetUint(ArgEmptyLobs)),
-						genArgs.GetString(ArgByteSize),
-						int(genArgs.GetUint(ArgBatchSize)),
-						genArgs.GetString(ArgLobType))
+						genArgs.GetStringSlice(arguments.ArgSpread),
+						int64(genArgs.GetUint(arguments.ArgEmptyLobs)),
+						genArgs.GetString(arguments.ArgByteSize),
+						int(genArgs.GetUint(arguments.ArgBatchSize)),
+						genArgs.GetString(arguments.ArgLobType)); genErr != nil {
+						logger.Error().Err(genErr).Msg("An error occurred while generating LOB data")
+					}
 				}
 			} else {
-				fmt.Printf("An error occurred while parsing the schema + table: %v", err)
+				logger.Error().Err(err).Msg("An error occurred while parsing the schema + table")
 			}
 		},
 	}
 
-	genArgs = allArgs.commandArgs(genCommand, append(
+	genArgs = arguments.AllArgs.CommandArgs(genCommand, append(
 		globalArgs,
-		ArgSpread,
-		ArgByteSize,
-		ArgTable,
-		ArgEmptyLobs,
-		ArgLobType,
-		ArgBatchSize,
-		ArgBulkInsert))
+		arguments.ArgSpread,
+		arguments.ArgByteSize,
+		arguments.ArgTable,
+		arguments.ArgEmptyLobs,
+		arguments.ArgLobType,
+		arguments.ArgBatchSize,
+		arguments.ArgBulkInsert,
+		arguments.ArgMetricsListen,
+		arguments.ArgTraceEndpoint,
+		arguments.ArgTraceFile,
+		arguments.ArgTraceQuerySample))
 	return genCommand
 }
 
 func lobTestCommand() *cobra.Command {
-	var testExecutionArgs args
+	var testExecutionArgs arguments.Args
 	testExecutionCommand := &cobra.Command{
 		Use:   "test",
 		Short: "run the test",
 		Long:  "Use this command to run the test on the earlier created data.",
 		Run: func(_ *cobra.Command, _ []string) {
-			schema, table, err := parseSchemaTable(testExecutionArgs.GetString(ArgTable))
+			schema, table, err := parseSchemaTable(testExecutionArgs.GetString(arguments.ArgTable))
 
 			if err == nil {
-				params := db2.NewDB2ConnparamsFromEnv()
-				db2Client := db2.NewClient(params)
+				db2Client, clientErr := newClient(testExecutionArgs)
+				if clientErr != nil {
+					logger.Error().Err(clientErr).Msg("An error occurred while reading the connection settings")
+					return
+				}
+
+				stopTracing, tracingErr := commands.StartTracing(testExecutionArgs, dbclient.DB2)
+				if tracingErr != nil {
+					logger.Error().Err(tracingErr).Msg("An error occurred while setting up tracing")
+					return
+				}
+				defer stopTracing()
+
+				stopMetrics, metricsErr := commands.ServeMetrics(testExecutionArgs)
+				if metricsErr != nil {
+					logger.Error().Err(metricsErr).Msg("An error occurred while serving the metrics")
+					return
+				}
+				defer stopMetrics()
 
+				run := commands.NewRun(testExecutionArgs, dbclient.DB2, "lob-performance", schema, table)
 				err := lobperformance.ExecuteTest(
 					context.Background(),
 					dbclient.DB2,
 					&db2Client,
 					schema,
 					table,
-					testExecutionArgs.GetString(ArgRandomizerSeed),
-					int(testExecutionArgs.GetUint(ArgParallel)),
-					int(testExecutionArgs.GetUint(ArgWarmupTime)),
-					int(testExecutionArgs.GetUint(ArgExecutionTime)),
-					testExecutionArgs.GetString(ArgReadMode),
-					testExecutionArgs.GetString(ArgLobType))
+					testExecutionArgs.GetString(arguments.ArgRandomizerSeed),
+					int(testExecutionArgs.GetUint(arguments.ArgParallel)),
+					int(testExecutionArgs.GetUint(arguments.ArgWarmupTime)),
+					int(testExecutionArgs.GetUint(arguments.ArgExecutionTime)),
+					testExecutionArgs.GetString(arguments.ArgReadMode),
+					testExecutionArgs.GetString(arguments.ArgLobType),
+					sessionSettingsFromArgs(testExecutionArgs),
+					reconnectPolicyFromArgs(testExecutionArgs),
+					run)
 
 				if err != nil {
-					fmt.Printf("An error occurred while trying to execute the LOB performance test: %v", err)
+					logger.Error().Err(err).Msg("An error occurred while trying to execute the LOB performance test")
+					return
 				}
+				commands.WriteResult(testExecutionArgs, run)
 			} else {
-				fmt.Printf("An error occurred while parsing the schema + table: %v", err)
+				logger.Error().Err(err).Msg("An error occurred while parsing the schema + table")
 			}
 		},
 	}
 
-	testExecutionArgs = allArgs.commandArgs(
+	testExecutionArgs = arguments.AllArgs.CommandArgs(
 		testExecutionCommand,
 		append(globalArgs,
-			ArgTable,
-			ArgRandomizerSeed,
-			ArgParallel,
-			ArgWarmupTime,
-			ArgExecutionTime,
-			ArgReadMode,
-			ArgLobType),
+			arguments.ArgTable,
+			arguments.ArgRandomizerSeed,
+			arguments.ArgParallel,
+			arguments.ArgWarmupTime,
+			arguments.ArgExecutionTime,
+			arguments.ArgReadMode,
+			arguments.ArgLobType,
+			arguments.ArgLockTimeout,
+			arguments.ArgReconnect,
+			arguments.ArgReconnectBackoff,
+			arguments.ArgReconnectMaxBackoff,
+			arguments.ArgResultFile,
+			arguments.ArgHistoryFile,
+			arguments.ArgHistoryDSN,
+			arguments.ArgServerMetricsInterval,
+			arguments.ArgClientMetricsInterval,
+			arguments.ArgReportInterval,
+			arguments.ArgMetricsListen,
+			arguments.ArgTraceEndpoint,
+			arguments.ArgTraceFile,
+			arguments.ArgTraceQuerySample),
 	)
 
 	return testExecutionCommand
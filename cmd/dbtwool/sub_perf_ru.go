@@ -2,13 +2,16 @@ package main
 
 import (
 	"context"
-	"fmt"
-	"strconv"
 	"strings"
+	"time"
 
+	"github.com/pgvillage-tools/dbtwool/internal/arguments"
+	"github.com/pgvillage-tools/dbtwool/internal/commands"
 	db2 "github.com/pgvillage-tools/dbtwool/pkg/db2client"
 	"github.com/pgvillage-tools/dbtwool/pkg/dbclient"
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
 	"github.com/pgvillage-tools/dbtwool/pkg/ruperformance"
+	"github.com/pgvillage-tools/dbtwool/pkg/testrunner"
 	"github.com/spf13/cobra"
 )
 
@@ -21,7 +24,7 @@ func ruCommand() *cobra.Command {
 			"create a workload",
 			"and execute a performance test for read uncommitted isolation level.",
 		}, ", "),
-		RunE: requireSubcommand,
+		RunE: commands.RequireSubcommand,
 	}
 
 	ruPerformanceCommand.AddCommand(
@@ -34,104 +37,214 @@ func ruCommand() *cobra.Command {
 }
 
 func ruStageCommand() *cobra.Command {
-	var stageArgs args
+	var stageArgs arguments.Args
 	stageCommand := &cobra.Command{
 		Use:   "stage",
 		Short: "create tables",
 		Long:  "Create the necessary schema and table(s)",
 		Run: func(_ *cobra.Command, _ []string) {
-			schema, table, err := parseSchemaTable(stageArgs.GetString(ArgTable))
+			schema, table, err := parseSchemaTable(stageArgs.GetString(arguments.ArgTable))
 
 			if err == nil {
-				params := db2.NewDB2ConnparamsFromEnv()
-				db2Client := db2.NewClient(params)
+				db2Client, clientErr := newClient(stageArgs)
+				if clientErr != nil {
+					logger.Error().Err(clientErr).Msg("An error occurred while reading the connection settings")
+					return
+				}
+
+				stopTracing, tracingErr := commands.StartTracing(stageArgs, dbclient.DB2)
+				if tracingErr != nil {
+					logger.Error().Err(tracingErr).Msg("An error occurred while setting up tracing")
+					return
+				}
+				defer stopTracing()
 				if stageErr := ruperformance.Stage(
 					context.Background(),
 					dbclient.DB2,
 					&db2Client,
 					schema, table); stageErr != nil {
-					fmt.Printf("An error occurred while staging RU performance: %v", stageErr)
+					logger.Error().Err(stageErr).Msg("An error occurred while staging RU performance")
 					return
 				}
 			} else {
-				fmt.Printf("An error occurred while parsing the schema + table: %v", err)
+				logger.Error().Err(err).Msg("An error occurred while parsing the schema + table")
 			}
 		},
 	}
 
-	stageArgs = allArgs.commandArgs(stageCommand, append(globalArgs, ArgTable))
+	stageArgs = arguments.AllArgs.CommandArgs(stageCommand, append(globalArgs, arguments.ArgTable,
+		arguments.ArgTraceEndpoint, arguments.ArgTraceFile, arguments.ArgTraceQuerySample))
 
 	return stageCommand
 }
 
 func ruGenCommand() *cobra.Command {
-	var genArgs args
+	var genArgs arguments.Args
 	genCommand := &cobra.Command{
 		Use:   "gen",
 		Short: "generate all the things",
 		Long:  "Use this command to generate data to test with.",
 		Run: func(_ *cobra.Command, _ []string) {
-			schema, table, err := parseSchemaTable(genArgs.GetString(ArgTable))
+			schema, table, err := parseSchemaTable(genArgs.GetString(arguments.ArgTable))
 
 			if err == nil {
-				params := db2.NewDB2ConnparamsFromEnv()
-				db2Client := db2.NewClient(params)
+				db2Client, clientErr := newClient(genArgs)
+				if clientErr != nil {
+					logger.Error().Err(clientErr).Msg("An error occurred while reading the connection settings")
+					return
+				}
 
-				ruperformance.Generate(
+				stopTracing, tracingErr := commands.StartTracing(genArgs, dbclient.DB2)
+				if tracingErr != nil {
+					logger.Error().Err(tracingErr).Msg("An error occurred while setting up tracing")
+					return
+				}
+				defer stopTracing()
+
+				stopMetrics, metricsErr := commands.ServeMetrics(genArgs)
+				if metricsErr != nil {
+					logger.Error().Err(metricsErr).Msg("An error occurred while serving the metrics")
+					return
+				}
+				defer stopMetrics()
+
+				if genErr := ruperformance.Generate(
 					context.Background(),
 					dbclient.DB2,
 					&db2Client,
 					schema,
 					table,
-					int64(genArgs.GetUint(ArgNumOfRows)))
+					int64(genArgs.GetUint(arguments.ArgNumOfRows))); genErr != nil {
+					logger.Error().Err(genErr).Msg("An error occurred while generating RU performance data")
+				}
 			} else {
-				fmt.Printf("An error occurred while parsing the schema + table: %v", err)
+				logger.Error().Err(err).Msg("An error occurred while parsing the schema + table")
 			}
 		},
 	}
-	genArgs = allArgs.commandArgs(genCommand, append(globalArgs, ArgTable, ArgNumOfRows))
+	genArgs = arguments.AllArgs.CommandArgs(genCommand,
+		append(globalArgs, arguments.ArgTable, arguments.ArgNumOfRows, arguments.ArgMetricsListen,
+			arguments.ArgTraceEndpoint, arguments.ArgTraceFile, arguments.ArgTraceQuerySample))
 	return genCommand
 }
 
 func ruTestCommand() *cobra.Command {
-	var testExecutionArgs args
+	var testExecutionArgs arguments.Args
 	testExecutionCommand := &cobra.Command{
 		Use:   "test",
 		Short: "run the test",
 		Long:  "Use this command to run the test on the earlier created data.",
 		Run: func(_ *cobra.Command, _ []string) {
-			schema, table, tableParseErr := parseSchemaTable(testExecutionArgs.GetString(ArgTable))
+			schema, table, tableParseErr := parseSchemaTable(testExecutionArgs.GetString(arguments.ArgTable))
 			if tableParseErr != nil {
-				fmt.Printf("An error occurred while parsing the schema + table: %v", tableParseErr)
+				logger.Error().Err(tableParseErr).Msg("An error occurred while parsing the schema + table")
+				return
 			}
 
-			iLevel, isolationParseErr := strconv.Atoi(testExecutionArgs.GetString(ArgIsolationLevel))
+			isolationLevel, isolationParseErr := db2.ParseIsolationLevel(
+				testExecutionArgs.GetString(arguments.ArgIsolationLevel))
 
 			if isolationParseErr == nil {
-				params := db2.NewDB2ConnparamsFromEnv()
-				db2Client := db2.NewClient(params)
+				retryPolicy, policyErr := retryPolicyFromArgs(testExecutionArgs)
+				if policyErr != nil {
+					logger.Error().Err(policyErr).Msg("An error occurred while parsing the retry policy")
+					return
+				}
+
+				db2Client, clientErr := newClient(testExecutionArgs)
+				if clientErr != nil {
+					logger.Error().Err(clientErr).Msg("An error occurred while reading the connection settings")
+					return
+				}
+
+				stopTracing, tracingErr := commands.StartTracing(testExecutionArgs, dbclient.DB2)
+				if tracingErr != nil {
+					logger.Error().Err(tracingErr).Msg("An error occurred while setting up tracing")
+					return
+				}
+				defer stopTracing()
 
+				stopMetrics, metricsErr := commands.ServeMetrics(testExecutionArgs)
+				if metricsErr != nil {
+					logger.Error().Err(metricsErr).Msg("An error occurred while serving the metrics")
+					return
+				}
+				defer stopMetrics()
+
+				run := commands.NewRun(testExecutionArgs, dbclient.DB2, "ru-performance", schema, table)
 				err := ruperformance.ExecuteTest(
 					context.Background(),
 					dbclient.DB2,
 					&db2Client,
 					schema,
 					table,
-					int(testExecutionArgs.GetUint(ArgWarmupTime)),
-					int(testExecutionArgs.GetUint(ArgExecutionTime)),
-					db2.GetIsolationLevel(iLevel))
+					int(testExecutionArgs.GetUint(arguments.ArgWarmupTime)),
+					int(testExecutionArgs.GetUint(arguments.ArgExecutionTime)),
+					int(testExecutionArgs.GetUint(arguments.ArgOltpWorkers)),
+					int(testExecutionArgs.GetUint(arguments.ArgOlapWorkers)),
+					isolationLevel,
+					retryPolicy,
+					sessionSettingsFromArgs(testExecutionArgs),
+					reconnectPolicyFromArgs(testExecutionArgs),
+					run)
 				if err != nil {
-					fmt.Printf("An error occurred while trying to execute the RU performance test: %v", err)
+					logger.Error().Err(err).Msg("An error occurred while trying to execute the RU performance test")
+					return
 				}
+				commands.WriteResult(testExecutionArgs, run)
 			} else {
-				fmt.Printf("An error occurred while parsing the isolation level: %v", isolationParseErr)
+				logger.Error().Err(isolationParseErr).Msg("An error occurred while parsing the isolation level")
 			}
 		},
 	}
 
-	testExecutionArgs = allArgs.commandArgs(
+	testExecutionArgs = arguments.AllArgs.CommandArgs(
 		testExecutionCommand,
-		append(globalArgs, ArgTable, ArgWarmupTime, ArgExecutionTime, ArgIsolationLevel))
+		append(globalArgs,
+			arguments.ArgTable,
+			arguments.ArgWarmupTime,
+			arguments.ArgExecutionTime,
+			arguments.ArgOltpWorkers,
+			arguments.ArgOlapWorkers,
+			arguments.ArgIsolationLevel,
+			arguments.ArgMaxRetries,
+			arguments.ArgRetryOn,
+			arguments.ArgRetryBackoff,
+			arguments.ArgLockTimeout,
+			arguments.ArgReconnect,
+			arguments.ArgReconnectBackoff,
+			arguments.ArgReconnectMaxBackoff,
+			arguments.ArgResultFile,
+			arguments.ArgHistoryFile,
+			arguments.ArgHistoryDSN,
+			arguments.ArgServerMetricsInterval,
+			arguments.ArgClientMetricsInterval,
+			arguments.ArgReportInterval,
+			arguments.ArgMetricsListen,
+			arguments.ArgTraceEndpoint,
+			arguments.ArgTraceFile,
+			arguments.ArgTraceQuerySample))
 
 	return testExecutionCommand
 }
+
+func retryPolicyFromArgs(args arguments.Args) (testrunner.RetryPolicy, error) {
+	return testrunner.NewRetryPolicy(
+		int(args.GetUint(arguments.ArgMaxRetries)),
+		args.GetStringSlice(arguments.ArgRetryOn),
+		time.Duration(args.GetUint(arguments.ArgRetryBackoff))*time.Millisecond)
+}
+
+func reconnectPolicyFromArgs(args arguments.Args) testrunner.ReconnectPolicy {
+	return testrunner.ReconnectPolicy{
+		Enabled:    args.GetBool(arguments.ArgReconnect),
+		Backoff:    time.Duration(args.GetUint(arguments.ArgReconnectBackoff)) * time.Millisecond,
+		MaxBackoff: time.Duration(args.GetUint(arguments.ArgReconnectMaxBackoff)) * time.Millisecond,
+	}
+}
+
+func sessionSettingsFromArgs(args arguments.Args) dbinterface.SessionSettings {
+	return dbinterface.SessionSettings{
+		LockTimeout: time.Duration(args.GetUint(arguments.ArgLockTimeout)) * time.Millisecond,
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"time"
+
+	"github.com/pgvillage-tools/dbtwool/internal/arguments"
+	"github.com/pgvillage-tools/dbtwool/internal/profile"
+	"github.com/pgvillage-tools/dbtwool/pkg/sqlite"
+)
+
+// newClient returns a SQLite client with the schemas attached as database files. Flags override env variables, which
+// override the selected connection profile, which overrides the defaults.
+func newClient(args arguments.Args, schemas ...string) (sqlite.Client, error) {
+	prof, err := profile.Select(args.GetString(arguments.ArgCfgFile), args.GetString(arguments.ArgProfile))
+	if err != nil {
+		return sqlite.Client{}, err
+	}
+	params := sqlite.ConnParamsFromEnvWithDefaults(sqlite.ConnParams{
+		Path:        prof.Database,
+		BusyTimeout: prof.BusyTimeout,
+	})
+	params.Path = profile.First(args.GetString(arguments.ArgDatabase), params.Path)
+	params.Schemas = schemas
+	client := sqlite.NewClient(params)
+	client.PoolSettings = prof.Pool.Override(poolFromArgs(args)).Settings()
+	return client, nil
+}
+
+// poolFromArgs returns the pool settings which are set with flags
+func poolFromArgs(args arguments.Args) profile.Pool {
+	return profile.Pool{
+		MaxConns:        int(args.GetUint(arguments.ArgMaxConns)),
+		MinConns:        int(args.GetUint(arguments.ArgMinConns)),
+		MaxConnLifetime: time.Duration(args.GetUint(arguments.ArgMaxConnLifetime)) * time.Second,
+		MaxConnIdleTime: time.Duration(args.GetUint(arguments.ArgMaxConnIdleTime)) * time.Second,
+	}
+}
@@ -0,0 +1,73 @@
+// Package main is the main entrypoint for dbwtool
+package main
+
+// cobra is used to create a uniform interface on CLI, with connection profiles from the configuration file.
+import (
+	"os"
+	"strings"
+
+	"github.com/pgvillage-tools/dbtwool/internal/arguments"
+	"github.com/pgvillage-tools/dbtwool/internal/commands"
+	"github.com/pgvillage-tools/dbtwool/internal/version"
+	"github.com/pgvillage-tools/dbtwool/pkg/logging"
+	"github.com/spf13/cobra"
+)
+
+var logger = logging.Logger()
+
+var globalArgs = []string{
+	arguments.ArgCfgFile,
+	arguments.ArgProfile,
+	arguments.ArgDatabase,
+	arguments.ArgMaxConns,
+	arguments.ArgMinConns,
+	arguments.ArgMaxConnLifetime,
+	arguments.ArgMaxConnIdleTime,
+}
+
+// createApp returns either a validly formed command for main() to run, or
+// an error. Initializes a cobra command structure using the settings from the
+// configuration file. Override the default location with -c,--cfgFile).
+// Override the target pg_hba.conf file with -f, --hbaFile
+func createApp() *cobra.Command {
+	var rootArgs arguments.Args
+	rootCmd := &cobra.Command{
+		Use:   "litetwool",
+		Short: "Run tests against an embedded SQLite database",
+		Long: strings.Join([]string{
+			"litetwool can be used to generate testdata and run tests against",
+			"an embedded SQLite database, without running a database server"}, " "),
+		RunE:              commands.RequireSubcommand,
+		PersistentPreRunE: commands.SetupLogging(&rootArgs),
+		CompletionOptions: cobra.CompletionOptions{},
+		TraverseChildren:  true,
+		Version:           version.GetAppVersion(),
+		// SilenceErrors: true,
+		// SilenceUsage: true,
+	}
+
+	rootCmd.AddCommand(
+		commands.HistoryCommand(),
+		lobPerformanceCommand(),
+		commands.ReportCommand(),
+		ruCommand(),
+		workloadCommand(),
+	)
+	rootArgs = arguments.AllArgs.CommandArgs(rootCmd, commands.LogArgs)
+	return rootCmd
+}
+
+// Execute the fully formed pgcustodian command and handle any errors.
+func main() {
+	rootCmd := createApp()
+	err := rootCmd.Execute()
+	if err != nil {
+		logger.Error().Err(err).Msg("failed")
+	} else {
+		logger.Info().Msg("finished")
+	}
+	commands.CloseLog()
+	if err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pgvillage-tools/dbtwool/internal/arguments"
+	"github.com/pgvillage-tools/dbtwool/internal/commands"
+	"github.com/pgvillage-tools/dbtwool/pkg/dbclient"
+	"github.com/pgvillage-tools/dbtwool/pkg/lobperformance"
+	"github.com/spf13/cobra"
+)
+
+func lobPerformanceCommand() *cobra.Command {
+	lobPerformanceCommand := &cobra.Command{
+		Use:   "lob-performance",
+		Short: "test db performance with large objects",
+		Long: "Use this command to create a test environment, " +
+			"create a workload, " +
+			"and execute a performance test for large objects.",
+		RunE: commands.RequireSubcommand,
+	}
+
+	lobPerformanceCommand.AddCommand(
+		lobStageCommand(),
+		lobGenCommand(),
+		lobTestCommand(),
+	)
+
+	return lobPerformanceCommand
+}
+
+func lobStageCommand() *cobra.Command {
+	var stageArgs arguments.Args
+	stageCommand := &cobra.Command{
+		Use:   "stage",
+		Short: "create tables",
+		Long:  "Create the necessary schema and table(s)",
+		Run: func(_ *cobra.Command, _ []string) {
+			schema, table, err := parseSchemaTable(stageArgs.GetString(arguments.ArgTable))
+
+			if err == nil {
+				sqliteClient, clientErr := newClient(stageArgs, schema)
+				if clientErr != nil {
+					logger.Error().Err(clientErr).Msg("An error occurred while reading the connection settings")
+					return
+				}
+
+				stopTracing, tracingErr := commands.StartTracing(stageArgs, dbclient.SQLite)
+				if tracingErr != nil {
+					logger.Error().Err(tracingErr).Msg("An error occurred while setting up tracing")
+					return
+				}
+				defer stopTracing()
+
+				if stageErr := lobperformance.Stage(
+					context.Background(),
+					dbclient.SQLite,
+					&sqliteClient,
+					schema, table); stageErr != nil {
+					logger.Error().Err(stageErr).Msg("An error occurred while staging LOB performance")
+				}
+			} else {
+				logger.Error().Err(err).Msg("An error occurred while parsing the schema + table")
+			}
+		},
+	}
+
+	stageArgs = arguments.AllArgs.CommandArgs(stageCommand, append(globalArgs, arguments.ArgTable,
+		arguments.ArgTraceEndpoint, arguments.ArgTraceFile, arguments.ArgTraceQuerySample))
+
+	return stageCommand
+}
+
+func lobGenCommand() *cobra.Command {
+	var genArgs arguments.Args
+	genCommand := &cobra.Command{
+		Use:   "gen",
+		Short: "generate all the things",
+		Long:  "Use this command to generate data to test with.",
+		Run: func(_ *cobra.Command, _ []string) {
+			schema, table, err := parseSchemaTable(genArgs.GetString(arguments.ArgTable))
+
+			if err == nil {
+				sqliteClient, clientErr := newClient(genArgs, schema)
+				if clientErr != nil {
+					logger.Error().Err(clientErr).Msg("An error occurred while reading the connection settings")
+					return
+				}
+
+				stopTracing, tracingErr := commands.StartTracing(genArgs, dbclient.SQLite)
+				if tracingErr != nil {
+					logger.Error().Err(tracingErr).Msg("An error occurred while setting up tracing")
+					return
+				}
+				defer stopTracing()
+
+				stopMetrics, metricsErr := commands.ServeMetrics(genArgs)
+				if metricsErr != nil {
+					logger.Error().Err(metricsErr).Msg("An error occurred while serving the metrics")
+					return
+				}
+				defer stopMetrics()
+				if genErr := lobperformance.GenerateBulk(
+					context.Background(),
+					dbclient.SQLite,
+					&sqliteClient,
+					schema,
+					table,
+					genArgs.GetStringSlice(arguments.ArgSpread),
+					int64(genArgs.GetUint(arguments.ArgEmptyLobs)),
+					genArgs.GetString(arguments.ArgByteSize),
+					int(genArgs.GetUint(arguments.ArgBatchSize)),
+					genArgs.GetString(arguments.ArgLobType)); genErr != nil {
+					logger.Error().Err(genErr).Msg("An error occurred while generating LOB data")
+				}
+			} else {
+				logger.Error().Err(err).Msg("An error occurred while parsing the schema + table")
+			}
+		},
+	}
+
+	genArgs = arguments.AllArgs.CommandArgs(
+		genCommand,
+		append(globalArgs,
+			arguments.ArgSpread,
+			arguments.ArgByteSize,
+			arguments.ArgTable,
+			arguments.ArgEmptyLobs,
+			arguments.ArgLobType,
+			arguments.ArgBatchSize,
+			arguments.ArgMetricsListen,
+			arguments.ArgTraceEndpoint,
+			arguments.ArgTraceFile,
+			arguments.ArgTraceQuerySample))
+	return genCommand
+}
+
+func lobTestCommand() *cobra.Command {
+	var testExecutionArgs arguments.Args
+	testExecutionCommand := &cobra.Command{
+		Use:   "test",
+		Short: "run the test",
+		Long:  "Use this command to run the test on the earlier created data.",
+		Run: func(_ *cobra.Command, _ []string) {
+			schema, table, err := parseSchemaTable(testExecutionArgs.GetString(arguments.ArgTable))
+
+			if err == nil {
+				sqliteClient, clientErr := newClient(testExecutionArgs, schema)
+				if clientErr != nil {
+					logger.Error().Err(clientErr).Msg("An error occurred while reading the connection settings")
+					return
+				}
+
+				stopTracing, tracingErr := commands.StartTracing(testExecutionArgs, dbclient.SQLite)
+				if tracingErr != nil {
+					logger.Error().Err(tracingErr).Msg("An error occurred while setting up tracing")
+					return
+				}
+				defer stopTracing()
+
+				stopMetrics, metricsErr := commands.ServeMetrics(testExecutionArgs)
+				if metricsErr != nil {
+					logger.Error().Err(metricsErr).Msg("An error occurred while serving the metrics")
+					return
+				}
+				defer stopMetrics()
+
+				run := commands.NewRun(testExecutionArgs, dbclient.SQLite, "lob-performance", schema, table)
+				err := lobperformance.ExecuteTest(
+					context.Background(),
+					dbclient.SQLite,
+					&sqliteClient,
+					schema,
+					table,
+					testExecutionArgs.GetString(arguments.ArgRandomizerSeed),
+					int(testExecutionArgs.GetUint(arguments.ArgParallel)),
+					int(testExecutionArgs.GetUint(arguments.ArgWarmupTime)),
+					int(testExecutionArgs.GetUint(arguments.ArgExecutionTime)),
+					testExecutionArgs.GetString(arguments.ArgReadMode),
+					testExecutionArgs.GetString(arguments.ArgLobType),
+					sessionSettingsFromArgs(testExecutionArgs),
+					reconnectPolicyFromArgs(testExecutionArgs),
+					run)
+
+				if err != nil {
+					logger.Error().Err(err).Msg("An error occurred while trying to execute the LOB performance test")
+					return
+				}
+				commands.WriteResult(testExecutionArgs, run)
+			} else {
+				logger.Error().Err(err).Msg("An error occurred while parsing the schema + table")
+			}
+		},
+	}
+
+	testExecutionArgs = arguments.AllArgs.CommandArgs(
+		testExecutionCommand,
+		append(
+			globalArgs,
+			arguments.ArgTable,
+			arguments.ArgRandomizerSeed,
+			arguments.ArgParallel,
+			arguments.ArgWarmupTime,
+			arguments.ArgExecutionTime,
+			arguments.ArgReadMode,
+			arguments.ArgLobType,
+			arguments.ArgLockTimeout,
+			arguments.ArgStatementTimeout,
+			arguments.ArgReconnect,
+			arguments.ArgReconnectBackoff,
+			arguments.ArgReconnectMaxBackoff,
+			arguments.ArgResultFile,
+			arguments.ArgHistoryFile,
+			arguments.ArgHistoryDSN,
+			arguments.ArgClientMetricsInterval,
+			arguments.ArgReportInterval,
+			arguments.ArgMetricsListen,
+			arguments.ArgTraceEndpoint,
+			arguments.ArgTraceFile,
+			arguments.ArgTraceQuerySample))
+
+	return testExecutionCommand
+}
+
+func parseSchemaTable(fullName string) (schema string, table string, err error) {
+	if fullName == "" {
+		return "", "", errors.New("table name cannot be empty")
+	}
+
+	if strings.Contains(fullName, ".") {
+		parts := strings.SplitN(fullName, ".", 2)
+		schema = parts[0]
+		table = parts[1]
+
+		if schema == "" || table == "" {
+			return "", "", fmt.Errorf("invalid table name %q, expected schema.table", fullName)
+		}
+
+		return schema, table, nil
+	}
+	return "dbtwooltests", fullName, nil
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pgvillage-tools/dbtwool/internal/arguments"
+	"github.com/pgvillage-tools/dbtwool/internal/commands"
+	"github.com/pgvillage-tools/dbtwool/pkg/dbclient"
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+	"github.com/pgvillage-tools/dbtwool/pkg/ruperformance"
+	"github.com/pgvillage-tools/dbtwool/pkg/sqlite"
+	"github.com/pgvillage-tools/dbtwool/pkg/testrunner"
+	"github.com/spf13/cobra"
+)
+
+func ruCommand() *cobra.Command {
+	ruPerformanceCommand := &cobra.Command{
+		Use:   "ru-performance",
+		Short: "test db performance with read uncommitted isolation level",
+		Long: strings.Join([]string{
+			"Use this command to create a testenvironment",
+			"create a workload",
+			"and execute a performance test for read uncommitted isolation level.",
+		}, ", "),
+		RunE: commands.RequireSubcommand,
+	}
+
+	ruPerformanceCommand.AddCommand(
+		ruStageCommand(),
+		ruGenCommand(),
+		ruTestCommand(),
+	)
+
+	return ruPerformanceCommand
+}
+
+func ruStageCommand() *cobra.Command {
+	var stageArgs arguments.Args
+	stageCommand := &cobra.Command{
+		Use:   "stage",
+		Short: "create tables",
+		Long:  "Create the necessary schema and table(s)",
+		Run: func(_ *cobra.Command, _ []string) {
+			schema, table, err := parseSchemaTable(stageArgs.GetString(arguments.ArgTable))
+
+			if err == nil {
+				sqliteClient, clientErr := newClient(stageArgs, schema)
+				if clientErr != nil {
+					logger.Error().Err(clientErr).Msg("An error occurred while reading the connection settings")
+					return
+				}
+
+				stopTracing, tracingErr := commands.StartTracing(stageArgs, dbclient.SQLite)
+				if tracingErr != nil {
+					logger.Error().Err(tracingErr).Msg("An error occurred while setting up tracing")
+					return
+				}
+				defer stopTracing()
+
+				if stageErr := ruperformance.Stage(
+					context.Background(),
+					dbclient.SQLite,
+					&sqliteClient,
+					schema, table); stageErr != nil {
+					logger.Error().Err(stageErr).Msg("An error occurred while staging RU performance")
+				}
+			} else {
+				logger.Error().Err(err).Msg("An error occurred while parsing the schema + table")
+			}
+		},
+	}
+
+	stageArgs = arguments.AllArgs.CommandArgs(stageCommand, append(globalArgs, arguments.ArgTable,
+		arguments.ArgTraceEndpoint, arguments.ArgTraceFile, arguments.ArgTraceQuerySample))
+
+	return stageCommand
+}
+
+func ruGenCommand() *cobra.Command {
+	var genArgs arguments.Args
+	genCommand := &cobra.Command{
+		Use:   "gen",
+		Short: "generate all the things",
+		Long:  "Use this command to generate data to test with.",
+		Run: func(_ *cobra.Command, _ []string) {
+			// not used yet
+			schema, table, err := parseSchemaTable(genArgs.GetString(arguments.ArgTable))
+
+			if err == nil {
+				sqliteClient, clientErr := newClient(genArgs, schema)
+				if clientErr != nil {
+					logger.Error().Err(clientErr).Msg("An error occurred while reading the connection settings")
+					return
+				}
+
+				stopTracing, tracingErr := commands.StartTracing(genArgs, dbclient.SQLite)
+				if tracingErr != nil {
+					logger.Error().Err(tracingErr).Msg("An error occurred while setting up tracing")
+					return
+				}
+				defer stopTracing()
+
+				stopMetrics, metricsErr := commands.ServeMetrics(genArgs)
+				if metricsErr != nil {
+					logger.Error().Err(metricsErr).Msg("An error occurred while serving the metrics")
+					return
+				}
+				defer stopMetrics()
+
+				if genErr := ruperformance.Generate(
+					context.Background(),
+					dbclient.SQLite,
+					&sqliteClient,
+					schema,
+					table,
+					int64(genArgs.GetUint(arguments.ArgNumOfRows))); genErr != nil {
+					logger.Error().Err(genErr).Msg("An error occurred while generating RU performance data")
+				}
+			} else {
+				logger.Error().Err(err).Msg("An error occurred while parsing the schema + table")
+			}
+		},
+	}
+
+	genArgs = arguments.AllArgs.CommandArgs(genCommand,
+		// revive:disable-next-line
+		append(globalArgs, arguments.ArgTable, arguments.ArgNumOfRows, arguments.ArgMetricsListen,
+			arguments.ArgTraceEndpoint, arguments.ArgTraceFile, arguments.ArgTraceQuerySample))
+	return genCommand
+}
+
+func ruTestCommand() *cobra.Command {
+	var testExecutionArgs arguments.Args
+	testExecutionCommand := &cobra.Command{
+		Use:   "test",
+		Short: "run the test",
+		Long:  "Use this command to run the test on the earlier created data.",
+		Run: func(_ *cobra.Command, _ []string) {
+			schema, table, tableParseErr := parseSchemaTable(testExecutionArgs.GetString(arguments.ArgTable))
+			if tableParseErr != nil {
+				logger.Error().Err(tableParseErr).Msg("An error occurred while parsing the schema + table")
+				return
+			}
+
+			isolationLevel, err := sqlite.ParseIsolationLevel(testExecutionArgs.GetString(arguments.ArgIsolationLevel))
+
+			if err == nil {
+				retryPolicy, policyErr := retryPolicyFromArgs(testExecutionArgs)
+				if policyErr != nil {
+					logger.Error().Err(policyErr).Msg("An error occurred while parsing the retry policy")
+					return
+				}
+
+				sqliteClient, clientErr := newClient(testExecutionArgs, schema)
+				if clientErr != nil {
+					logger.Error().Err(clientErr).Msg("An error occurred while reading the connection settings")
+					return
+				}
+
+				stopTracing, tracingErr := commands.StartTracing(testExecutionArgs, dbclient.SQLite)
+				if tracingErr != nil {
+					logger.Error().Err(tracingErr).Msg("An error occurred while setting up tracing")
+					return
+				}
+				defer stopTracing()
+
+				stopMetrics, metricsErr := commands.ServeMetrics(testExecutionArgs)
+				if metricsErr != nil {
+					logger.Error().Err(metricsErr).Msg("An error occurred while serving the metrics")
+					return
+				}
+				defer stopMetrics()
+
+				run := commands.NewRun(testExecutionArgs, dbclient.SQLite, "ru-performance", schema, table)
+				err := ruperformance.ExecuteTest(
+					context.Background(),
+					dbclient.SQLite,
+					&sqliteClient,
+					schema,
+					table,
+					int(testExecutionArgs.GetUint(arguments.ArgWarmupTime)),
+					int(testExecutionArgs.GetUint(arguments.ArgExecutionTime)),
+					int(testExecutionArgs.GetUint(arguments.ArgOltpWorkers)),
+					int(testExecutionArgs.GetUint(arguments.ArgOlapWorkers)),
+					isolationLevel,
+					retryPolicy,
+					sessionSettingsFromArgs(testExecutionArgs),
+					reconnectPolicyFromArgs(testExecutionArgs),
+					run)
+				if err != nil {
+					logger.Error().Err(err).Msg("An error occurred while trying to execute the RU performance test")
+					return
+				}
+				commands.WriteResult(testExecutionArgs, run)
+			} else {
+				logger.Error().Err(err).Msg("An error occurred while parsing the isolation level")
+			}
+		},
+	}
+
+	testExecutionArgs = arguments.AllArgs.CommandArgs(
+		testExecutionCommand,
+		append(globalArgs,
+			arguments.ArgTable,
+			arguments.ArgWarmupTime,
+			arguments.ArgExecutionTime,
+			arguments.ArgOltpWorkers,
+			arguments.ArgOlapWorkers,
+			arguments.ArgIsolationLevel,
+			arguments.ArgMaxRetries,
+			arguments.ArgRetryOn,
+			arguments.ArgRetryBackoff,
+			arguments.ArgLockTimeout,
+			arguments.ArgStatementTimeout,
+			arguments.ArgReconnect,
+			arguments.ArgReconnectBackoff,
+			arguments.ArgReconnectMaxBackoff,
+			arguments.ArgResultFile,
+			arguments.ArgHistoryFile,
+			arguments.ArgHistoryDSN,
+			arguments.ArgClientMetricsInterval,
+			arguments.ArgReportInterval,
+			arguments.ArgMetricsListen,
+			arguments.ArgTraceEndpoint,
+			arguments.ArgTraceFile,
+			arguments.ArgTraceQuerySample))
+
+	return testExecutionCommand
+}
+
+func retryPolicyFromArgs(args arguments.Args) (testrunner.RetryPolicy, error) {
+	return testrunner.NewRetryPolicy(
+		int(args.GetUint(arguments.ArgMaxRetries)),
+		args.GetStringSlice(arguments.ArgRetryOn),
+		time.Duration(args.GetUint(arguments.ArgRetryBackoff))*time.Millisecond)
+}
+
+func reconnectPolicyFromArgs(args arguments.Args) testrunner.ReconnectPolicy {
+	return testrunner.ReconnectPolicy{
+		Enabled:    args.GetBool(arguments.ArgReconnect),
+		Backoff:    time.Duration(args.GetUint(arguments.ArgReconnectBackoff)) * time.Millisecond,
+		MaxBackoff: time.Duration(args.GetUint(arguments.ArgReconnectMaxBackoff)) * time.Millisecond,
+	}
+}
+
+func sessionSettingsFromArgs(args arguments.Args) dbinterface.SessionSettings {
+	return dbinterface.SessionSettings{
+		LockTimeout:      time.Duration(args.GetUint(arguments.ArgLockTimeout)) * time.Millisecond,
+		StatementTimeout: time.Duration(args.GetUint(arguments.ArgStatementTimeout)) * time.Millisecond,
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+
+	"github.com/pgvillage-tools/dbtwool/internal/arguments"
+	"github.com/pgvillage-tools/dbtwool/pkg/workload"
+	"github.com/spf13/cobra"
+)
+
+func workloadCommand() *cobra.Command {
+	var workloadArgs arguments.Args
+	workloadCommand := &cobra.Command{
+		Use:   "workload",
+		Short: "run a custom SQL workload",
+		Long: "Use this command to run a weighted mix of transactions and statements, " +
+			"as defined in a workload file, and report metrics per statement.",
+		Run: func(_ *cobra.Command, _ []string) {
+			def, err := workload.LoadDefinition(workloadArgs.GetString(arguments.ArgWorkloadFile))
+			if err != nil {
+				logger.Error().Err(err).Msg("An error occurred while loading the workload")
+				return
+			}
+			retryPolicy, err := retryPolicyFromArgs(workloadArgs)
+			if err != nil {
+				logger.Error().Err(err).Msg("An error occurred while parsing the retry policy")
+				return
+			}
+
+			sqliteClient, clientErr := newClient(workloadArgs)
+			if clientErr != nil {
+				logger.Error().Err(clientErr).Msg("An error occurred while reading the connection settings")
+				return
+			}
+
+			err = workload.ExecuteTest(
+				context.Background(),
+				&sqliteClient,
+				def,
+				workloadArgs.GetString(arguments.ArgRandomizerSeed),
+				int(workloadArgs.GetUint(arguments.ArgParallel)),
+				int(workloadArgs.GetUint(arguments.ArgWarmupTime)),
+				int(workloadArgs.GetUint(arguments.ArgExecutionTime)),
+				retryPolicy,
+				reconnectPolicyFromArgs(workloadArgs))
+			if err != nil {
+				logger.Error().Err(err).Msg("An error occurred while trying to execute the workload")
+			}
+		},
+	}
+
+	workloadArgs = arguments.AllArgs.CommandArgs(
+		workloadCommand,
+		append(
+			globalArgs,
+			arguments.ArgWorkloadFile,
+			arguments.ArgRandomizerSeed,
+			arguments.ArgParallel,
+			arguments.ArgWarmupTime,
+			arguments.ArgExecutionTime,
+			arguments.ArgMaxRetries,
+			arguments.ArgRetryOn,
+			arguments.ArgRetryBackoff,
+			arguments.ArgReconnect,
+			arguments.ArgReconnectBackoff,
+			arguments.ArgReconnectMaxBackoff))
+
+	return workloadCommand
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"os"
+	"time"
+
+	"github.com/pgvillage-tools/dbtwool/internal/arguments"
+	"github.com/pgvillage-tools/dbtwool/internal/profile"
+	"github.com/pgvillage-tools/dbtwool/pkg/pg"
+	"github.com/pgvillage-tools/dbtwool/pkg/secrets"
+)
+
+// newClient returns a PostgreSQL client for the connection parameters and pool settings, where flags override the
+// dsn, which overrides env variables, which override the selected connection profile, which overrides the defaults
+func newClient(args arguments.Args) (pg.Client, error) {
+	prof, err := profile.Select(args.GetString(arguments.ArgCfgFile), args.GetString(arguments.ArgProfile))
+	if err != nil {
+		return pg.Client{}, err
+	}
+	params, err := connParams(args, prof)
+	if err != nil {
+		return pg.Client{}, err
+	}
+	client := pg.NewClient(params)
+	client.PoolSettings = prof.Pool.Override(poolFromArgs(args)).Settings()
+	return client, nil
+}
+
+// connParams returns the connection parameters from the flags, the dsn, env variables and the connection profile
+func connParams(args arguments.Args, prof profile.Profile) (pg.ConnParams, error) {
+	flagPassword, err := passwordFromArgs(args)
+	if err != nil {
+		return pg.ConnParams{}, err
+	}
+	var fromDSN pg.ConnParams
+	if dsn := args.GetString(arguments.ArgDSN); dsn != "" {
+		if fromDSN, err = pg.ParseDSN(dsn); err != nil {
+			return pg.ConnParams{}, err
+		}
+	}
+	// the passwords of the profile are only resolved when they are used, as they may run a password command
+	password, err := prof.Password.ResolveUnless(flagPassword, fromDSN.Password, os.Getenv("PGPASSWORD"))
+	if err != nil {
+		return pg.ConnParams{}, err
+	}
+	keyPassword, err := prof.TLS.KeyPassword.ResolveUnless(fromDSN.SslPassword, os.Getenv("PGSSLPASSWORD"))
+	if err != nil {
+		return pg.ConnParams{}, err
+	}
+	params := pg.ConnParamsFromEnvWithDefaults(pg.ConnParams{
+		Host:               prof.Host,
+		Port:               prof.Port,
+		Database:           prof.Database,
+		User:               prof.User,
+		Password:           password,
+		SslMode:            prof.SslMode,
+		SslRootCert:        prof.TLS.RootCert,
+		SslCert:            prof.TLS.Cert,
+		SslKey:             prof.TLS.Key,
+		SslPassword:        keyPassword,
+		SslCrl:             prof.TLS.CRL,
+		TargetSessionAttrs: prof.TargetSessionAttrs,
+	})
+	params.Host = profile.First(args.GetString(arguments.ArgHost), fromDSN.Host, params.Host)
+	params.Port = profile.First(args.GetString(arguments.ArgPort), fromDSN.Port, params.Port)
+	params.Database = profile.First(args.GetString(arguments.ArgDatabase), fromDSN.Database, params.Database)
+	params.User = profile.First(args.GetString(arguments.ArgUser), fromDSN.User, params.User)
+	params.Password = profile.First(flagPassword, fromDSN.Password, params.Password)
+	params.SslMode = profile.First(fromDSN.SslMode, params.SslMode)
+	params.SslRootCert = profile.First(fromDSN.SslRootCert, params.SslRootCert)
+	params.SslCert = profile.First(fromDSN.SslCert, params.SslCert)
+	params.SslKey = profile.First(fromDSN.SslKey, params.SslKey)
+	params.SslPassword = profile.First(fromDSN.SslPassword, params.SslPassword)
+	params.SslCrl = profile.First(fromDSN.SslCrl, params.SslCrl)
+	params.TargetSessionAttrs = profile.First(fromDSN.TargetSessionAttrs, params.TargetSessionAttrs)
+	for _, secret := range []string{params.Password, params.SslPassword} {
+		if err = secrets.Register(secret); err != nil {
+			logger.Warn().Err(err).Msg("A password is not masked in the output")
+		}
+	}
+	return params, nil
+}
+
+// passwordFromArgs returns the password from --passwordFile or --passwordCommand, or an empty string when neither is
+// set
+func passwordFromArgs(args arguments.Args) (string, error) {
+	return profile.Password{
+		File:    args.GetString(arguments.ArgPasswordFile),
+		Command: args.GetString(arguments.ArgPasswordCommand),
+	}.Resolve()
+}
+
+// poolFromArgs returns the pool settings which are set with flags
+func poolFromArgs(args arguments.Args) profile.Pool {
+	return profile.Pool{
+		MaxConns:          int(args.GetUint(arguments.ArgMaxConns)),
+		MinConns:          int(args.GetUint(arguments.ArgMinConns)),
+		MaxConnLifetime:   time.Duration(args.GetUint(arguments.ArgMaxConnLifetime)) * time.Second,
+		MaxConnIdleTime:   time.Duration(args.GetUint(arguments.ArgMaxConnIdleTime)) * time.Second,
+		HealthCheckPeriod: time.Duration(args.GetUint(arguments.ArgHealthCheckPeriod)) * time.Second,
+		ConnectTimeout:    time.Duration(args.GetUint(arguments.ArgConnectTimeout)) * time.Second,
+	}
+}
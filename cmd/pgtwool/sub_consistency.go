@@ -2,7 +2,7 @@ package main
 
 import (
 	"context"
-	"strconv"
+	"fmt"
 
 	"github.com/pgvillage-tools/dbtwool/internal/arguments"
 	db "github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
@@ -16,30 +16,38 @@ func consistencyCommand() *cobra.Command {
 		Use:   "consistency",
 		Short: "Run a consistency test.",
 		Long:  `Use this command to test consistency with different transaction isolation levels.`,
-		Run: func(_ *cobra.Command, _ []string) {
-			iLevel, err := strconv.Atoi(consistencyArgs.GetString("isolationLevel"))
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			// the arguments are fine, so failures from here on do not need the usage
+			cmd.SilenceUsage = true
+			isolationLevel, err := pg.ParseIsolationLevel(consistencyArgs.GetString(arguments.ArgIsolationLevel))
 			if err != nil {
-				log.Info("Warning: invalid isolationLevel, defaulting to 1")
-				iLevel = 1
+				return fmt.Errorf("failed to parse the isolation level: %w", err)
 			}
-			isolationLevel := pg.GetIsolationLevel(iLevel)
 
-			params := pg.ConnParamsFromEnv()
-
-			cl1 := pg.NewClient(params)
-			db.ConsistencyTest(
+			cl1, clientErr := newClient(consistencyArgs)
+			if clientErr != nil {
+				return fmt.Errorf("failed to read the connection settings: %w", clientErr)
+			}
+			if testErr := db.ConsistencyTest(
 				context.Background(),
 				&cl1,
 				"SELECT AVG(price) AS avgprice FROM gotest.products;",
 				isolationLevel,
+				sessionSettingsFromArgs(consistencyArgs),
 				"SELECT * FROM gotest.products FOR UPDATE;",
 				"UPDATE gotest.products SET price = 5000 where product_id = 1;",
-			)
+				db.DefaultConsistencyHoldTime,
+			); testErr != nil {
+				return fmt.Errorf("the consistency test failed: %w", testErr)
+			}
+			return nil
 		},
 	}
 
 	consistencyArgs = arguments.AllArgs.CommandArgs(consistencyCommand, append(globalArgs,
-		"isolationLevel",
+		arguments.ArgIsolationLevel,
+		arguments.ArgLockTimeout,
+		arguments.ArgStatementTimeout,
 	))
 	return consistencyCommand
 }
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/pgvillage-tools/dbtwool/internal/arguments"
+	"github.com/pgvillage-tools/dbtwool/pkg/dbclient"
+	"github.com/pgvillage-tools/dbtwool/pkg/failoverdrill"
+	"github.com/pgvillage-tools/dbtwool/pkg/testrunner"
+	"github.com/spf13/cobra"
+)
+
+func failoverDrillCommand() *cobra.Command {
+	var drillArgs arguments.Args
+	drillCommand := &cobra.Command{
+		Use:   "failover-drill",
+		Short: "measure the impact of a failover on clients",
+		Long: "Use this command to write and read heartbeats on parallel workers while a failover is triggered. " +
+			"Workers reconnect when their connection is lost. Afterwards the downtime windows, the time until the " +
+			"first successful write, and lost or duplicated acknowledged writes are reported. Set multiple " +
+			"hosts (e.a. --dsn 'postgres://host1,host2/db') to follow the primary; target_session_attrs defaults to " +
+			"read-write.",
+		Run: func(_ *cobra.Command, _ []string) {
+			postgresClient, clientErr := newClient(drillArgs)
+			if clientErr != nil {
+				logger.Error().Err(clientErr).Msg("An error occurred while reading the connection settings")
+				return
+			}
+			if postgresClient.ConnectParams.TargetSessionAttrs == "" {
+				postgresClient.ConnectParams.TargetSessionAttrs = "read-write"
+			}
+			schema, table, err := parseSchemaTable(drillArgs.GetString(arguments.ArgHeartbeatTable))
+			if err != nil {
+				logger.Error().Err(err).Msg("An error occurred while parsing the heartbeat table")
+				return
+			}
+
+			err = failoverdrill.ExecuteTest(
+				context.Background(),
+				dbclient.Postgres,
+				&postgresClient,
+				schema,
+				table,
+				int(drillArgs.GetUint(arguments.ArgParallel)),
+				int(drillArgs.GetUint(arguments.ArgExecutionTime)),
+				time.Duration(drillArgs.GetUint(arguments.ArgHeartbeatInterval))*time.Millisecond,
+				testrunner.ReconnectPolicy{
+					Backoff:    time.Duration(drillArgs.GetUint(arguments.ArgReconnectBackoff)) * time.Millisecond,
+					MaxBackoff: time.Duration(drillArgs.GetUint(arguments.ArgReconnectMaxBackoff)) * time.Millisecond,
+				})
+			if err != nil {
+				logger.Error().Err(err).Msg("An error occurred while trying to execute the failover drill")
+			}
+		},
+	}
+
+	drillArgs = arguments.AllArgs.CommandArgs(
+		drillCommand,
+		append(
+			globalArgs,
+			arguments.ArgHeartbeatTable,
+			arguments.ArgHeartbeatInterval,
+			arguments.ArgParallel,
+			arguments.ArgExecutionTime,
+			arguments.ArgReconnectBackoff,
+			arguments.ArgReconnectMaxBackoff))
+
+	return drillCommand
+}
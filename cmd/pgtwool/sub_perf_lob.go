@@ -7,9 +7,9 @@ import (
 	"strings"
 
 	"github.com/pgvillage-tools/dbtwool/internal/arguments"
+	"github.com/pgvillage-tools/dbtwool/internal/commands"
 	"github.com/pgvillage-tools/dbtwool/pkg/dbclient"
 	"github.com/pgvillage-tools/dbtwool/pkg/lobperformance"
-	"github.com/pgvillage-tools/dbtwool/pkg/pg"
 	"github.com/spf13/cobra"
 )
 
@@ -20,7 +20,7 @@ func lobPerformanceCommand() *cobra.Command {
 		Long: "Use this command to create a test environment, " +
 			"create a workload, " +
 			"and execute a performance test for large objects.",
-		RunE: requireSubcommand,
+		RunE: commands.RequireSubcommand,
 	}
 
 	lobPerformanceCommand.AddCommand(
@@ -42,18 +42,34 @@ func lobStageCommand() *cobra.Command {
 			schema, table, err := parseSchemaTable(stageArgs.GetString(arguments.ArgTable))
 
 			if err == nil {
-				params := pg.ConnParamsFromEnv()
+				postgresClient, clientErr := newClient(stageArgs)
+				if clientErr != nil {
+					logger.Error().Err(clientErr).Msg("An error occurred while reading the connection settings")
+					return
+				}
 
-				postgresClient := pg.NewClient(params)
+				stopTracing, tracingErr := commands.StartTracing(stageArgs, dbclient.Postgres)
+				if tracingErr != nil {
+					logger.Error().Err(tracingErr).Msg("An error occurred while setting up tracing")
+					return
+				}
+				defer stopTracing()
 
-				lobperformance.Stage(context.Background(), dbclient.Postgres, &postgresClient, schema, table)
+				if stageErr := lobperformance.Stage(
+					context.Background(),
+					dbclient.Postgres,
+					&postgresClient,
+					schema, table); stageErr != nil {
+					logger.Error().Err(stageErr).Msg("An error occurred while staging LOB performance")
+				}
 			} else {
-				fmt.Printf("An error occurred while parsing the schema + table: %v", err)
+				logger.Error().Err(err).Msg("An error occurred while parsing the schema + table")
 			}
 		},
 	}
 
-	stageArgs = arguments.AllArgs.CommandArgs(stageCommand, append(globalArgs, arguments.ArgTable))
+	stageArgs = arguments.AllArgs.CommandArgs(stageCommand, append(globalArgs, arguments.ArgTable,
+		arguments.ArgTraceEndpoint, arguments.ArgTraceFile, arguments.ArgTraceQuerySample))
 
 	return stageCommand
 }
@@ -68,9 +84,26 @@ func lobGenCommand() *cobra.Command {
 			schema, table, err := parseSchemaTable(genArgs.GetString(arguments.ArgTable))
 
 			if err == nil {
-				params := pg.ConnParamsFromEnv()
-				postgresClient := pg.NewClient(params)
-				lobperformance.GenerateBulk(
+				postgresClient, clientErr := newClient(genArgs)
+				if clientErr != nil {
+					logger.Error().Err(clientErr).Msg("An error occurred while reading the connection settings")
+					return
+				}
+
+				stopTracing, tracingErr := commands.StartTracing(genArgs, dbclient.Postgres)
+				if tracingErr != nil {
+					logger.Error().Err(tracingErr).Msg("An error occurred while setting up tracing")
+					return
+				}
+				defer stopTracing()
+
+				stopMetrics, metricsErr := commands.ServeMetrics(genArgs)
+				if metricsErr != nil {
+					logger.Error().Err(metricsErr).Msg("An error occurred while serving the metrics")
+					return
+				}
+				defer stopMetrics()
+				if genErr := lobperformance.GenerateBulk(
 					context.Background(),
 					dbclient.Postgres,
 					&postgresClient,
@@ -80,9 +113,11 @@ func lobGenCommand() *cobra.Command {
 					int64(genArgs.GetUint(arguments.ArgEmptyLobs)),
 					genArgs.GetString(arguments.ArgByteSize),
 					int(genArgs.GetUint(arguments.ArgBatchSize)),
-					genArgs.GetString(arguments.ArgLobType))
+					genArgs.GetString(arguments.ArgLobType)); genErr != nil {
+					logger.Error().Err(genErr).Msg("An error occurred while generating LOB data")
+				}
 			} else {
-				fmt.Printf("An error occurred while parsing the schema + table: %v", err)
+				logger.Error().Err(err).Msg("An error occurred while parsing the schema + table")
 			}
 		},
 	}
@@ -95,7 +130,11 @@ func lobGenCommand() *cobra.Command {
 			arguments.ArgTable,
 			arguments.ArgEmptyLobs,
 			arguments.ArgLobType,
-			arguments.ArgBatchSize))
+			arguments.ArgBatchSize,
+			arguments.ArgMetricsListen,
+			arguments.ArgTraceEndpoint,
+			arguments.ArgTraceFile,
+			arguments.ArgTraceQuerySample))
 	return genCommand
 }
 
@@ -109,9 +148,27 @@ func lobTestCommand() *cobra.Command {
 			schema, table, err := parseSchemaTable(testExecutionArgs.GetString(arguments.ArgTable))
 
 			if err == nil {
-				params := pg.ConnParamsFromEnv()
-				postgresClient := pg.NewClient(params)
+				postgresClient, clientErr := newClient(testExecutionArgs)
+				if clientErr != nil {
+					logger.Error().Err(clientErr).Msg("An error occurred while reading the connection settings")
+					return
+				}
 
+				stopTracing, tracingErr := commands.StartTracing(testExecutionArgs, dbclient.Postgres)
+				if tracingErr != nil {
+					logger.Error().Err(tracingErr).Msg("An error occurred while setting up tracing")
+					return
+				}
+				defer stopTracing()
+
+				stopMetrics, metricsErr := commands.ServeMetrics(testExecutionArgs)
+				if metricsErr != nil {
+					logger.Error().Err(metricsErr).Msg("An error occurred while serving the metrics")
+					return
+				}
+				defer stopMetrics()
+
+				run := commands.NewRun(testExecutionArgs, dbclient.Postgres, "lob-performance", schema, table)
 				err := lobperformance.ExecuteTest(
 					context.Background(),
 					dbclient.Postgres,
@@ -123,13 +180,18 @@ func lobTestCommand() *cobra.Command {
 					int(testExecutionArgs.GetUint(arguments.ArgWarmupTime)),
 					int(testExecutionArgs.GetUint(arguments.ArgExecutionTime)),
 					testExecutionArgs.GetString(arguments.ArgReadMode),
-					testExecutionArgs.GetString(arguments.ArgLobType))
+					testExecutionArgs.GetString(arguments.ArgLobType),
+					sessionSettingsFromArgs(testExecutionArgs),
+					reconnectPolicyFromArgs(testExecutionArgs),
+					run)
 
 				if err != nil {
-					fmt.Printf("An error occurred while trying to execute the LOB performance test: %v", err)
+					logger.Error().Err(err).Msg("An error occurred while trying to execute the LOB performance test")
+					return
 				}
+				commands.WriteResult(testExecutionArgs, run)
 			} else {
-				fmt.Printf("An error occurred while parsing the schema + table: %v", err)
+				logger.Error().Err(err).Msg("An error occurred while parsing the schema + table")
 			}
 		},
 	}
@@ -144,7 +206,22 @@ func lobTestCommand() *cobra.Command {
 			arguments.ArgWarmupTime,
 			arguments.ArgExecutionTime,
 			arguments.ArgReadMode,
-			arguments.ArgLobType))
+			arguments.ArgLobType,
+			arguments.ArgLockTimeout,
+			arguments.ArgStatementTimeout,
+			arguments.ArgReconnect,
+			arguments.ArgReconnectBackoff,
+			arguments.ArgReconnectMaxBackoff,
+			arguments.ArgResultFile,
+			arguments.ArgHistoryFile,
+			arguments.ArgHistoryDSN,
+			arguments.ArgServerMetricsInterval,
+			arguments.ArgClientMetricsInterval,
+			arguments.ArgReportInterval,
+			arguments.ArgMetricsListen,
+			arguments.ArgTraceEndpoint,
+			arguments.ArgTraceFile,
+			arguments.ArgTraceQuerySample))
 
 	return testExecutionCommand
 }
@@ -2,14 +2,16 @@ package main
 
 import (
 	"context"
-	"fmt"
-	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pgvillage-tools/dbtwool/internal/arguments"
+	"github.com/pgvillage-tools/dbtwool/internal/commands"
 	"github.com/pgvillage-tools/dbtwool/pkg/dbclient"
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
 	"github.com/pgvillage-tools/dbtwool/pkg/pg"
 	"github.com/pgvillage-tools/dbtwool/pkg/ruperformance"
+	"github.com/pgvillage-tools/dbtwool/pkg/testrunner"
 	"github.com/spf13/cobra"
 )
 
@@ -22,7 +24,7 @@ func ruCommand() *cobra.Command {
 			"create a workload",
 			"and execute a performance test for read uncommitted isolation level.",
 		}, ", "),
-		RunE: requireSubcommand,
+		RunE: commands.RequireSubcommand,
 	}
 
 	ruPerformanceCommand.AddCommand(
@@ -44,17 +46,34 @@ func ruStageCommand() *cobra.Command {
 			schema, table, err := parseSchemaTable(stageArgs.GetString(arguments.ArgTable))
 
 			if err == nil {
-				params := pg.ConnParamsFromEnv()
-				postgresClient := pg.NewClient(params)
+				postgresClient, clientErr := newClient(stageArgs)
+				if clientErr != nil {
+					logger.Error().Err(clientErr).Msg("An error occurred while reading the connection settings")
+					return
+				}
+
+				stopTracing, tracingErr := commands.StartTracing(stageArgs, dbclient.Postgres)
+				if tracingErr != nil {
+					logger.Error().Err(tracingErr).Msg("An error occurred while setting up tracing")
+					return
+				}
+				defer stopTracing()
 
-				ruperformance.Stage(context.Background(), dbclient.Postgres, &postgresClient, schema, table)
+				if stageErr := ruperformance.Stage(
+					context.Background(),
+					dbclient.Postgres,
+					&postgresClient,
+					schema, table); stageErr != nil {
+					logger.Error().Err(stageErr).Msg("An error occurred while staging RU performance")
+				}
 			} else {
-				fmt.Printf("An error occurred while parsing the schema + table: %v", err)
+				logger.Error().Err(err).Msg("An error occurred while parsing the schema + table")
 			}
 		},
 	}
 
-	stageArgs = arguments.AllArgs.CommandArgs(stageCommand, append(globalArgs, arguments.ArgTable))
+	stageArgs = arguments.AllArgs.CommandArgs(stageCommand, append(globalArgs, arguments.ArgTable,
+		arguments.ArgTraceEndpoint, arguments.ArgTraceFile, arguments.ArgTraceQuerySample))
 
 	return stageCommand
 }
@@ -70,25 +89,45 @@ func ruGenCommand() *cobra.Command {
 			schema, table, err := parseSchemaTable(genArgs.GetString(arguments.ArgTable))
 
 			if err == nil {
-				params := pg.ConnParamsFromEnv()
-				postgresClient := pg.NewClient(params)
+				postgresClient, clientErr := newClient(genArgs)
+				if clientErr != nil {
+					logger.Error().Err(clientErr).Msg("An error occurred while reading the connection settings")
+					return
+				}
+
+				stopTracing, tracingErr := commands.StartTracing(genArgs, dbclient.Postgres)
+				if tracingErr != nil {
+					logger.Error().Err(tracingErr).Msg("An error occurred while setting up tracing")
+					return
+				}
+				defer stopTracing()
 
-				ruperformance.Generate(
+				stopMetrics, metricsErr := commands.ServeMetrics(genArgs)
+				if metricsErr != nil {
+					logger.Error().Err(metricsErr).Msg("An error occurred while serving the metrics")
+					return
+				}
+				defer stopMetrics()
+
+				if genErr := ruperformance.Generate(
 					context.Background(),
 					dbclient.Postgres,
 					&postgresClient,
 					schema,
 					table,
-					int64(genArgs.GetUint(arguments.ArgNumOfRows)))
+					int64(genArgs.GetUint(arguments.ArgNumOfRows))); genErr != nil {
+					logger.Error().Err(genErr).Msg("An error occurred while generating RU performance data")
+				}
 			} else {
-				fmt.Printf("An error occurred while parsing the schema + table: %v", err)
+				logger.Error().Err(err).Msg("An error occurred while parsing the schema + table")
 			}
 		},
 	}
 
 	genArgs = arguments.AllArgs.CommandArgs(genCommand,
 		// revive:disable-next-line
-		append(globalArgs, arguments.ArgTable, arguments.ArgNumOfRows))
+		append(globalArgs, arguments.ArgTable, arguments.ArgNumOfRows, arguments.ArgMetricsListen,
+			arguments.ArgTraceEndpoint, arguments.ArgTraceFile, arguments.ArgTraceQuerySample))
 	return genCommand
 }
 
@@ -101,15 +140,40 @@ func ruTestCommand() *cobra.Command {
 		Run: func(_ *cobra.Command, _ []string) {
 			schema, table, tableParseErr := parseSchemaTable(testExecutionArgs.GetString(arguments.ArgTable))
 			if tableParseErr != nil {
-				fmt.Printf("An error occurred while parsing the schema + table: %v", tableParseErr)
+				logger.Error().Err(tableParseErr).Msg("An error occurred while parsing the schema + table")
+				return
 			}
 
-			iLevel, err := strconv.Atoi(testExecutionArgs.GetString(arguments.ArgIsolationLevel))
+			isolationLevel, err := pg.ParseIsolationLevel(testExecutionArgs.GetString(arguments.ArgIsolationLevel))
 
 			if err == nil {
-				params := pg.ConnParamsFromEnv()
-				postgresClient := pg.NewClient(params)
+				retryPolicy, policyErr := retryPolicyFromArgs(testExecutionArgs)
+				if policyErr != nil {
+					logger.Error().Err(policyErr).Msg("An error occurred while parsing the retry policy")
+					return
+				}
 
+				postgresClient, clientErr := newClient(testExecutionArgs)
+				if clientErr != nil {
+					logger.Error().Err(clientErr).Msg("An error occurred while reading the connection settings")
+					return
+				}
+
+				stopTracing, tracingErr := commands.StartTracing(testExecutionArgs, dbclient.Postgres)
+				if tracingErr != nil {
+					logger.Error().Err(tracingErr).Msg("An error occurred while setting up tracing")
+					return
+				}
+				defer stopTracing()
+
+				stopMetrics, metricsErr := commands.ServeMetrics(testExecutionArgs)
+				if metricsErr != nil {
+					logger.Error().Err(metricsErr).Msg("An error occurred while serving the metrics")
+					return
+				}
+				defer stopMetrics()
+
+				run := commands.NewRun(testExecutionArgs, dbclient.Postgres, "ru-performance", schema, table)
 				err := ruperformance.ExecuteTest(
 					context.Background(),
 					dbclient.Postgres,
@@ -118,12 +182,20 @@ func ruTestCommand() *cobra.Command {
 					table,
 					int(testExecutionArgs.GetUint(arguments.ArgWarmupTime)),
 					int(testExecutionArgs.GetUint(arguments.ArgExecutionTime)),
-					pg.GetIsolationLevel(iLevel))
+					int(testExecutionArgs.GetUint(arguments.ArgOltpWorkers)),
+					int(testExecutionArgs.GetUint(arguments.ArgOlapWorkers)),
+					isolationLevel,
+					retryPolicy,
+					sessionSettingsFromArgs(testExecutionArgs),
+					reconnectPolicyFromArgs(testExecutionArgs),
+					run)
 				if err != nil {
-					fmt.Printf("An error occurred while trying to execute the RU performance test: %v", err)
+					logger.Error().Err(err).Msg("An error occurred while trying to execute the RU performance test")
+					return
 				}
+				commands.WriteResult(testExecutionArgs, run)
 			} else {
-				fmt.Printf("An error occurred while parsing the isolation level: %v", err)
+				logger.Error().Err(err).Msg("An error occurred while parsing the isolation level")
 			}
 		},
 	}
@@ -134,7 +206,49 @@ func ruTestCommand() *cobra.Command {
 			arguments.ArgTable,
 			arguments.ArgWarmupTime,
 			arguments.ArgExecutionTime,
-			arguments.ArgIsolationLevel))
+			arguments.ArgOltpWorkers,
+			arguments.ArgOlapWorkers,
+			arguments.ArgIsolationLevel,
+			arguments.ArgMaxRetries,
+			arguments.ArgRetryOn,
+			arguments.ArgRetryBackoff,
+			arguments.ArgLockTimeout,
+			arguments.ArgStatementTimeout,
+			arguments.ArgReconnect,
+			arguments.ArgReconnectBackoff,
+			arguments.ArgReconnectMaxBackoff,
+			arguments.ArgResultFile,
+			arguments.ArgHistoryFile,
+			arguments.ArgHistoryDSN,
+			arguments.ArgServerMetricsInterval,
+			arguments.ArgClientMetricsInterval,
+			arguments.ArgReportInterval,
+			arguments.ArgMetricsListen,
+			arguments.ArgTraceEndpoint,
+			arguments.ArgTraceFile,
+			arguments.ArgTraceQuerySample))
 
 	return testExecutionCommand
 }
+
+func retryPolicyFromArgs(args arguments.Args) (testrunner.RetryPolicy, error) {
+	return testrunner.NewRetryPolicy(
+		int(args.GetUint(arguments.ArgMaxRetries)),
+		args.GetStringSlice(arguments.ArgRetryOn),
+		time.Duration(args.GetUint(arguments.ArgRetryBackoff))*time.Millisecond)
+}
+
+func reconnectPolicyFromArgs(args arguments.Args) testrunner.ReconnectPolicy {
+	return testrunner.ReconnectPolicy{
+		Enabled:    args.GetBool(arguments.ArgReconnect),
+		Backoff:    time.Duration(args.GetUint(arguments.ArgReconnectBackoff)) * time.Millisecond,
+		MaxBackoff: time.Duration(args.GetUint(arguments.ArgReconnectMaxBackoff)) * time.Millisecond,
+	}
+}
+
+func sessionSettingsFromArgs(args arguments.Args) dbinterface.SessionSettings {
+	return dbinterface.SessionSettings{
+		LockTimeout:      time.Duration(args.GetUint(arguments.ArgLockTimeout)) * time.Millisecond,
+		StatementTimeout: time.Duration(args.GetUint(arguments.ArgStatementTimeout)) * time.Millisecond,
+	}
+}
@@ -2,21 +2,63 @@ package arguments
 
 // CLI argument keys used throughout the application.
 const (
-	ArgCfgFile        = "cfgFile"
-	ArgIsolationLevel = "isolationLevel"
-	ArgSpread         = "spread"
-	ArgByteSize       = "byteSize"
-	ArgBatchSize      = "batchSize"
-	ArgLobType        = "lobType"
-	ArgEmptyLobs      = "emptyLobs"
-	ArgRandomizerSeed = "randomizerSeed"
-	ArgTable          = "table"
-	ArgParallel       = "parallel"
-	ArgWarmupTime     = "warmupTime"
-	ArgExecutionTime  = "executionTime"
-	ArgReadMode       = "readMode"
-	ArgNumOfRows      = "numOfRows"
-	ArgBulkInsert     = "bulkInsert"
+	ArgCfgFile               = "cfgFile"
+	ArgProfile               = "profile"
+	ArgHost                  = "host"
+	ArgPort                  = "port"
+	ArgDatabase              = "database"
+	ArgUser                  = "user"
+	ArgDSN                   = "dsn"
+	ArgPasswordFile          = "passwordFile"
+	ArgPasswordCommand       = "passwordCommand"
+	ArgMaxConns              = "maxConns"
+	ArgMinConns              = "minConns"
+	ArgMaxConnLifetime       = "maxConnLifetime"
+	ArgMaxConnIdleTime       = "maxConnIdleTime"
+	ArgHealthCheckPeriod     = "healthCheckPeriod"
+	ArgConnectTimeout        = "connectTimeout"
+	ArgIsolationLevel        = "isolationLevel"
+	ArgSpread                = "spread"
+	ArgByteSize              = "byteSize"
+	ArgBatchSize             = "batchSize"
+	ArgLobType               = "lobType"
+	ArgEmptyLobs             = "emptyLobs"
+	ArgRandomizerSeed        = "randomizerSeed"
+	ArgTable                 = "table"
+	ArgParallel              = "parallel"
+	ArgWarmupTime            = "warmupTime"
+	ArgExecutionTime         = "executionTime"
+	ArgReadMode              = "readMode"
+	ArgNumOfRows             = "numOfRows"
+	ArgBulkInsert            = "bulkInsert"
+	ArgWorkloadFile          = "workloadFile"
+	ArgOltpWorkers           = "oltpWorkers"
+	ArgOlapWorkers           = "olapWorkers"
+	ArgMaxRetries            = "maxRetries"
+	ArgRetryOn               = "retryOn"
+	ArgRetryBackoff          = "retryBackoff"
+	ArgLockTimeout           = "lockTimeout"
+	ArgStatementTimeout      = "statementTimeout"
+	ArgConnectQuery          = "connectQuery"
+	ArgReconnect             = "reconnect"
+	ArgReconnectBackoff      = "reconnectBackoff"
+	ArgReconnectMaxBackoff   = "reconnectMaxBackoff"
+	ArgHeartbeatTable        = "heartbeatTable"
+	ArgHeartbeatInterval     = "heartbeatInterval"
+	ArgResultFile            = "resultFile"
+	ArgServerMetricsInterval = "serverMetricsInterval"
+	ArgClientMetricsInterval = "clientMetricsInterval"
+	ArgMetricsListen         = "metricsListen"
+	ArgTraceEndpoint         = "traceEndpoint"
+	ArgTraceFile             = "traceFile"
+	ArgTraceQuerySample      = "traceQuerySample"
+	ArgReportInterval        = "reportInterval"
+	ArgReportFile            = "reportFile"
+	ArgHistoryFile           = "historyFile"
+	ArgHistoryDSN            = "historyDsn"
+	ArgLogLevel              = "logLevel"
+	ArgLogFormat             = "logFormat"
+	ArgLogFile               = "logFile"
 )
 
 var (
@@ -24,8 +66,46 @@ var (
 	AllArgs = Args{
 		ArgCfgFile: {short: "c", defValue: "config.yaml", argType: typePath,
 			desc: `config file`},
-		ArgIsolationLevel: {short: "i", defValue: "1", argType: typeString,
-			desc: `Transaction isolation level`},
+		ArgProfile: {argType: typeString,
+			desc: `Name of the connection profile in the config file. Leave empty for the default profile.`},
+		ArgHost: {argType: typeString,
+			desc: `Database host. Overrides the environment and the connection profile.`},
+		ArgPort: {argType: typeString,
+			desc: `Database port. Overrides the environment and the connection profile.`},
+		ArgDatabase: {argType: typeString,
+			desc: `Database name (or database file for SQLite). Overrides the environment and the connection profile.`},
+		ArgUser: {argType: typeString,
+			desc: `Database user. Overrides the environment and the connection profile.`},
+		ArgDSN: {argType: typeString,
+			desc: `Connection uri (postgres://user@host:port/db?sslmode=require, db2://user@host:port/db) or ` +
+				`connection string. Overrides the environment and the connection profile, ` +
+				`but is overridden by host, port, database and user.`},
+		ArgPasswordFile: {argType: typeString,
+			desc: `File holding the database password. Overrides the dsn, the environment and the connection profile.`},
+		ArgPasswordCommand: {argType: typeString,
+			desc: `Command (run with sh -c) which prints the database password. ` +
+				`Overrides the dsn, the environment and the connection profile.`},
+		ArgMaxConns: {defValue: uint(0), argType: typeUInt,
+			desc: `Maximum number of connections in the pool. 0 sizes the pool to the parallelism of the test. ` +
+				`Overrides the connection profile.`},
+		ArgMinConns: {defValue: uint(0), argType: typeUInt,
+			desc: `Number of connections which are kept open, even when idle. DB2 and SQLite do not open them in ` +
+				`advance, but keep up to minConns idle connections for reuse. Overrides the connection profile.`},
+		ArgMaxConnLifetime: {defValue: uint(0), argType: typeUInt,
+			desc: `Time in seconds after which a connection is closed and replaced. 0 keeps the default. ` +
+				`Overrides the connection profile.`},
+		ArgMaxConnIdleTime: {defValue: uint(0), argType: typeUInt,
+			desc: `Time in seconds after which an idle connection is closed. 0 keeps the default. ` +
+				`Overrides the connection profile.`},
+		ArgHealthCheckPeriod: {defValue: uint(0), argType: typeUInt,
+			desc: `Interval in seconds at which idle connections are checked (PostgreSQL only). 0 keeps the default. ` +
+				`Overrides the connection profile.`},
+		ArgConnectTimeout: {defValue: uint(0), argType: typeUInt,
+			desc: `Time in seconds to wait for a new connection. 0 keeps the default. ` +
+				`Overrides the connection profile.`},
+		ArgIsolationLevel: {short: "i", defValue: "read committed", argType: typeString,
+			desc: `Transaction isolation level. A name (UR, CS, RS, RR, 'read committed', 'repeatable read', ` +
+				`'serializable', etc.) which is mapped to the level with the same guarantees`},
 		ArgSpread: {short: "s", defValue: []string{"100%:8b"}, argType: typeStringArray,
 			desc: `spread. By default everything is 8 bytes`},
 		ArgByteSize: {short: "b", defValue: "1kb", argType: typeString,
@@ -52,5 +132,71 @@ var (
 			desc: `How many rows to generate`},
 		ArgBulkInsert: {short: "u", defValue: false, argType: typeBool,
 			desc: `Use bulk insertion. (Not possible remotely with DB2. Execute on host.)`},
+		ArgWorkloadFile: {short: "f", defValue: "workload.yaml", argType: typeString,
+			desc: `File with the transactions, statements and parameter generators of the workload`},
+		ArgOltpWorkers: {short: "o", defValue: uint(1), argType: typeUInt,
+			desc: `Number of workers (each on its own connection) running short update transactions`},
+		ArgOlapWorkers: {short: "a", defValue: uint(1), argType: typeUInt,
+			desc: `Number of workers (each on its own connection) running long reporting queries`},
+		ArgMaxRetries: {defValue: uint(0), argType: typeUInt,
+			desc: `How often a transaction which failed with an expected error (see retryOn) is retried`},
+		ArgRetryOn: {defValue: []string{"serialization", "deadlock", "lock_timeout"}, argType: typeStringArray,
+			desc: `Classes of errors which are expected, counted and retried instead of aborting the test. ` +
+				`(serialization, deadlock, lock_timeout, statement_timeout, connection_lost, other)`},
+		ArgRetryBackoff: {defValue: uint(10), argType: typeUInt,
+			desc: `Time in milliseconds to wait before retrying a failed transaction`},
+		ArgLockTimeout: {defValue: uint(0), argType: typeUInt,
+			desc: `Time in milliseconds a statement waits for a lock before it fails. 0 keeps the server default. ` +
+				`(DB2 rounds up to whole seconds)`},
+		ArgStatementTimeout: {defValue: uint(0), argType: typeUInt,
+			desc: `Time in milliseconds a statement may run before it is canceled. 0 keeps the server default. ` +
+				`(not available on DB2, use lockTimeout)`},
+		ArgConnectQuery: {defValue: false, argType: typeBool,
+			desc: `Run a test query on every new connection, so that the first round trip is part of the measurement`},
+		ArgReconnect: {defValue: false, argType: typeBool,
+			desc: `Let workers reconnect when their connection is lost (e.g. during a failover), instead of aborting ` +
+				`the test. Reconnects and downtime are reported.`},
+		ArgReconnectBackoff: {defValue: uint(100), argType: typeUInt,
+			desc: `Time in milliseconds to wait after a failed (re)connect attempt. It doubles after every ` +
+				`failed attempt.`},
+		ArgReconnectMaxBackoff: {defValue: uint(5000), argType: typeUInt,
+			desc: `Maximum time in milliseconds to wait between (re)connect attempts`},
+		ArgHeartbeatTable: {defValue: "dbtwooltests.heartbeat", argType: typeString,
+			desc: `Schema + table name of the heartbeat table of the failover drill. Its rows are removed first.`},
+		ArgHeartbeatInterval: {defValue: uint(100), argType: typeUInt,
+			desc: `Time in milliseconds between the heartbeats of a worker`},
+		ArgResultFile: {argType: typeString,
+			desc: `Write the result document of the test (parameters, results, server and client metrics) as JSON to ` +
+				`this file`},
+		ArgServerMetricsInterval: {defValue: uint(0), argType: typeUInt,
+			desc: `Sample server statistics on a separate connection every so many seconds during the test, and add ` +
+				`the deltas over the measurement window to the result. 0 disables sampling.`},
+		ArgClientMetricsInterval: {defValue: uint(0), argType: typeUInt,
+			desc: `Sample the CPU, memory, garbage collection and network usage of the client every so many ` +
+				`seconds during the test, and warn when the client is saturated. 0 disables sampling.`},
+		ArgMetricsListen: {argType: typeString,
+			desc: `Serve the progress of the command as Prometheus metrics on /metrics of this address (e.g. :9187)`},
+		ArgTraceEndpoint: {argType: typeString,
+			desc: `Export OpenTelemetry traces to this OTLP/HTTP endpoint (e.g. http://localhost:4318)`},
+		ArgTraceFile: {argType: typeString,
+			desc: `Append OpenTelemetry traces as JSON to this file`},
+		ArgTraceQuerySample: {defValue: uint(1000), argType: typeUInt,
+			desc: `Trace one in this many queries of the test workers when traces are exported. 0 traces none.`},
+		ArgReportInterval: {defValue: uint(0), argType: typeUInt,
+			desc: `Report the throughput and latency of the test every so many seconds, and add them to the ` +
+				`result document. 0 disables the report.`},
+		ArgReportFile: {defValue: "report.html", argType: typeString,
+			desc: `Write the HTML report to this file`},
+		ArgHistoryFile: {argType: typeString,
+			desc: `Keep the results of all runs in this SQLite file`},
+		ArgHistoryDSN: {argType: typeString,
+			desc: `Keep the results of all runs in the dbtwool_results schema of the PostgreSQL database of this ` +
+				`connection string. Overrides historyFile.`},
+		ArgLogLevel: {defValue: "info", argType: typeString,
+			desc: `The level of the log lines which are written (trace, debug, info, warn or error)`},
+		ArgLogFormat: {defValue: "json", argType: typeString,
+			desc: `The format of the log lines: json (one object per line) or console (human readable)`},
+		ArgLogFile: {argType: typeString,
+			desc: `Append the log lines to this file, next to writing them to stderr`},
 	}
 )
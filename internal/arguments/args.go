@@ -142,3 +142,23 @@ func (as Args) GetBool(argument string) (value bool) {
 	value = *arg.boolValue
 	return value
 }
+
+// Values returns the values of all arguments by name, e.a. to record the parameters of a test run
+func (as Args) Values() map[string]any {
+	values := make(map[string]any, len(as))
+	for name, arg := range as {
+		switch arg.argType {
+		case typeCount:
+			values[name] = *arg.intValue
+		case typeUInt:
+			values[name] = *arg.uIntValue
+		case typePath, typeString:
+			values[name] = *arg.stringValue
+		case typeStringArray:
+			values[name] = *arg.stringArrayValue
+		case typeBool:
+			values[name] = *arg.boolValue
+		}
+	}
+	return values
+}
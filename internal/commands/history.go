@@ -0,0 +1,181 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pgvillage-tools/dbtwool/internal/arguments"
+	"github.com/pgvillage-tools/dbtwool/pkg/history"
+	"github.com/spf13/cobra"
+)
+
+const (
+	idBase    = 10
+	idBitSize = 64
+)
+
+var historyArgs = []string{
+	arguments.ArgHistoryFile,
+	arguments.ArgHistoryDSN,
+}
+
+// HistoryConfig returns where the history is kept, from --historyFile and --historyDsn
+func HistoryConfig(args arguments.Args) history.Config {
+	return history.Config{
+		File: args.GetString(arguments.ArgHistoryFile),
+		DSN:  args.GetString(arguments.ArgHistoryDSN),
+	}
+}
+
+// withHistory opens the history, runs f on it and closes it again. Errors are returned with action (e.g. list
+// the runs) in the message.
+func withHistory(args arguments.Args, action string, f func(context.Context, *history.Store) error) error {
+	ctx := context.Background()
+	store, err := history.Open(ctx, HistoryConfig(args))
+	if err != nil {
+		return fmt.Errorf("failed to open the history to %s: %w", action, err)
+	}
+	defer func() { _ = store.Close(ctx) }()
+	if err = f(ctx, store); err != nil {
+		return fmt.Errorf("failed to %s: %w", action, err)
+	}
+	return nil
+}
+
+// parseID parses the id of a run, as printed by history list
+func parseID(id string) (int64, error) {
+	parsed, err := strconv.ParseInt(id, idBase, idBitSize)
+	if err != nil {
+		return 0, fmt.Errorf("invalid run id %q: %w", id, err)
+	}
+	return parsed, nil
+}
+
+// HistoryCommand returns the history command, which lists, shows and deletes the runs in the history
+func HistoryCommand() *cobra.Command {
+	historyCommand := &cobra.Command{
+		Use:   "history",
+		Short: "list, show and delete the results of earlier runs",
+		Long: "Use this command to manage the results which the lob-performance and ru-performance tests save " +
+			"with --historyFile or --historyDsn.",
+		RunE: RequireSubcommand,
+	}
+
+	historyCommand.AddCommand(
+		historyListCommand(),
+		historyShowCommand(),
+		historyDeleteCommand(),
+	)
+
+	return historyCommand
+}
+
+func historyListCommand() *cobra.Command {
+	var listArgs arguments.Args
+	listCommand := &cobra.Command{
+		Use:   "list [TEST]",
+		Short: "list runs",
+		Long:  "List the runs in the history, optionally of one test only (e.g. lob-performance)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, tests []string) error {
+			cmd.SilenceUsage = true
+			return withHistory(listArgs, "list the runs", func(ctx context.Context, store *history.Store) error {
+				entries, err := store.List(ctx, strings.Join(tests, ""))
+				if err != nil {
+					return err
+				}
+				printEntries(entries)
+				return nil
+			})
+		},
+	}
+
+	listArgs = arguments.AllArgs.CommandArgs(listCommand, historyArgs)
+
+	return listCommand
+}
+
+func printEntries(entries []history.Entry) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ID\tTEST\tRDBMS\tSTARTED\tDURATION\tOPERATIONS")
+	for _, entry := range entries {
+		doc := entry.Document
+		var operations []string
+		for _, op := range doc.Operations {
+			operations = append(operations,
+				fmt.Sprintf("%s %.1f ops/s p99 %.2f ms", op.Name, op.PerSecond, op.Latency.P99Ms))
+		}
+		_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", entry.ID, doc.Test, doc.RDBMS,
+			doc.StartedAt.Local().Format(time.DateTime), doc.FinishedAt.Sub(doc.StartedAt).Round(time.Second),
+			strings.Join(operations, ", "))
+	}
+	_ = w.Flush()
+}
+
+func historyShowCommand() *cobra.Command {
+	var showArgs arguments.Args
+	showCommand := &cobra.Command{
+		Use:   "show ID",
+		Short: "show a run",
+		Long:  "Print the result document of a run as JSON, e.g. to write a report of it",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, ids []string) error {
+			id, err := parseID(ids[0])
+			if err != nil {
+				return err
+			}
+			cmd.SilenceUsage = true
+			return withHistory(showArgs, fmt.Sprintf("show run %d", id),
+				func(ctx context.Context, store *history.Store) error {
+					doc, getErr := store.Get(ctx, id)
+					if getErr != nil {
+						return getErr
+					}
+					content, marshalErr := doc.Marshal()
+					if marshalErr != nil {
+						return marshalErr
+					}
+					fmt.Println(string(content))
+					return nil
+				})
+		},
+	}
+
+	showArgs = arguments.AllArgs.CommandArgs(showCommand, historyArgs)
+
+	return showCommand
+}
+
+func historyDeleteCommand() *cobra.Command {
+	var deleteArgs arguments.Args
+	deleteCommand := &cobra.Command{
+		Use:   "delete ID",
+		Short: "delete a run",
+		Long:  "Remove a run from the history",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, ids []string) error {
+			id, err := parseID(ids[0])
+			if err != nil {
+				return err
+			}
+			cmd.SilenceUsage = true
+			return withHistory(deleteArgs, fmt.Sprintf("delete run %d", id),
+				func(ctx context.Context, store *history.Store) error {
+					if deleteErr := store.Delete(ctx, id); deleteErr != nil {
+						return deleteErr
+					}
+					fmt.Printf("Run %d was deleted from the history\n", id)
+					return nil
+				})
+		},
+	}
+
+	deleteArgs = arguments.AllArgs.CommandArgs(deleteCommand, historyArgs)
+
+	return deleteCommand
+}
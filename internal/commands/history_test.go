@@ -0,0 +1,76 @@
+package commands_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pgvillage-tools/dbtwool/internal/commands"
+	"github.com/pgvillage-tools/dbtwool/pkg/history"
+	"github.com/pgvillage-tools/dbtwool/pkg/result"
+	"github.com/pgvillage-tools/dbtwool/pkg/stats"
+)
+
+// executeHistory runs the history command with args, and returns its error
+func executeHistory(args ...string) error {
+	cmd := commands.HistoryCommand()
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetArgs(args)
+	return cmd.Execute()
+}
+
+var _ = Describe("HistoryCommand", func() {
+	var (
+		cfg history.Config
+		id  int64
+	)
+	BeforeEach(func(ctx context.Context) {
+		cfg = history.Config{File: filepath.Join(GinkgoT().TempDir(), "history.sqlite")}
+		store, err := history.Open(ctx, cfg)
+		Expect(err).NotTo(HaveOccurred())
+		doc := result.Document{Test: "lob-performance", RDBMS: "sqlite", StartedAt: time.Now(), FinishedAt: time.Now(),
+			Operations: []stats.OperationSummary{{Name: "read", PerSecond: 1}}}
+		id, err = store.Save(ctx, doc)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(store.Close(ctx)).To(Succeed())
+	})
+	It("should require a sub command", func() {
+		Expect(executeHistory()).To(MatchError(ContainSubstring("missing command 'history COMMAND'")))
+	})
+	It("should list the runs in the history", func() {
+		Expect(executeHistory("list", "--historyFile", cfg.File, "lob-performance")).To(Succeed())
+	})
+	It("should show a run from the history", func() {
+		Expect(executeHistory("show", "--historyFile", cfg.File, fmt.Sprint(id))).To(Succeed())
+	})
+	It("should fail to show a run which is not in the history", func() {
+		err := executeHistory("show", "--historyFile", cfg.File, fmt.Sprint(id+1))
+		Expect(err).To(MatchError(history.ErrNotFound))
+		Expect(err).To(MatchError(ContainSubstring(fmt.Sprintf("failed to show run %d", id+1))))
+	})
+	It("should reject an invalid run id", func() {
+		Expect(executeHistory("delete", "--historyFile", cfg.File, "first")).To(
+			MatchError(ContainSubstring(`invalid run id "first"`)))
+	})
+	It("should fail when the history can not be opened", func() {
+		missing := filepath.Join(GinkgoT().TempDir(), "missing", "history.sqlite")
+		Expect(executeHistory("list", "--historyFile", missing)).To(
+			MatchError(ContainSubstring("failed to open the history to list the runs")))
+	})
+	It("should delete a run from the history", func(ctx context.Context) {
+		Expect(executeHistory("delete", "--historyFile", cfg.File, fmt.Sprint(id))).To(Succeed())
+
+		store, err := history.Open(ctx, cfg)
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { Expect(store.Close(ctx)).To(Succeed()) }()
+		Expect(store.List(ctx, "")).To(BeEmpty())
+		Expect(executeHistory("delete", "--historyFile", cfg.File, fmt.Sprint(id))).To(
+			MatchError(ContainSubstring(fmt.Sprintf("failed to delete run %d", id))))
+	})
+})
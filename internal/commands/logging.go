@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"github.com/pgvillage-tools/dbtwool/internal/arguments"
+	"github.com/pgvillage-tools/dbtwool/pkg/logging"
+	"github.com/spf13/cobra"
+)
+
+var (
+	logger = logging.Logger()
+	// closeLog closes the log file of --logFile, once the command has finished
+	closeLog = func() {}
+)
+
+// LogArgs are the logging options of the root command
+var LogArgs = []string{
+	arguments.ArgLogLevel,
+	arguments.ArgLogFormat,
+	arguments.ArgLogFile,
+}
+
+// SetupLogging returns a PersistentPreRunE, which applies the logging options of the root command before any
+// command runs, and starts the phase named after the command (e.g. gen or test)
+func SetupLogging(args *arguments.Args) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, _ []string) error {
+		closer, err := logging.Setup(logging.Config{
+			Level:  args.GetString(arguments.ArgLogLevel),
+			Format: args.GetString(arguments.ArgLogFormat),
+			File:   args.GetString(arguments.ArgLogFile),
+		})
+		if err != nil {
+			return err
+		}
+		closeLog = closer
+		logging.SetPhase(cmd.Name())
+		return nil
+	}
+}
+
+// CloseLog closes the log file of --logFile. It is called once the command has finished.
+func CloseLog() {
+	closeLog()
+}
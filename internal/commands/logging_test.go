@@ -0,0 +1,30 @@
+package commands_test
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+
+	"github.com/pgvillage-tools/dbtwool/internal/arguments"
+	"github.com/pgvillage-tools/dbtwool/internal/commands"
+)
+
+var _ = Describe("SetupLogging", func() {
+	It("should log to --logFile until the log is closed", func() {
+		logFile := filepath.Join(GinkgoT().TempDir(), "dbtwool.log")
+		cmd := &cobra.Command{Use: "gen"}
+		args := arguments.AllArgs.CommandArgs(cmd, commands.LogArgs)
+		Expect(cmd.ParseFlags([]string{"--logFile", logFile, "--logFormat", "json"})).To(Succeed())
+		Expect(commands.SetupLogging(&args)(cmd, nil)).To(Succeed())
+		commands.CloseLog()
+		Expect(logFile).To(BeARegularFile())
+	})
+	It("should fail on an unknown log level", func() {
+		cmd := &cobra.Command{Use: "gen"}
+		args := arguments.AllArgs.CommandArgs(cmd, commands.LogArgs)
+		Expect(cmd.ParseFlags([]string{"--logLevel", "chatty"})).To(Succeed())
+		Expect(commands.SetupLogging(&args)(cmd, nil)).NotTo(Succeed())
+	})
+})
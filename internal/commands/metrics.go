@@ -0,0 +1,22 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/pgvillage-tools/dbtwool/internal/arguments"
+	"github.com/pgvillage-tools/dbtwool/pkg/livemetrics"
+)
+
+// ServeMetrics serves the live metrics on --metricsListen while a command runs, when it is set. It returns the
+// function which stops serving.
+func ServeMetrics(args arguments.Args) (func(), error) {
+	addr := args.GetString(arguments.ArgMetricsListen)
+	if addr == "" {
+		return func() {}, nil
+	}
+	server, err := livemetrics.Serve(addr)
+	if err != nil {
+		return nil, err
+	}
+	return func() { _ = server.Close(context.Background()) }, nil
+}
@@ -0,0 +1,42 @@
+// Package commands holds the sub commands which work the same for every RDBMS (report and history), and the
+// plumbing every test command shares (logging, live metrics, tracing and result documents).
+// Like package arguments, they live here instead of being copied to every cmd folder.
+package commands
+
+import (
+	"github.com/pgvillage-tools/dbtwool/internal/arguments"
+	"github.com/pgvillage-tools/dbtwool/pkg/report"
+	"github.com/pgvillage-tools/dbtwool/pkg/result"
+	"github.com/spf13/cobra"
+)
+
+// ReportCommand returns the report command, which writes an HTML report of result documents
+func ReportCommand() *cobra.Command {
+	var reportArgs arguments.Args
+	reportCommand := &cobra.Command{
+		Use:   "report RESULTFILE...",
+		Short: "write an HTML report of result documents",
+		Long: "Use this command to turn result documents (written with --resultFile) into one self-contained HTML " +
+			"file, with throughput and latency charts, the intervals, parameters and server metrics of every run, " +
+			"and a comparison of DB2 and PostgreSQL for tests which ran on both.",
+		Args: cobra.MinimumNArgs(1),
+		Run: func(_ *cobra.Command, paths []string) {
+			var docs []result.Document
+			for _, path := range paths {
+				doc, err := result.Read(path)
+				if err != nil {
+					logger.Error().Err(err).Msg("An error occurred while reading the result documents")
+					return
+				}
+				docs = append(docs, doc)
+			}
+			if err := report.Write(reportArgs.GetString(arguments.ArgReportFile), docs); err != nil {
+				logger.Error().Err(err).Msg("An error occurred while writing the report")
+			}
+		},
+	}
+
+	reportArgs = arguments.AllArgs.CommandArgs(reportCommand, []string{arguments.ArgReportFile})
+
+	return reportCommand
+}
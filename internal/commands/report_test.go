@@ -0,0 +1,31 @@
+package commands_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pgvillage-tools/dbtwool/internal/commands"
+	"github.com/pgvillage-tools/dbtwool/pkg/result"
+)
+
+var _ = Describe("ReportCommand", func() {
+	It("should write a report of the result documents", func() {
+		dir := GinkgoT().TempDir()
+		resultFile := filepath.Join(dir, "pg-lob.json")
+		reportFile := filepath.Join(dir, "report.html")
+		doc := result.Document{Test: "lob-performance", RDBMS: "pg", StartedAt: time.Now(), FinishedAt: time.Now()}
+		Expect(doc.Write(resultFile)).To(Succeed())
+
+		cmd := commands.ReportCommand()
+		cmd.SetArgs([]string{"--reportFile", reportFile, resultFile})
+		Expect(cmd.Execute()).To(Succeed())
+
+		content, err := os.ReadFile(reportFile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(content)).To(ContainSubstring("lob-performance pg"))
+	})
+})
@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"context"
+	"time"
+
+	"github.com/pgvillage-tools/dbtwool/internal/arguments"
+	"github.com/pgvillage-tools/dbtwool/pkg/clientmetrics"
+	"github.com/pgvillage-tools/dbtwool/pkg/dbclient"
+	"github.com/pgvillage-tools/dbtwool/pkg/environment"
+	"github.com/pgvillage-tools/dbtwool/pkg/history"
+	"github.com/pgvillage-tools/dbtwool/pkg/result"
+	"github.com/pgvillage-tools/dbtwool/pkg/servermetrics"
+)
+
+// NewRun returns the collector of the result document of a test, which describes the client and the server, and
+// samples the server statistics of the table under test when --serverMetricsInterval is set (not for SQLite, which
+// has no server statistics), and the resource usage of the client with --clientMetricsInterval. With
+// --reportInterval it reports the throughput and latency of the test per interval.
+func NewRun(args arguments.Args, rdbms dbclient.RDBMS, test string, schema string, table string) *result.Run {
+	run := result.NewRun(test, string(rdbms), args.Values())
+	run.DescribeServer(environment.QueriesFor(rdbms))
+	if queries := servermetrics.QueriesFor(rdbms, schema, table); len(queries) > 0 {
+		if interval := args.GetUint(arguments.ArgServerMetricsInterval); interval > 0 {
+			run.SampleServer(servermetrics.NewSampler(time.Duration(interval)*time.Second, queries))
+		}
+	}
+	if interval := args.GetUint(arguments.ArgClientMetricsInterval); interval > 0 {
+		run.SampleClient(clientmetrics.NewSampler(time.Duration(interval) * time.Second))
+	}
+	if interval := args.GetUint(arguments.ArgReportInterval); interval > 0 {
+		run.ReportIntervals(time.Duration(interval) * time.Second)
+	}
+	return run
+}
+
+// WriteResult writes the result document to --resultFile, and saves it in the history (--historyFile or
+// --historyDsn), when they are set
+func WriteResult(args arguments.Args, run *result.Run) {
+	ctx := context.Background()
+	// the history is opened first, so that the password of its dsn is masked in the result file too
+	var store *history.Store
+	if cfg := HistoryConfig(args); cfg.Enabled() {
+		var err error
+		if store, err = history.Open(ctx, cfg); err != nil {
+			logger.Error().Err(err).Msg("An error occurred while opening the history")
+		} else {
+			defer func() { _ = store.Close(ctx) }()
+		}
+	}
+	if path := args.GetString(arguments.ArgResultFile); path != "" {
+		if err := run.Document.Write(path); err != nil {
+			logger.Error().Err(err).Msg("An error occurred while writing the result document")
+		}
+	}
+	if store != nil {
+		if _, err := store.Save(ctx, run.Document); err != nil {
+			logger.Error().Err(err).Msg("An error occurred while saving the result in the history")
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package commands_test
+
+import (
+	"context"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+
+	"github.com/pgvillage-tools/dbtwool/internal/arguments"
+	"github.com/pgvillage-tools/dbtwool/internal/commands"
+	"github.com/pgvillage-tools/dbtwool/pkg/dbclient"
+	"github.com/pgvillage-tools/dbtwool/pkg/history"
+	"github.com/pgvillage-tools/dbtwool/pkg/result"
+)
+
+var testArgs = []string{
+	arguments.ArgResultFile,
+	arguments.ArgHistoryFile,
+	arguments.ArgHistoryDSN,
+	arguments.ArgClientMetricsInterval,
+	arguments.ArgReportInterval,
+	arguments.ArgMetricsListen,
+	arguments.ArgTraceEndpoint,
+	arguments.ArgTraceFile,
+	arguments.ArgTraceQuerySample,
+}
+
+// parseArgs returns the arguments of a test command, parsed from flags
+func parseArgs(enabled []string, flags ...string) arguments.Args {
+	cmd := &cobra.Command{Use: "test"}
+	args := arguments.AllArgs.CommandArgs(cmd, enabled)
+	Expect(cmd.ParseFlags(flags)).To(Succeed())
+	return args
+}
+
+// resultArgs returns the arguments of a test command, with --serverMetricsInterval like pgtwool and dbtwool have
+func resultArgs(flags ...string) arguments.Args {
+	return parseArgs(append([]string{arguments.ArgServerMetricsInterval}, testArgs...), flags...)
+}
+
+var _ = Describe("NewRun", func() {
+	It("should sample the server statistics of the table under test", func() {
+		run := commands.NewRun(resultArgs("--serverMetricsInterval", "1"), dbclient.Postgres, "lob-performance",
+			"s", "t")
+		Expect(run.Document.RDBMS).To(Equal("pg"))
+		Expect(run.Conns()).To(Equal(1))
+	})
+	It("should not need --serverMetricsInterval for SQLite, which has no server statistics", func() {
+		run := commands.NewRun(parseArgs(testArgs, "--reportInterval", "1"), dbclient.SQLite, "lob-performance",
+			"s", "t")
+		Expect(run.Document.RDBMS).To(Equal("sqlite"))
+	})
+})
+
+var _ = Describe("WriteResult", func() {
+	It("should write the result document and save it in the history", func(ctx context.Context) {
+		dir := GinkgoT().TempDir()
+		resultFile := filepath.Join(dir, "result.json")
+		historyFile := filepath.Join(dir, "history.sqlite")
+		args := resultArgs("--resultFile", resultFile, "--historyFile", historyFile)
+		commands.WriteResult(args, commands.NewRun(args, dbclient.DB2, "ru-performance", "s", "t"))
+
+		doc, err := result.Read(resultFile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(doc.Test).To(Equal("ru-performance"))
+		store, err := history.Open(ctx, history.Config{File: historyFile})
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { Expect(store.Close(ctx)).To(Succeed()) }()
+		Expect(store.List(ctx, "ru-performance")).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("ServeMetrics", func() {
+	It("should not serve without --metricsListen", func() {
+		stop, err := commands.ServeMetrics(resultArgs())
+		Expect(err).NotTo(HaveOccurred())
+		stop()
+	})
+	It("should serve on --metricsListen until it is stopped", func() {
+		stop, err := commands.ServeMetrics(resultArgs("--metricsListen", "127.0.0.1:0"))
+		Expect(err).NotTo(HaveOccurred())
+		stop()
+	})
+})
+
+var _ = Describe("StartTracing", func() {
+	It("should export the traces to --traceFile until it is stopped", func() {
+		traceFile := filepath.Join(GinkgoT().TempDir(), "traces.json")
+		stop, err := commands.StartTracing(resultArgs("--traceFile", traceFile), dbclient.Postgres)
+		Expect(err).NotTo(HaveOccurred())
+		stop()
+		Expect(traceFile).To(BeARegularFile())
+	})
+	It("should fail when --traceFile can not be created", func() {
+		traceFile := filepath.Join(GinkgoT().TempDir(), "missing", "traces.json")
+		_, err := commands.StartTracing(resultArgs("--traceFile", traceFile), dbclient.Postgres)
+		Expect(err).To(MatchError(ContainSubstring("failed to open the trace file")))
+	})
+})
@@ -0,0 +1,38 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// RequireSubcommand returns an error if no sub command is provided
+// This was copied from skopeo, which copied it from podman: `github.com/containers/podman/cmd/podman/validate/args.go
+// Some small style changes to match skopeo were applied, but try to apply any
+// bugfixes there first.
+func RequireSubcommand(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		suggestions := cmd.SuggestionsFor(args[0])
+		if len(suggestions) == 0 {
+			return fmt.Errorf("unrecognized command `%[1]s %[2]s`\nTry '%[1]s --help' for more information",
+				cmd.CommandPath(), args[0])
+		}
+		return fmt.Errorf(strings.Join(
+			[]string{"unrecognized command `%[1]s %[2]s`",
+				"",
+				"Did you mean this?",
+				"\t%[3]s",
+				"",
+				"Try '%[1]s --help' for more information"}, "\n"),
+			cmd.CommandPath(),
+			args[0],
+			strings.Join(suggestions, "\n\t"))
+	}
+	return fmt.Errorf(
+		strings.Join([]string{
+			"missing command '%[1]s COMMAND'",
+			"Try '%[1]s --help' for more information",
+		}, "\n"),
+		cmd.CommandPath())
+}
@@ -0,0 +1,23 @@
+package commands_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pgvillage-tools/dbtwool/internal/commands"
+)
+
+var _ = Describe("RequireSubcommand", func() {
+	It("should suggest a sub command for a typo", func() {
+		cmd := commands.HistoryCommand()
+		// cobra sets the distance when it looks up a command, which this test skips
+		cmd.SuggestionsMinimumDistance = 2
+		Expect(commands.RequireSubcommand(cmd, []string{"lst"})).To(
+			MatchError(ContainSubstring("Did you mean this?\n\tlist")))
+	})
+	It("should reject an unknown sub command", func() {
+		cmd := commands.HistoryCommand()
+		Expect(commands.RequireSubcommand(cmd, []string{"purge"})).To(
+			MatchError(ContainSubstring("unrecognized command `history purge`")))
+	})
+})
@@ -0,0 +1,35 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/pgvillage-tools/dbtwool/internal/arguments"
+	"github.com/pgvillage-tools/dbtwool/pkg/dbclient"
+	"github.com/pgvillage-tools/dbtwool/pkg/tracing"
+)
+
+// serviceNames maps every RDBMS to the binary which tests it, which is the service name of its traces
+var serviceNames = map[dbclient.RDBMS]string{
+	dbclient.DB2:      "dbtwool",
+	dbclient.Postgres: "pgtwool",
+	dbclient.SQLite:   "litetwool",
+}
+
+// StartTracing exports traces to --traceEndpoint and --traceFile while a command runs, when they are set. It
+// returns the function which flushes the remaining spans and stops exporting.
+func StartTracing(args arguments.Args, rdbms dbclient.RDBMS) (func(), error) {
+	shutdown, err := tracing.Setup(context.Background(), tracing.Config{
+		Service:     serviceNames[rdbms],
+		Endpoint:    args.GetString(arguments.ArgTraceEndpoint),
+		File:        args.GetString(arguments.ArgTraceFile),
+		QuerySample: args.GetUint(arguments.ArgTraceQuerySample),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		if shutdownErr := shutdown(context.Background()); shutdownErr != nil {
+			logger.Error().Err(shutdownErr).Msg("An error occurred while exporting the traces")
+		}
+	}, nil
+}
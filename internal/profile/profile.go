@@ -0,0 +1,255 @@
+// Package profile reads named connection profiles from the dbtwool configuration file.
+// A profile holds the connection settings for one environment, so switching between environments only requires
+// --profile. Profile values sit between the environment variables and the built-in defaults of every RDBMS:
+// flags override environment variables, which override the profile, which overrides the defaults.
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+	"github.com/pgvillage-tools/dbtwool/pkg/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the layout of the configuration file
+type Config struct {
+	// Default is the profile which is used when --profile is not set
+	Default  string             `yaml:"default"`
+	Profiles map[string]Profile `yaml:"profiles"`
+}
+
+// Profile holds the connection settings for one environment. Empty values are left to the environment variables
+// and the defaults of the RDBMS.
+type Profile struct {
+	Host     string   `yaml:"host"`
+	Port     string   `yaml:"port"`
+	Database string   `yaml:"database"`
+	User     string   `yaml:"user"`
+	Password Password `yaml:"password"`
+	// SslMode is the PostgreSQL sslmode (disable, allow, prefer, require, verify-ca, verify-full)
+	SslMode string `yaml:"sslmode"`
+	// TargetSessionAttrs selects the server when Host holds multiple PostgreSQL hosts (read-write, primary, any, etc.)
+	TargetSessionAttrs string `yaml:"targetSessionAttrs"`
+	// Protocol is the DB2 PROTOCOL keyword (TCPIP, IPC, etc.)
+	Protocol string `yaml:"protocol"`
+	// Security is the DB2 SECURITY keyword (SSL)
+	Security string `yaml:"security"`
+	// AltHost and AltPort are the alternate DB2 server for client reroute (ALTHOSTNAME, ALTPORT)
+	AltHost string `yaml:"altHost"`
+	AltPort string `yaml:"altPort"`
+	// Options are extra DB2 connection string keywords, for example AUTHENTICATION or CURRENTSCHEMA
+	Options map[string]string `yaml:"options"`
+	TLS     TLS               `yaml:"tls"`
+	// BusyTimeout is the time in milliseconds SQLite waits for a locked database
+	BusyTimeout int  `yaml:"busyTimeout"`
+	Pool        Pool `yaml:"pool"`
+}
+
+// TLS holds the certificates for encrypted connections. Which fields apply depends on the RDBMS.
+type TLS struct {
+	// RootCert is the CA (or server) certificate the server is verified with (PostgreSQL sslrootcert, DB2
+	// SSLServerCertificate)
+	RootCert string `yaml:"rootCert"`
+	// Cert, Key and KeyPassword are the client certificate and key for mutual TLS with PostgreSQL (sslcert, sslkey,
+	// sslpassword)
+	Cert        string   `yaml:"cert"`
+	Key         string   `yaml:"key"`
+	KeyPassword Password `yaml:"keyPassword"`
+	// CRL is a list of revoked server certificates (PostgreSQL sslcrl)
+	CRL string `yaml:"crl"`
+	// Keystore, Keystash, KeystorePassword and Label select the client certificate for mutual TLS with DB2
+	// (SSLClientKeystoredb, SSLClientKeystash, SSLClientKeystoreDBPassword, SSLClientLabel)
+	Keystore         string   `yaml:"keystore"`
+	Keystash         string   `yaml:"keystash"`
+	KeystorePassword Password `yaml:"keystorePassword"`
+	Label            string   `yaml:"label"`
+}
+
+// Pool holds the sizing and connection lifecycle of the connection pool. Durations are written as 30s, 5m, 1h, etc.
+type Pool struct {
+	// MaxConns limits the pool. Leave empty to size the pool to the parallelism of the test.
+	MaxConns          int           `yaml:"maxConns"`
+	MinConns          int           `yaml:"minConns"`
+	MaxConnLifetime   time.Duration `yaml:"maxConnLifetime"`
+	MaxConnIdleTime   time.Duration `yaml:"maxConnIdleTime"`
+	HealthCheckPeriod time.Duration `yaml:"healthCheckPeriod"`
+	ConnectTimeout    time.Duration `yaml:"connectTimeout"`
+}
+
+// Override returns the pool settings where the values which are set in overrides replace the values of p
+func (p Pool) Override(overrides Pool) Pool {
+	if overrides.MaxConns != 0 {
+		p.MaxConns = overrides.MaxConns
+	}
+	if overrides.MinConns != 0 {
+		p.MinConns = overrides.MinConns
+	}
+	if overrides.MaxConnLifetime != 0 {
+		p.MaxConnLifetime = overrides.MaxConnLifetime
+	}
+	if overrides.MaxConnIdleTime != 0 {
+		p.MaxConnIdleTime = overrides.MaxConnIdleTime
+	}
+	if overrides.HealthCheckPeriod != 0 {
+		p.HealthCheckPeriod = overrides.HealthCheckPeriod
+	}
+	if overrides.ConnectTimeout != 0 {
+		p.ConnectTimeout = overrides.ConnectTimeout
+	}
+	return p
+}
+
+// Settings returns the pool settings for a client
+func (p Pool) Settings() dbinterface.PoolSettings {
+	return dbinterface.PoolSettings{
+		MaxConns:          p.MaxConns,
+		MinConns:          p.MinConns,
+		MaxConnLifetime:   p.MaxConnLifetime,
+		MaxConnIdleTime:   p.MaxConnIdleTime,
+		HealthCheckPeriod: p.HealthCheckPeriod,
+		ConnectTimeout:    p.ConnectTimeout,
+	}
+}
+
+// Password describes where the password of a profile comes from. Only one of Value, Env, File and Command should be
+// set.
+// In the configuration file it can also be written as a plain string, which is the same as setting Value.
+type Password struct {
+	Value string `yaml:"value"`
+	// Env is the name of an environment variable holding the password
+	Env string `yaml:"env"`
+	// File is the path of a file holding the password. Trailing newlines are stripped.
+	File string `yaml:"file"`
+	// Command is run with sh -c, and should print the password on stdout. Trailing newlines are stripped.
+	Command string `yaml:"command"`
+}
+
+// UnmarshalYAML allows the password to be set as a plain string as well as a mapping
+func (p *Password) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		p.Value = node.Value
+		return nil
+	}
+	type plain Password
+	return node.Decode((*plain)(p))
+}
+
+// Resolve returns the password from its source. An empty string is returned when no source is set.
+func (p Password) Resolve() (string, error) {
+	var sources int
+	for _, source := range []string{p.Value, p.Env, p.File, p.Command} {
+		if source != "" {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return "", errors.New("password should have only one of value, env, file and command")
+	}
+	switch {
+	case p.Env != "":
+		value, ok := os.LookupEnv(p.Env)
+		if !ok {
+			return "", fmt.Errorf("password environment variable %s is not set", p.Env)
+		}
+		return value, nil
+	case p.File != "":
+		content, err := os.ReadFile(utils.ResolveHome(p.File))
+		if err != nil {
+			return "", fmt.Errorf("failed to read password file: %w", err)
+		}
+		return strings.TrimRight(string(content), "\r\n"), nil
+	case p.Command != "":
+		// #nosec G204 -- running a password helper of the users choice is the purpose of this option
+		cmd := exec.Command("sh", "-c", p.Command)
+		cmd.Stderr = os.Stderr
+		output, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("password command failed: %w", err)
+		}
+		return strings.TrimRight(string(output), "\r\n"), nil
+	default:
+		return p.Value, nil
+	}
+}
+
+// ResolveUnless is Resolve, but returns an empty string when one of overrides is set. Pass the passwords with a
+// higher precedence as overrides, so that e.g. a password command does not run for a password which is not used.
+func (p Password) ResolveUnless(overrides ...string) (string, error) {
+	if First(overrides...) != "" {
+		return "", nil
+	}
+	return p.Resolve()
+}
+
+// Load reads the configuration file. A missing file results in an empty Config, so that dbtwool can run on
+// environment variables only.
+func Load(path string) (Config, error) {
+	var config Config
+	content, err := os.ReadFile(utils.ResolveHome(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	} else if err != nil {
+		return config, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	if err = yaml.Unmarshal(content, &config); err != nil {
+		return config, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	if config.Default != "" {
+		if _, exists := config.Profiles[config.Default]; !exists {
+			return config, fmt.Errorf("default profile %q is not defined in %s", config.Default, path)
+		}
+	}
+	return config, nil
+}
+
+// Get returns the profile with the given name, or the default profile when name is empty.
+// Without a name and without a default an empty Profile is returned.
+func (c Config) Get(name string) (Profile, error) {
+	if name == "" {
+		name = c.Default
+	}
+	if name == "" {
+		return Profile{}, nil
+	}
+	p, exists := c.Profiles[name]
+	if !exists {
+		return Profile{}, fmt.Errorf("profile %q is not defined (available: %s)", name, strings.Join(c.Names(), ", "))
+	}
+	return p, nil
+}
+
+// Names returns the sorted names of all profiles
+func (c Config) Names() []string {
+	var names []string
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Select loads the configuration file and returns the requested profile
+func Select(path string, name string) (Profile, error) {
+	config, err := Load(path)
+	if err != nil {
+		return Profile{}, err
+	}
+	return config.Get(name)
+}
+
+// First returns the first value which is not empty. It is used to apply flag > env > profile > default precedence.
+func First(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,172 @@
+package profile_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pgvillage-tools/dbtwool/internal/profile"
+)
+
+const testConfig = `
+default: pg-test
+profiles:
+  pg-test:
+    host: pgtest.example.com
+    port: "5433"
+    database: lobs
+    user: tester
+    password: secret
+    sslmode: require
+    tls:
+      rootCert: ~/.dbtwool/root.crt
+      cert: ~/.dbtwool/client.crt
+      key: ~/.dbtwool/client.key
+    pool:
+      minConns: 2
+      maxConns: 64
+      maxConnLifetime: 30m
+      connectTimeout: 5s
+  db2-prod:
+    host: db2prod.example.com
+    user: db2inst1
+    password:
+      env: DBTWOOL_TEST_DB2_PASSWORD
+    security: SSL
+    tls:
+      rootCert: /certs/db2ca.arm
+      keystore: /certs/client.kdb
+      keystash: /certs/client.sth
+      label: dbtwool
+    options:
+      AUTHENTICATION: SERVER_ENCRYPT
+`
+
+var _ = Describe("Profile", func() {
+	var cfgFile string
+
+	BeforeEach(func() {
+		cfgFile = filepath.Join(GinkgoT().TempDir(), "config.yaml")
+		Expect(os.WriteFile(cfgFile, []byte(testConfig), 0o600)).To(Succeed())
+	})
+
+	Describe("Load", func() {
+		It("should read all profiles", func() {
+			config, err := profile.Load(cfgFile)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.Names()).To(Equal([]string{"db2-prod", "pg-test"}))
+			Expect(config.Profiles["pg-test"].Pool).To(Equal(profile.Pool{
+				MinConns:        2,
+				MaxConns:        64,
+				MaxConnLifetime: 30 * time.Minute,
+				ConnectTimeout:  5 * time.Second,
+			}))
+			Expect(config.Profiles["db2-prod"].Options).To(HaveKeyWithValue("AUTHENTICATION", "SERVER_ENCRYPT"))
+		})
+		It("should return an empty config when the file does not exist", func() {
+			config, err := profile.Load(filepath.Join(GinkgoT().TempDir(), "missing.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.Profiles).To(BeEmpty())
+		})
+		It("should fail on an invalid file", func() {
+			Expect(os.WriteFile(cfgFile, []byte("profiles: ["), 0o600)).To(Succeed())
+			_, err := profile.Load(cfgFile)
+			Expect(err).To(MatchError(ContainSubstring("failed to parse config file")))
+		})
+		It("should fail when the default profile does not exist", func() {
+			Expect(os.WriteFile(cfgFile, []byte("default: missing\n"), 0o600)).To(Succeed())
+			_, err := profile.Load(cfgFile)
+			Expect(err).To(MatchError(ContainSubstring(`default profile "missing"`)))
+		})
+	})
+
+	Describe("Select", func() {
+		It("should return the default profile without a name", func() {
+			p, err := profile.Select(cfgFile, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(p.Host).To(Equal("pgtest.example.com"))
+			Expect(p.Port).To(Equal("5433"))
+			Expect(p.SslMode).To(Equal("require"))
+			Expect(p.TLS.Cert).To(Equal("~/.dbtwool/client.crt"))
+			Expect(p.TLS.Key).To(Equal("~/.dbtwool/client.key"))
+		})
+		It("should return the requested profile", func() {
+			p, err := profile.Select(cfgFile, "db2-prod")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(p.Host).To(Equal("db2prod.example.com"))
+			Expect(p.Security).To(Equal("SSL"))
+			Expect(p.TLS.Keystore).To(Equal("/certs/client.kdb"))
+			Expect(p.TLS.Label).To(Equal("dbtwool"))
+		})
+		It("should fail on an unknown profile and list the available ones", func() {
+			_, err := profile.Select(cfgFile, "db2-test")
+			Expect(err).To(MatchError(ContainSubstring("available: db2-prod, pg-test")))
+		})
+		It("should return an empty profile without config file and name", func() {
+			p, err := profile.Select(filepath.Join(GinkgoT().TempDir(), "missing.yaml"), "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(p).To(Equal(profile.Profile{}))
+		})
+	})
+
+	Describe("Password", func() {
+		It("should accept a plain string", func() {
+			p, err := profile.Select(cfgFile, "pg-test")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(p.Password.Resolve()).To(Equal("secret"))
+		})
+		It("should read the password from an env variable", func() {
+			GinkgoT().Setenv("DBTWOOL_TEST_DB2_PASSWORD", "fromenv")
+			p, err := profile.Select(cfgFile, "db2-prod")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(p.Password.Resolve()).To(Equal("fromenv"))
+		})
+		It("should fail when the env variable is not set", func() {
+			_, err := profile.Password{Env: "DBTWOOL_TEST_UNSET_PASSWORD"}.Resolve()
+			Expect(err).To(MatchError(ContainSubstring("is not set")))
+		})
+		It("should read the password from a file without trailing newline", func() {
+			passwordFile := filepath.Join(GinkgoT().TempDir(), "password")
+			Expect(os.WriteFile(passwordFile, []byte("fromfile\n"), 0o600)).To(Succeed())
+			Expect(profile.Password{File: passwordFile}.Resolve()).To(Equal("fromfile"))
+		})
+		It("should run the password command", func() {
+			Expect(profile.Password{Command: "echo fromcommand"}.Resolve()).To(Equal("fromcommand"))
+		})
+		It("should fail when the password command fails", func() {
+			_, err := profile.Password{Command: "exit 3"}.Resolve()
+			Expect(err).To(MatchError(ContainSubstring("password command failed")))
+		})
+		It("should fail with more than one source", func() {
+			_, err := profile.Password{Value: "a", File: "b"}.Resolve()
+			Expect(err).To(MatchError(ContainSubstring("only one of")))
+		})
+		It("should not resolve a password which is overridden", func() {
+			Expect(profile.Password{Command: "exit 3"}.ResolveUnless("", "fromflag")).To(BeEmpty())
+		})
+		It("should resolve a password which is not overridden", func() {
+			Expect(profile.Password{Command: "echo fromcommand"}.ResolveUnless("", "")).To(Equal("fromcommand"))
+		})
+	})
+
+	Describe("Pool", func() {
+		It("should only override the values which are set", func() {
+			pool := profile.Pool{MaxConns: 64, MinConns: 2, ConnectTimeout: 5 * time.Second}
+			settings := pool.Override(profile.Pool{MaxConns: 8, MaxConnIdleTime: time.Minute}).Settings()
+			Expect(settings.MaxConns).To(Equal(8))
+			Expect(settings.MinConns).To(Equal(2))
+			Expect(settings.MaxConnIdleTime).To(Equal(time.Minute))
+			Expect(settings.ConnectTimeout).To(Equal(5 * time.Second))
+		})
+	})
+
+	Describe("First", func() {
+		It("should return the first value which is set", func() {
+			Expect(profile.First("", "env", "profile", "default")).To(Equal("env"))
+			Expect(profile.First("", "", "")).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,13 @@
+package clientmetrics_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestClientmetrics(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Clientmetrics Suite")
+}
@@ -0,0 +1,21 @@
+// Package clientmetrics samples the resource usage of dbtwool itself while a test runs: CPU, go routines, garbage
+// collection, memory and network traffic. When the client is saturated, a result measures dbtwool rather than the
+// database, which these metrics reveal.
+package clientmetrics
+
+import (
+	"github.com/pgvillage-tools/dbtwool/pkg/logging"
+)
+
+var logger = logging.Logger()
+
+const (
+	// defaultProcRoot is where the proc filesystem is mounted. It is not available on all platforms (e.g. macOS),
+	// in which case only the runtime metrics are collected.
+	defaultProcRoot = "/proc"
+	// clockTicks is the number of clock ticks per second in /proc/self/stat (USER_HZ), which is 100 on all
+	// supported Linux architectures
+	clockTicks = 100
+	// saturatedPct is the CPU usage (of the process or the host) above which the client is considered saturated
+	saturatedPct = 90
+)
@@ -0,0 +1,129 @@
+package clientmetrics
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	bitSize64  = 64
+	bytesPerKB = 1024
+	// the columns of /proc/net/dev with the bytes received and transmitted
+	netDevRxBytes = 0
+	netDevTxBytes = 8
+)
+
+// readProcessCPU returns the user and system CPU time of this process in seconds
+func readProcessCPU(procRoot string) (float64, error) {
+	content, err := os.ReadFile(filepath.Join(procRoot, "self", "stat"))
+	if err != nil {
+		return 0, err
+	}
+	// the command (field 2) may contain spaces, so the fields are counted from the closing parenthesis
+	stat := string(content)
+	end := strings.LastIndex(stat, ")")
+	if end < 0 {
+		return 0, fmt.Errorf("unexpected format of %s/self/stat", procRoot)
+	}
+	fields := strings.Fields(stat[end+1:])
+	// utime and stime are field 14 and 15, and fields starts at field 3
+	const utime, stime = 14 - 3, 15 - 3
+	if len(fields) <= stime {
+		return 0, fmt.Errorf("unexpected format of %s/self/stat", procRoot)
+	}
+	var ticks float64
+	for _, field := range []string{fields[utime], fields[stime]} {
+		value, parseErr := strconv.ParseFloat(field, bitSize64)
+		if parseErr != nil {
+			return 0, fmt.Errorf("unexpected format of %s/self/stat: %w", procRoot, parseErr)
+		}
+		ticks += value
+	}
+	return ticks / clockTicks, nil
+}
+
+// readRSS returns the resident memory of this process in bytes
+func readRSS(procRoot string) (float64, error) {
+	file, err := os.Open(filepath.Join(procRoot, "self", "status"))
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = file.Close() }()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "VmRSS:" {
+			kb, parseErr := strconv.ParseFloat(fields[1], bitSize64)
+			if parseErr != nil {
+				return 0, fmt.Errorf("unexpected format of %s/self/status: %w", procRoot, parseErr)
+			}
+			return kb * bytesPerKB, nil
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("no VmRSS in %s/self/status", procRoot)
+}
+
+// readHostCPU returns the busy and total CPU time of the host in clock ticks, from the first line of /proc/stat
+func readHostCPU(procRoot string) (busy float64, total float64, err error) {
+	file, err := os.Open(filepath.Join(procRoot, "stat"))
+	if err != nil {
+		return 0, 0, err
+	}
+	defer func() { _ = file.Close() }()
+	scanner := bufio.NewScanner(file)
+	if !scanner.Scan() {
+		return 0, 0, fmt.Errorf("empty %s/stat", procRoot)
+	}
+	fields := strings.Fields(scanner.Text())
+	// cpu user nice system idle iowait irq softirq steal ...
+	const idle, iowait = 4, 5
+	if len(fields) <= iowait || fields[0] != "cpu" {
+		return 0, 0, fmt.Errorf("unexpected format of %s/stat", procRoot)
+	}
+	for i, field := range fields[1:] {
+		value, parseErr := strconv.ParseFloat(field, bitSize64)
+		if parseErr != nil {
+			return 0, 0, fmt.Errorf("unexpected format of %s/stat: %w", procRoot, parseErr)
+		}
+		total += value
+		if i+1 != idle && i+1 != iowait {
+			busy += value
+		}
+	}
+	return busy, total, nil
+}
+
+// readNetwork returns the bytes received and sent on all interfaces but loopback
+func readNetwork(procRoot string) (rx float64, tx float64, err error) {
+	file, err := os.Open(filepath.Join(procRoot, "net", "dev"))
+	if err != nil {
+		return 0, 0, err
+	}
+	defer func() { _ = file.Close() }()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		iface, counters, found := strings.Cut(scanner.Text(), ":")
+		if !found || strings.TrimSpace(iface) == "lo" {
+			continue
+		}
+		fields := strings.Fields(counters)
+		if len(fields) <= netDevTxBytes {
+			continue
+		}
+		received, rxErr := strconv.ParseFloat(fields[netDevRxBytes], bitSize64)
+		sent, txErr := strconv.ParseFloat(fields[netDevTxBytes], bitSize64)
+		if rxErr != nil || txErr != nil {
+			continue
+		}
+		rx += received
+		tx += sent
+	}
+	return rx, tx, scanner.Err()
+}
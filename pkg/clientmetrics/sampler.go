@@ -0,0 +1,257 @@
+package clientmetrics
+
+import (
+	"context"
+	"runtime"
+	"sort"
+	"time"
+)
+
+// Stat holds the average and maximum of a sampled value over the measurement window
+type Stat struct {
+	Avg float64 `json:"avg"`
+	Max float64 `json:"max"`
+}
+
+type accumulator struct {
+	sum   float64
+	max   float64
+	count int
+}
+
+func (a *accumulator) add(value float64) {
+	a.sum += value
+	a.count++
+	if a.count == 1 || value > a.max {
+		a.max = value
+	}
+}
+
+func (a accumulator) stat() Stat {
+	if a.count == 0 {
+		return Stat{}
+	}
+	return Stat{Avg: a.sum / float64(a.count), Max: a.max}
+}
+
+// Metrics holds the resource usage of the client over the measurement window
+type Metrics struct {
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	SampleCount int       `json:"sample_count"`
+	// ProcessCPUPct is the CPU usage of dbtwool as a percentage of the cores it may use (GOMAXPROCS)
+	ProcessCPUPct Stat `json:"process_cpu_pct"`
+	// HostCPUPct is the CPU usage of all processes on the client host
+	HostCPUPct Stat    `json:"host_cpu_pct"`
+	Goroutines Stat    `json:"goroutines"`
+	HeapBytes  Stat    `json:"heap_bytes"`
+	RSSBytes   Stat    `json:"rss_bytes"`
+	GCCount    uint32  `json:"gc_count"`
+	GCPauseMs  float64 `json:"gc_pause_ms"`
+	// NetRxBytes and NetTxBytes are the bytes received and sent by the host on all interfaces but loopback
+	NetRxBytes float64 `json:"net_rx_bytes"`
+	NetTxBytes float64 `json:"net_tx_bytes"`
+	// SaturatedSamples is the number of samples where the process or host CPU usage was above 90%
+	SaturatedSamples int `json:"saturated_samples"`
+	// Unavailable lists the metrics which could not be read on this platform
+	Unavailable []string `json:"unavailable,omitempty"`
+}
+
+type snapshot struct {
+	time       time.Time
+	processCPU float64
+	hostBusy   float64
+	hostTotal  float64
+	rss        float64
+	rx         float64
+	tx         float64
+	goroutines int
+	heap       uint64
+	numGC      uint32
+	gcPauseNs  uint64
+}
+
+// Sampler samples the resource usage of the client every interval, from Start until Stop. All samples are taken on
+// one go routine, so the state needs no locking.
+type Sampler struct {
+	interval time.Duration
+	procRoot string
+
+	cancel      context.CancelFunc
+	done        chan struct{}
+	mark        chan struct{}
+	unavailable map[string]bool
+	baseline    snapshot
+	last        snapshot
+	count       int
+	saturated   int
+	processCPU  accumulator
+	hostCPU     accumulator
+	goroutines  accumulator
+	heap        accumulator
+	rss         accumulator
+}
+
+// NewSampler returns a Sampler which samples every interval
+func NewSampler(interval time.Duration) *Sampler {
+	return &Sampler{
+		interval:    interval,
+		procRoot:    defaultProcRoot,
+		unavailable: map[string]bool{},
+		done:        make(chan struct{}),
+		mark:        make(chan struct{}, 1),
+	}
+}
+
+// Start takes the first sample and keeps sampling in the background until Stop is called. The first sample is the
+// baseline until MarkStart is called.
+func (s *Sampler) Start(ctx context.Context) {
+	s.baseline = s.take()
+	s.last = s.baseline
+	ctx, s.cancel = context.WithCancel(ctx)
+	go s.loop(ctx)
+}
+
+// MarkStart makes the next sample, which is taken right away, the baseline of the measurement window.
+// Samples which were collected before are discarded.
+func (s *Sampler) MarkStart() {
+	select {
+	case s.mark <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Sampler) loop(ctx context.Context) {
+	defer close(s.done)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.mark:
+			s.reset(s.take())
+		case <-ticker.C:
+			s.record(s.take())
+		}
+	}
+}
+
+func (s *Sampler) reset(baseline snapshot) {
+	s.baseline, s.last = baseline, baseline
+	s.count, s.saturated = 0, 0
+	s.processCPU = accumulator{}
+	s.hostCPU = accumulator{}
+	s.goroutines = accumulator{}
+	s.heap = accumulator{}
+	s.rss = accumulator{}
+}
+
+// record adds a sample, where the CPU usage is computed over the time since the previous sample
+func (s *Sampler) record(current snapshot) {
+	previous := s.last
+	s.last = current
+	s.count++
+	s.goroutines.add(float64(current.goroutines))
+	s.heap.add(float64(current.heap))
+	if !s.unavailable[metricRSS] {
+		s.rss.add(current.rss)
+	}
+	saturated := false
+	if elapsed := current.time.Sub(previous.time).Seconds(); elapsed > 0 && !s.unavailable[metricProcessCPU] {
+		pct := (current.processCPU - previous.processCPU) / elapsed / float64(runtime.GOMAXPROCS(0)) * 100
+		s.processCPU.add(pct)
+		saturated = pct >= saturatedPct
+	}
+	if total := current.hostTotal - previous.hostTotal; total > 0 && !s.unavailable[metricHostCPU] {
+		pct := (current.hostBusy - previous.hostBusy) / total * 100
+		s.hostCPU.add(pct)
+		saturated = saturated || pct >= saturatedPct
+	}
+	if !saturated {
+		return
+	}
+	s.saturated++
+	if s.saturated == 1 {
+		logger.Warn().
+			Float64("process_cpu_pct", s.processCPU.max).
+			Float64("host_cpu_pct", s.hostCPU.max).
+			Msg("The client CPU is saturated, so the results may measure dbtwool rather than the database")
+	}
+}
+
+// Stop stops sampling and takes a last sample. It returns the metrics between the baseline and the last sample.
+func (s *Sampler) Stop() Metrics {
+	s.cancel()
+	<-s.done
+	s.record(s.take())
+	metrics := Metrics{
+		Start:            s.baseline.time,
+		End:              s.last.time,
+		SampleCount:      s.count,
+		ProcessCPUPct:    s.processCPU.stat(),
+		HostCPUPct:       s.hostCPU.stat(),
+		Goroutines:       s.goroutines.stat(),
+		HeapBytes:        s.heap.stat(),
+		RSSBytes:         s.rss.stat(),
+		GCCount:          s.last.numGC - s.baseline.numGC,
+		GCPauseMs:        float64(s.last.gcPauseNs-s.baseline.gcPauseNs) / float64(time.Millisecond),
+		NetRxBytes:       s.last.rx - s.baseline.rx,
+		NetTxBytes:       s.last.tx - s.baseline.tx,
+		SaturatedSamples: s.saturated,
+	}
+	if s.unavailable[metricNetwork] {
+		metrics.NetRxBytes, metrics.NetTxBytes = 0, 0
+	}
+	for name := range s.unavailable {
+		metrics.Unavailable = append(metrics.Unavailable, name)
+	}
+	sort.Strings(metrics.Unavailable)
+	return metrics
+}
+
+const (
+	metricProcessCPU = "process_cpu"
+	metricHostCPU    = "host_cpu"
+	metricRSS        = "rss"
+	metricNetwork    = "network"
+)
+
+// take reads all metrics once. Metrics which cannot be read are skipped from then on.
+func (s *Sampler) take() snapshot {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	current := snapshot{
+		time:       time.Now(),
+		goroutines: runtime.NumGoroutine(),
+		heap:       memStats.HeapAlloc,
+		numGC:      memStats.NumGC,
+		gcPauseNs:  memStats.PauseTotalNs,
+	}
+	var err error
+	if !s.unavailable[metricProcessCPU] {
+		current.processCPU, err = readProcessCPU(s.procRoot)
+		s.check(metricProcessCPU, err)
+	}
+	if !s.unavailable[metricHostCPU] {
+		current.hostBusy, current.hostTotal, err = readHostCPU(s.procRoot)
+		s.check(metricHostCPU, err)
+	}
+	if !s.unavailable[metricRSS] {
+		current.rss, err = readRSS(s.procRoot)
+		s.check(metricRSS, err)
+	}
+	if !s.unavailable[metricNetwork] {
+		current.rx, current.tx, err = readNetwork(s.procRoot)
+		s.check(metricNetwork, err)
+	}
+	return current
+}
+
+func (s *Sampler) check(metric string, err error) {
+	if err == nil {
+		return
+	}
+	logger.Info().Err(err).Str("metric", metric).Msg("Client metric is not available, skipping")
+	s.unavailable[metric] = true
+}
@@ -0,0 +1,90 @@
+package clientmetrics
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func writeProc(root string, name string, content string) {
+	path := filepath.Join(root, name)
+	Ω(os.MkdirAll(filepath.Dir(path), 0o700)).To(Succeed())
+	Ω(os.WriteFile(path, []byte(content), 0o600)).To(Succeed())
+}
+
+var _ = Describe("Client metrics", func() {
+	Context("proc", func() {
+		var root string
+		BeforeEach(func() {
+			root = GinkgoT().TempDir()
+			writeProc(root, "self/stat",
+				"1234 (db two) S 1 1234 1234 0 -1 4194560 100 0 0 0 250 50 0 0 20 0 12 0 100 1000 200")
+			writeProc(root, "self/status", "Name:\tdbtwool\nVmPeak:\t  20000 kB\nVmRSS:\t   10240 kB\n")
+			writeProc(root, "stat", "cpu  100 0 100 700 100 0 0 0 0 0\ncpu0 50 0 50 350 50 0 0 0 0 0\n")
+			writeProc(root, "net/dev", `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier
+    lo:    5000      10    0    0    0     0          0         0     5000      10    0    0    0     0       0
+  eth0:    1000      10    0    0    0     0          0         0      300       3    0    0    0     0       0
+  eth1:      24       1    0    0    0     0          0         0       12       1    0    0    0     0       0
+`)
+		})
+		It("should read the CPU time of the process, also when the command has spaces", func() {
+			Ω(readProcessCPU(root)).To(Equal(3.0))
+		})
+		It("should read the resident memory", func() {
+			Ω(readRSS(root)).To(Equal(10240.0 * 1024))
+		})
+		It("should read the busy and total CPU time of the host", func() {
+			busy, total, err := readHostCPU(root)
+			Ω(err).NotTo(HaveOccurred())
+			Ω(busy).To(Equal(200.0))
+			Ω(total).To(Equal(1000.0))
+		})
+		It("should sum the network traffic of all interfaces but loopback", func() {
+			rx, tx, err := readNetwork(root)
+			Ω(err).NotTo(HaveOccurred())
+			Ω(rx).To(Equal(1024.0))
+			Ω(tx).To(Equal(312.0))
+		})
+	})
+
+	Context("Sampler", func() {
+		It("should count samples where the host CPU is saturated", func() {
+			s := NewSampler(time.Second)
+			start := time.Now()
+			s.reset(snapshot{time: start, hostBusy: 0, hostTotal: 100})
+			s.record(snapshot{time: start.Add(time.Second), hostBusy: 50, hostTotal: 200})
+			s.record(snapshot{time: start.Add(2 * time.Second), hostBusy: 145, hostTotal: 300})
+			Ω(s.hostCPU.stat()).To(Equal(Stat{Avg: 72.5, Max: 95}))
+			Ω(s.saturated).To(Equal(1))
+		})
+		It("should count samples where the process uses all its cores", func() {
+			s := NewSampler(time.Second)
+			s.unavailable[metricHostCPU] = true
+			start := time.Now()
+			cores := float64(runtime.GOMAXPROCS(0))
+			s.reset(snapshot{time: start})
+			s.record(snapshot{time: start.Add(time.Second), processCPU: cores})
+			Ω(s.processCPU.stat().Max).To(BeNumerically("~", 100, 0.001))
+			Ω(s.saturated).To(Equal(1))
+		})
+		It("should collect the runtime metrics when proc is not available", func() {
+			s := NewSampler(5 * time.Millisecond)
+			s.procRoot = filepath.Join(GinkgoT().TempDir(), "missing")
+			s.Start(context.Background())
+			s.MarkStart()
+			time.Sleep(20 * time.Millisecond)
+			metrics := s.Stop()
+			Ω(metrics.SampleCount).To(BeNumerically(">", 0))
+			Ω(metrics.Goroutines.Max).To(BeNumerically(">", 0))
+			Ω(metrics.HeapBytes.Max).To(BeNumerically(">", 0))
+			Ω(metrics.Unavailable).To(Equal([]string{metricHostCPU, metricNetwork, metricProcessCPU, metricRSS}))
+			Ω(metrics.SaturatedSamples).To(Equal(0))
+		})
+	})
+})
@@ -0,0 +1,13 @@
+package connperformance_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestConnperformance(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Connperformance Suite")
+}
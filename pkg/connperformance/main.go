@@ -0,0 +1,14 @@
+// Package connperformance measures the cost of setting up connections, by opening and closing physical connections
+// (outside of the pool) as fast as possible on a number of parallel workers
+package connperformance
+
+import (
+	"github.com/pgvillage-tools/dbtwool/pkg/logging"
+)
+
+var logger = logging.Logger()
+
+const (
+	defaultWarmupTime    = 10
+	defaultExecutionTime = 20
+)
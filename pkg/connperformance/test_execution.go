@@ -0,0 +1,153 @@
+package connperformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+	"github.com/pgvillage-tools/dbtwool/pkg/stats"
+	"github.com/pgvillage-tools/dbtwool/pkg/testrunner"
+)
+
+const (
+	opConnect = "connect"
+	opNetwork = "network"
+)
+
+// ExecuteTest opens and closes new connections on parallel workers. After the warmup, the connections per second
+// and the connect latency are measured. With query set, every connection also runs a test query, so that the first
+// round trip is included in the latency. After a failed connect a worker waits for the backoff of reconnect before
+// it tries again, so that a database which refuses connections is not flooded with attempts.
+func ExecuteTest(
+	ctx context.Context,
+	client dbinterface.Client,
+	parallel int,
+	warmupTime int,
+	executionTime int,
+	query bool,
+	reconnect testrunner.ReconnectPolicy,
+) error {
+	logger := logger.With().Int("parallel", parallel).Bool("query", query).Logger()
+	summary, err := run(ctx, client, parallel, warmupTime, executionTime, query, reconnect)
+	if err != nil {
+		return err
+	}
+	logResults(logger, summary)
+	return nil
+}
+
+// testSummary holds the results of a connect test
+type testSummary struct {
+	connect stats.OperationSummary
+	// network is only measured for drivers which tell the network connection apart from authentication
+	network stats.OperationSummary
+}
+
+func run(
+	ctx context.Context,
+	client dbinterface.Client,
+	parallel int,
+	warmupTime int,
+	executionTime int,
+	query bool,
+	reconnect testrunner.ReconnectPolicy,
+) (testSummary, error) {
+	if parallel <= 0 {
+		return testSummary{}, errors.New("parallel must be > 0")
+	}
+	if warmupTime <= 0 {
+		warmupTime = defaultWarmupTime
+	}
+	if executionTime <= 0 {
+		executionTime = defaultExecutionTime
+	}
+	connect, closeConnect, err := dbinterface.NewConnectFunc(client)
+	if err != nil {
+		return testSummary{}, err
+	}
+	defer func() { _ = closeConnect() }()
+
+	connectOp := stats.NewOperation(opConnect)
+	networkOp := stats.NewOperation(opNetwork)
+	var firstErr error
+	var errOnce sync.Once
+
+	warmupCtx, totalCtx, cancel := testrunner.WarmupAndTotalContexts(ctx, warmupTime, executionTime)
+	defer cancel()
+
+	var measurement testrunner.Measurement
+	logger.Info().Msgf("Starting %d workers.", parallel)
+	var wg sync.WaitGroup
+	for range parallel {
+		wg.Go(func() {
+			failures := 0
+			for totalCtx.Err() == nil {
+				measuring := measurement.Active()
+				timing, connectErr := connect(totalCtx, query)
+				if connectErr != nil {
+					if totalCtx.Err() != nil {
+						return
+					}
+					errOnce.Do(func() {
+						firstErr = connectErr
+						logger.Warn().Err(connectErr).Msg("Connecting failed, continuing to measure")
+					})
+					if measuring {
+						connectOp.Fail()
+					}
+					failures++
+					reconnect.Wait(totalCtx, failures)
+					continue
+				}
+				failures = 0
+				if !measuring {
+					continue
+				}
+				connectOp.Observe(timing.Total)
+				if timing.Network > 0 {
+					networkOp.Observe(timing.Network)
+				}
+			}
+		})
+	}
+
+	<-warmupCtx.Done()
+	logger.Info().Msg("Warmup finished. Starting measurements.")
+	measurement.Start()
+	wg.Wait()
+
+	elapsed := measurement.Elapsed(time.Duration(executionTime) * time.Second)
+	summary := testSummary{connect: connectOp.Summarize(elapsed), network: networkOp.Summarize(elapsed)}
+	if summary.connect.Count == 0 && firstErr != nil {
+		return summary, fmt.Errorf("no connection could be set up: %w", firstErr)
+	}
+	return summary, nil
+}
+
+func logResults(logger zerolog.Logger, summary testSummary) {
+	if summary.network.Count > 0 {
+		logger.Info().
+			Int64("connections", summary.network.Count).
+			Float64("mean_ms", summary.network.Latency.MeanMs).
+			Float64("p50_ms", summary.network.Latency.P50Ms).
+			Float64("p95_ms", summary.network.Latency.P95Ms).
+			Float64("p99_ms", summary.network.Latency.P99Ms).
+			Float64("max_ms", summary.network.Latency.MaxMs).
+			Msg("Results for the network connection (including TLS, excluding authentication)")
+	}
+	logger.Info().
+		Int64("connections", summary.connect.Count).
+		Int64("errors", summary.connect.Errors).
+		Float64("per_sec", summary.connect.PerSecond).
+		Float64("mean_ms", summary.connect.Latency.MeanMs).
+		Float64("p50_ms", summary.connect.Latency.P50Ms).
+		Float64("p95_ms", summary.connect.Latency.P95Ms).
+		Float64("p99_ms", summary.connect.Latency.P99Ms).
+		Float64("max_ms", summary.connect.Latency.MaxMs).
+		Msg("Connect performance test finished")
+}
@@ -0,0 +1,61 @@
+package connperformance
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface/fake"
+	"github.com/pgvillage-tools/dbtwool/pkg/testrunner"
+)
+
+// pooledOnly is a client without a DirectConnector
+type pooledOnly struct {
+	dbinterface.Client
+}
+
+var _ = Describe("ExecuteTest", func() {
+	ctx := context.Background()
+	It("should open and close a new connection for every measurement", func() {
+		client := fake.NewClient()
+		client.Script.On(fake.StmtConnect).Delay(time.Millisecond)
+		summary, err := run(ctx, client, 2, 1, 1, true, testrunner.ReconnectPolicy{})
+		Ω(err).NotTo(HaveOccurred())
+		Ω(summary.connect.Count).To(BeNumerically(">", 10))
+		Ω(summary.connect.PerSecond).To(BeNumerically(">", 0))
+		Ω(summary.connect.Latency.P50Ms).To(BeNumerically(">=", 1))
+		pool, err := client.Pool(ctx)
+		Ω(err).NotTo(HaveOccurred())
+		Ω(pool.(*fake.Pool).Open()).To(BeZero())
+		Ω(client.Script.Calls()).To(ContainElement(HaveField("SQL", "SELECT 1")))
+	})
+	It("should count failed connections and keep measuring", func() {
+		client := fake.NewClient()
+		client.Script.On(fake.StmtConnect).After(5).Times(5).Fail(errors.New("too many clients"))
+		summary, err := run(ctx, client, 1, 1, 1, false, testrunner.ReconnectPolicy{})
+		Ω(err).NotTo(HaveOccurred())
+		Ω(summary.connect.Count).To(BeNumerically(">", 0))
+	})
+	It("should fail when no connection could be set up", func() {
+		client := fake.NewClient()
+		client.Script.On(fake.StmtConnect).Fail(errors.New("no server"))
+		_, err := run(ctx, client, 1, 1, 1, false, testrunner.ReconnectPolicy{})
+		Ω(err).To(MatchError(ContainSubstring("no server")))
+	})
+	It("should back off after a failed connect", func() {
+		client := fake.NewClient()
+		client.Script.On(fake.StmtConnect).Fail(errors.New("too many clients"))
+		_, err := run(ctx, client, 1, 1, 1, false, testrunner.ReconnectPolicy{Backoff: 500 * time.Millisecond})
+		Ω(err).To(HaveOccurred())
+		// 2 seconds with a backoff of 500 ms leaves room for 4 attempts, where it would be thousands without it
+		Ω(client.Script.Count(fake.StmtConnect)).To(BeNumerically("<=", 5))
+	})
+	It("should fail for clients which cannot connect outside of their pool", func() {
+		_, err := run(ctx, pooledOnly{}, 1, 1, 1, false, testrunner.ReconnectPolicy{})
+		Ω(err).To(MatchError(dbinterface.ErrDirectConnectUnsupported))
+	})
+})
@@ -4,6 +4,8 @@ package db2client
 import (
 	"context"
 	"database/sql"
+	"math"
+	"strconv"
 
 	// importing db drivers so that database/sql can use it
 	_ "github.com/ibmdb/go_ibm_db"
@@ -15,6 +17,7 @@ const db2TestQuery = "SELECT 1 FROM SYSIBM.SYSDUMMY1"
 // Client is the main object to connect to DB2
 type Client struct {
 	ConnectParams ConnParams
+	PoolSettings  dbinterface.PoolSettings
 	pool          *Pool
 }
 
@@ -31,14 +34,57 @@ func (cl *Client) Pool(_ context.Context) (dbinterface.Pool, error) {
 		return cl.pool, nil
 	}
 
-	pool, err := sql.Open("go_ibm_db", cl.ConnectParams.GetConnString())
+	logger.Debug().Msgf("Connecting to %s", cl.ConnectParams)
+	pool, err := sql.Open("go_ibm_db", cl.connString())
 	if err != nil {
 		return nil, err
-	} else if err = pool.Ping(); err != nil {
+	}
+	cl.PoolSettings.ConfigureDB(pool)
+	if err = pool.Ping(); err != nil {
+		_ = pool.Close()
 		return nil, err
-	} else if _, err := pool.Query(db2TestQuery); err != nil {
+	}
+	rows, err := pool.Query(db2TestQuery)
+	if err != nil {
+		_ = pool.Close()
 		return nil, err
 	}
+	// an open result set would keep its connection, and with maxConns set, a worker would wait for it forever
+	_ = rows.Close()
+	if params, ok := cl.ConnectParams.(DB2ConnParams); ok {
+		logger.Info().Msgf("Connection is %s", params.TLSDescription())
+	}
 	cl.pool = &Pool{pool: pool}
 	return cl.pool, nil
 }
+
+// EnsurePoolSize implements dbinterface.PoolSizer. database/sql pools are unlimited unless maxConns is set.
+func (cl *Client) EnsurePoolSize(conns int) error {
+	return cl.PoolSettings.CheckSize(conns)
+}
+
+// ConnectFunc implements dbinterface.DirectConnector. It uses a database/sql pool of its own, which keeps no idle
+// connections, so that every call opens a new connection.
+func (cl *Client) ConnectFunc() (dbinterface.ConnectFunc, func() error, error) {
+	db, err := sql.Open("go_ibm_db", cl.connString())
+	if err != nil {
+		return nil, nil, err
+	}
+	connect, closeDB := dbinterface.DBConnectFunc(db, db2TestQuery)
+	return connect, closeDB, nil
+}
+
+// connString returns the connection string, with the connect timeout of the pool settings as CONNECTTIMEOUT
+// (unless it is set in the connection parameters already)
+func (cl *Client) connString() string {
+	params, ok := cl.ConnectParams.(DB2ConnParams)
+	if !ok || cl.PoolSettings.ConnectTimeout <= 0 || params["CONNECTTIMEOUT"] != "" {
+		return cl.ConnectParams.GetConnString()
+	}
+	withTimeout := DB2ConnParams{}
+	for key, value := range params {
+		withTimeout[key] = value
+	}
+	withTimeout["CONNECTTIMEOUT"] = strconv.Itoa(int(math.Ceil(cl.PoolSettings.ConnectTimeout.Seconds())))
+	return withTimeout.GetConnString()
+}
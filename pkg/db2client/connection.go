@@ -2,170 +2,37 @@ package db2client
 
 import (
 	"context"
-	"database/sql"
 	"errors"
 	"fmt"
-	"strings"
+	"math"
+	"time"
 
-	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+	"github.com/pgvillage-tools/dbtwool/pkg/sqlconn"
 )
 
-// Connection is a wrapper over sql.Conn, so that we can add methods
+// Connection is a database/sql connection (see package sqlconn) with the DB2 specific methods
 type Connection struct {
-	conn *sql.Conn
-	tx   *sql.Tx
+	*sqlconn.Connection
 }
 
-// Close closes the connection
-func (c *Connection) Close(_ context.Context) error {
-	return c.conn.Close()
-}
-
-// SetIsolationLevel can be used to change the isolation level on a connection
-func (c *Connection) SetIsolationLevel(ctx context.Context, isoLevel dbinterface.IsolationLevel) error {
-	qryIsoLevel := isoLevel.AsQuery()
-	logger.Info().Msgf("Set Isolation Level: %s", qryIsoLevel)
-	_, err := c.Execute(ctx, qryIsoLevel)
-	return err
-}
-
-// Execute will execute a query and return number of affected rows
-func (c *Connection) Execute(ctx context.Context, query string) (int64, error) {
-	var (
-		r   sql.Result
-		err error
-	)
-
-	if c.tx != nil {
-		r, err = c.tx.ExecContext(ctx, query)
-	} else {
-		r, err = c.conn.ExecContext(ctx, query)
-	}
-	if err != nil {
-		return 0, err
-	}
-	return r.RowsAffected()
-}
-
-// Query will execute a query and return a list of maps where every list item is a row and every map item is a column
-func (c *Connection) Query(ctx context.Context, query string, args ...any) ([]map[string]any, error) {
-	var (
-		rows *sql.Rows
-		err  error
-	)
-
-	if c.tx != nil {
-		rows, err = c.tx.QueryContext(ctx, query, args...)
-	} else {
-		rows, err = c.conn.QueryContext(ctx, query, args...)
-	}
-	if err != nil {
-		return nil, err
-	}
-	return rowsToMaps(rows)
-}
-
-// QueryOneRow executes a query and expects one row, or fails. On success it returns the row.
-func (c *Connection) QueryOneRow(ctx context.Context, query string, args ...any) (map[string]any, error) {
-	rows, queryErr := c.Query(ctx, query, args...)
-	if queryErr != nil {
-		return nil, fmt.Errorf("error while executing olap query %v", queryErr)
-	}
-	if len(rows) != 1 {
-		return nil, fmt.Errorf("expected 1 row on olap query: %v", queryErr)
-	}
-	return rows[0], nil
-}
-
-// Begin starts a transaction. In this case there is a one-on-one relation between the transaction and the connection
-func (c *Connection) Begin(ctx context.Context) error {
-	if c.tx != nil {
-		return errors.New("transaction already active")
-	}
-	tx, err := c.conn.BeginTx(ctx, nil)
-	if err != nil {
-		return err
-	}
-	c.tx = tx
-	return nil
-}
-
-// Commit will commit the connection
-func (c *Connection) Commit(_ context.Context) error {
-	if c.tx == nil {
-		return errors.New("no active transaction")
-	}
-	err := c.tx.Commit()
-	c.tx = nil
-	return err
-}
-
-// Rollback will rollback the transaction
-func (c *Connection) Rollback(_ context.Context) error {
-	if c.tx == nil {
-		return errors.New("no active transaction")
+// SetLockTimeout sets how long a statement waits for a lock before it fails with SQL0911N (reason code 68).
+// DB2 only supports whole seconds, so the timeout is rounded up. 0 means wait forever.
+func (c *Connection) SetLockTimeout(ctx context.Context, timeout time.Duration) error {
+	seconds := int64(-1)
+	if timeout > 0 {
+		seconds = int64(math.Ceil(timeout.Seconds()))
 	}
-	err := c.tx.Rollback()
-	c.tx = nil
+	qry := fmt.Sprintf("SET CURRENT LOCK TIMEOUT %d", seconds)
+	logger.Info().Msgf("Set timeout: %s", qry)
+	_, err := c.Execute(ctx, qry)
 	return err
 }
 
-// ExecuteWithPayload executes a query with adding a payload
-func (c *Connection) ExecuteWithPayload(ctx context.Context, qry string, payload any, args ...any) (int64, error) {
-	if c.tx == nil {
-		return 0, errors.New("ExecuteWithPayload requires an active transaction; call Begin() first")
-	}
-
-	allArgs := make([]any, 0, len(args)+1)
-	allArgs = append(allArgs, args...)
-	allArgs = append(allArgs, payload)
-
-	res, err := c.tx.ExecContext(ctx, qry, allArgs...)
-	if err != nil {
-		return 0, err
-	}
-	return res.RowsAffected()
-}
-
-func rowsToMaps(rows *sql.Rows) ([]map[string]any, error) {
-	defer rows.Close()
-
-	cols, err := rows.Columns()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get columns: %w", err)
+// SetStatementTimeout fails for any timeout but 0. go_ibm_db does not expose the CLI query timeout, and only checks
+// the context after a statement returned, so a statement which waits for a lock could not be interrupted.
+func (c *Connection) SetStatementTimeout(_ context.Context, timeout time.Duration) error {
+	if timeout > 0 {
+		return errors.New("statement timeouts are not supported on DB2, use a lock timeout instead")
 	}
-
-	var results []map[string]any
-
-	for rows.Next() {
-		values := make([]any, len(cols))
-		scanArgs := make([]any, len(cols))
-
-		for i := range values {
-			scanArgs[i] = &values[i]
-		}
-
-		if err := rows.Scan(scanArgs...); err != nil {
-			return nil, fmt.Errorf("scan failed: %w", err)
-		}
-
-		rowMap := make(map[string]any, len(cols))
-		for i, colName := range cols {
-			key := strings.ToLower(colName) // Makes uppercase column names not break everything
-			val := values[i]
-
-			if b, ok := val.([]byte); ok {
-				rowMap[key] = string(b)
-			} else {
-				rowMap[key] = val
-			}
-		}
-		results = append(results, rowMap)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("row iteration failed: %w", err)
-	}
-
-	return results, nil
+	return nil
 }
@@ -0,0 +1,17 @@
+package db2client
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Connection", func() {
+	It("should reject a statement timeout, which DB2 can not enforce", func() {
+		conn := &Connection{}
+		Ω(conn.SetStatementTimeout(context.Background(), time.Second)).To(HaveOccurred())
+		Ω(conn.SetStatementTimeout(context.Background(), 0)).To(Succeed())
+	})
+})
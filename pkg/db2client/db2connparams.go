@@ -3,9 +3,10 @@ package db2client
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 
-	"github.com/pgvillage-tools/dbtwool/pkg/utils"
+	"github.com/pgvillage-tools/dbtwool/pkg/secrets"
 )
 
 // DB2ConnParams objects define connection parameters for a DB2 connection
@@ -13,21 +14,98 @@ type DB2ConnParams map[string]string
 
 // GetConnString builds and returns a string that can be used to connect to DB2
 func (cp DB2ConnParams) GetConnString() string {
+	return cp.connString(false)
+}
+
+// Redacted returns the connection string with the password masked, so that it can be logged
+func (cp DB2ConnParams) Redacted() string {
+	return cp.connString(true)
+}
+
+// String implements fmt.Stringer and returns the redacted connection string, so that printing DB2ConnParams never
+// reveals the password
+func (cp DB2ConnParams) String() string {
+	return cp.Redacted()
+}
+
+func (cp DB2ConnParams) connString(redact bool) string {
+	var keys []string
+	for key := range cp {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
 	var l []string
-	for key, value := range cp {
-		l = append(l, fmt.Sprintf("%s=%s", key, value))
+	for _, key := range keys {
+		value := cp[key]
+		if redact && isSecretKeyword(key) && value != "" {
+			value = secrets.Mask
+		}
+		l = append(l, fmt.Sprintf("%s=%s", key, quoteConnValue(value)))
 	}
 	return strings.Join(l, ";")
 }
 
 // NewDB2ConnparamsFromEnv generates a new default ConnParams from env variables with defaults
 func NewDB2ConnparamsFromEnv() ConnParams {
-	return DB2ConnParams{
-		"HOSTNAME": utils.GetEnv("DB2_HOST", "db2"),
-		"PORT":     utils.GetEnv("DB2_PORT", "50000"),
-		"DATABASE": utils.GetEnv("DB2_DATABASE", "sample"),
-		"UID":      utils.GetEnv("DB2_USER", "db2inst1"),
-		"PWD":      os.Getenv("DB2_PASSWORD"),
-		"PROTOCOL": utils.GetEnv("DB2_PROTOCOL", "TCPIP"),
+	return NewDB2ConnparamsFromEnvWithDefaults(DB2ConnParams{})
+}
+
+// NewDB2ConnparamsFromEnvWithDefaults generates a new DB2ConnParams from env variables. Keywords which are not set
+// in the environment are taken from defaults (e.a. a connection profile), and from the built-in defaults when
+// missing there. Keywords in defaults without an env variable (like AUTHENTICATION) are copied as is.
+func NewDB2ConnparamsFromEnvWithDefaults(defaults DB2ConnParams) DB2ConnParams {
+	params := DB2ConnParams{
+		"HOSTNAME": "db2",
+		"PORT":     "50000",
+		"DATABASE": "sample",
+		"UID":      "db2inst1",
+		"PWD":      "",
+		"PROTOCOL": "TCPIP",
+	}
+	for key, value := range defaults {
+		if value != "" {
+			params[strings.ToUpper(key)] = value
+		}
+	}
+	for key, envVar := range map[string]string{
+		"HOSTNAME":                    "DB2_HOST",
+		"PORT":                        "DB2_PORT",
+		"DATABASE":                    "DB2_DATABASE",
+		"UID":                         "DB2_USER",
+		"PWD":                         "DB2_PASSWORD",
+		"PROTOCOL":                    "DB2_PROTOCOL",
+		"SECURITY":                    "DB2_SECURITY",
+		"SSLSERVERCERTIFICATE":        "DB2_SSL_SERVER_CERTIFICATE",
+		"SSLCLIENTKEYSTOREDB":         "DB2_SSL_CLIENT_KEYSTOREDB",
+		"SSLCLIENTKEYSTASH":           "DB2_SSL_CLIENT_KEYSTASH",
+		"SSLCLIENTKEYSTOREDBPASSWORD": "DB2_SSL_CLIENT_KEYSTOREDB_PASSWORD",
+		"SSLCLIENTLABEL":              "DB2_SSL_CLIENT_LABEL",
+		"ALTHOSTNAME":                 "DB2_ALT_HOST",
+		"ALTPORT":                     "DB2_ALT_PORT",
+	} {
+		if value := os.Getenv(envVar); value != "" {
+			params[key] = value
+		}
+	}
+	return params
+}
+
+// isSecretKeyword returns true for the keywords which hold a password
+func isSecretKeyword(key string) bool {
+	return strings.EqualFold(key, "PWD") || strings.EqualFold(key, "SSLCLIENTKEYSTOREDBPASSWORD")
+}
+
+// TLSDescription describes the TLS settings of the connection for logging. The DB2 CLI driver does not expose the
+// negotiated TLS version and cipher, so the configured settings are described instead.
+func (cp DB2ConnParams) TLSDescription() string {
+	if !strings.EqualFold(cp["SECURITY"], "SSL") {
+		return "not encrypted"
+	}
+	description := "encrypted (SECURITY=SSL"
+	for _, key := range []string{"SSLSERVERCERTIFICATE", "SSLCLIENTKEYSTOREDB", "SSLCLIENTLABEL"} {
+		if value := cp[key]; value != "" {
+			description += fmt.Sprintf(", %s=%s", key, value)
+		}
 	}
+	return description + ")"
 }
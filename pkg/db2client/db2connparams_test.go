@@ -0,0 +1,68 @@
+package db2client
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DB2ConnParams", func() {
+	BeforeEach(func() {
+		for _, envVar := range []string{"DB2_HOST", "DB2_PORT", "DB2_DATABASE", "DB2_USER", "DB2_PASSWORD",
+			"DB2_PROTOCOL", "DB2_SECURITY", "DB2_SSL_SERVER_CERTIFICATE", "DB2_SSL_CLIENT_KEYSTOREDB",
+			"DB2_SSL_CLIENT_KEYSTASH", "DB2_SSL_CLIENT_KEYSTOREDB_PASSWORD", "DB2_SSL_CLIENT_LABEL",
+			"DB2_ALT_HOST", "DB2_ALT_PORT"} {
+			GinkgoT().Setenv(envVar, "")
+		}
+	})
+
+	Context("NewDB2ConnparamsFromEnvWithDefaults", func() {
+		It("should fall back to the built-in defaults", func() {
+			params := NewDB2ConnparamsFromEnvWithDefaults(DB2ConnParams{})
+			Ω(params).To(HaveKeyWithValue("HOSTNAME", "db2"))
+			Ω(params).To(HaveKeyWithValue("PORT", "50000"))
+			Ω(params).To(HaveKeyWithValue("PROTOCOL", "TCPIP"))
+			Ω(params).NotTo(HaveKey("SECURITY"))
+		})
+		It("should prefer the defaults over the built-in defaults", func() {
+			params := NewDB2ConnparamsFromEnvWithDefaults(DB2ConnParams{
+				"HOSTNAME":       "profilehost",
+				"security":       "SSL",
+				"AUTHENTICATION": "SERVER_ENCRYPT",
+			})
+			Ω(params).To(HaveKeyWithValue("HOSTNAME", "profilehost"))
+			Ω(params).To(HaveKeyWithValue("SECURITY", "SSL"))
+			Ω(params).To(HaveKeyWithValue("AUTHENTICATION", "SERVER_ENCRYPT"))
+		})
+		It("should prefer the environment over the defaults", func() {
+			GinkgoT().Setenv("DB2_HOST", "envhost")
+			GinkgoT().Setenv("DB2_PASSWORD", "envpassword")
+			params := NewDB2ConnparamsFromEnvWithDefaults(DB2ConnParams{"HOSTNAME": "profilehost", "PWD": "profile"})
+			Ω(params).To(HaveKeyWithValue("HOSTNAME", "envhost"))
+			Ω(params).To(HaveKeyWithValue("PWD", "envpassword"))
+		})
+		It("should read the tls settings from the environment", func() {
+			GinkgoT().Setenv("DB2_SECURITY", "SSL")
+			GinkgoT().Setenv("DB2_SSL_SERVER_CERTIFICATE", "/certs/ca.arm")
+			GinkgoT().Setenv("DB2_SSL_CLIENT_KEYSTOREDB_PASSWORD", "keystoresecret")
+			params := NewDB2ConnparamsFromEnvWithDefaults(DB2ConnParams{})
+			Ω(params).To(HaveKeyWithValue("SECURITY", "SSL"))
+			Ω(params).To(HaveKeyWithValue("SSLSERVERCERTIFICATE", "/certs/ca.arm"))
+			Ω(params.Redacted()).NotTo(ContainSubstring("keystoresecret"))
+		})
+		It("should read the alternate server for client reroute from the environment", func() {
+			GinkgoT().Setenv("DB2_ALT_HOST", "standby")
+			GinkgoT().Setenv("DB2_ALT_PORT", "50001")
+			params := NewDB2ConnparamsFromEnvWithDefaults(DB2ConnParams{})
+			Ω(params).To(HaveKeyWithValue("ALTHOSTNAME", "standby"))
+			Ω(params).To(HaveKeyWithValue("ALTPORT", "50001"))
+		})
+	})
+
+	Context("TLSDescription", func() {
+		It("should describe the configured tls settings", func() {
+			Ω(DB2ConnParams{"HOSTNAME": "db2"}.TLSDescription()).To(Equal("not encrypted"))
+			Ω(DB2ConnParams{"SECURITY": "SSL", "SSLSERVERCERTIFICATE": "/certs/ca.arm"}.TLSDescription()).To(
+				Equal("encrypted (SECURITY=SSL, SSLSERVERCERTIFICATE=/certs/ca.arm)"))
+		})
+	})
+})
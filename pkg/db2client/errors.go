@@ -0,0 +1,76 @@
+package db2client
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"regexp"
+	"strings"
+
+	ibmdb "github.com/ibmdb/go_ibm_db"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+)
+
+const (
+	// sqlDeadlockOrTimeout (SQL0911N) means the transaction was rolled back because of a deadlock or lock timeout
+	sqlDeadlockOrTimeout = -911
+	// sqlDeadlockOrTimeoutNoRollback (SQL0913N) is like SQL0911N, but only the statement failed
+	sqlDeadlockOrTimeoutNoRollback = -913
+	// sqlCommunicationError (SQL30081N) means the connection to the server was lost
+	sqlCommunicationError = -30081
+	// sqlConnectionTerminated (SQL1224N) means the server terminated the connection
+	sqlConnectionTerminated = -1224
+
+	reasonDeadlock    = "2"
+	reasonLockTimeout = "68"
+)
+
+var reasonCodeRe = regexp.MustCompile(`(?i)reason code "?(\d+)"?`)
+
+// ClassifyError returns the class of a DB2 error, based on its SQLCODE, reason code and SQLSTATE
+func ClassifyError(err error) dbinterface.ErrorClass {
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) {
+		return dbinterface.ErrorClassConnectionLost
+	}
+	var dbErr *ibmdb.Error
+	if errors.As(err, &dbErr) {
+		for _, diag := range dbErr.Diag {
+			if class := classifyDiag(diag.NativeError, diag.State, diag.Message); class != dbinterface.ErrorClassOther {
+				return class
+			}
+		}
+	}
+	return dbinterface.ErrorClassOther
+}
+
+func classifyDiag(nativeError int, state string, message string) dbinterface.ErrorClass {
+	switch nativeError {
+	case sqlDeadlockOrTimeout, sqlDeadlockOrTimeoutNoRollback:
+		switch reasonCode(message) {
+		case reasonDeadlock:
+			return dbinterface.ErrorClassDeadlock
+		case reasonLockTimeout:
+			return dbinterface.ErrorClassLockTimeout
+		}
+		return dbinterface.ErrorClassOther
+	case sqlCommunicationError, sqlConnectionTerminated:
+		return dbinterface.ErrorClassConnectionLost
+	}
+	if strings.HasPrefix(state, "08") {
+		return dbinterface.ErrorClassConnectionLost
+	}
+	return dbinterface.ErrorClassOther
+}
+
+func reasonCode(message string) string {
+	if match := reasonCodeRe.FindStringSubmatch(message); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// ClassifyError implements dbinterface.ErrorClassifier
+func (c *Connection) ClassifyError(err error) dbinterface.ErrorClass {
+	return ClassifyError(err)
+}
@@ -0,0 +1,35 @@
+package db2client
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+)
+
+var _ = Describe("ClassifyError", func() {
+	DescribeTable("classifyDiag should classify by SQLCODE, reason code and SQLSTATE",
+		func(nativeError int, state string, message string, expected dbinterface.ErrorClass) {
+			Ω(classifyDiag(nativeError, state, message)).To(Equal(expected))
+		},
+		Entry("SQL0911N deadlock", -911, "40001",
+			`SQL0911N  The current transaction has been rolled back because of a deadlock or timeout.  `+
+				`Reason code "2".  SQLSTATE=40001`, dbinterface.ErrorClassDeadlock),
+		Entry("SQL0911N lock timeout", -911, "40001", `Reason code "68".`, dbinterface.ErrorClassLockTimeout),
+		Entry("SQL0913N deadlock", -913, "57033", `Reason code "2".`, dbinterface.ErrorClassDeadlock),
+		Entry("SQL0911N unknown reason", -911, "40001", `Reason code "72".`, dbinterface.ErrorClassOther),
+		Entry("SQL30081N", -30081, "08001", "SQL30081N  A communication error", dbinterface.ErrorClassConnectionLost),
+		Entry("connection SQLSTATE", -99999, "08003", "", dbinterface.ErrorClassConnectionLost),
+		Entry("SQL0803N", -803, "23505", "duplicate", dbinterface.ErrorClassOther),
+	)
+	It("should classify a bad connection as connection lost", func() {
+		Ω(ClassifyError(fmt.Errorf("query: %w", driver.ErrBadConn))).To(Equal(dbinterface.ErrorClassConnectionLost))
+	})
+	It("should classify other errors as other", func() {
+		Ω(ClassifyError(errors.New("boom"))).To(Equal(dbinterface.ErrorClassOther))
+	})
+})
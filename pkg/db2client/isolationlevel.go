@@ -1,6 +1,10 @@
 package db2client
 
-import "strings"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 // IsolationLevel is used to get rdbms specific queries for basic functions
 type IsolationLevel int
@@ -23,6 +27,21 @@ var (
 		ReadStability:   "RS",
 		RepeatableRead:  "RR",
 	}
+	// nameToLevel maps DB2 and ANSI (PostgreSQL) names to the DB2 level which offers the same guarantees.
+	// Note that ANSI REPEATABLE READ matches DB2 RS, and ANSI SERIALIZABLE matches DB2 RR.
+	nameToLevel = map[string]IsolationLevel{
+		"UR":               UncommittedRead,
+		"UNCOMMITTED READ": UncommittedRead,
+		"CS":               CursorStability,
+		"CURSOR STABILITY": CursorStability,
+		"RS":               ReadStability,
+		"READ STABILITY":   ReadStability,
+		"RR":               RepeatableRead,
+		"READ UNCOMMITTED": UncommittedRead,
+		"READ COMMITTED":   CursorStability,
+		"REPEATABLE READ":  ReadStability,
+		"SERIALIZABLE":     RepeatableRead,
+	}
 )
 
 // AsQuery can be used to return a query for the isolation level
@@ -44,3 +63,17 @@ func (i IsolationLevel) AsString() string {
 func GetIsolationLevel(i int) IsolationLevel {
 	return UncommittedRead + IsolationLevel(i)
 }
+
+// ParseIsolationLevel returns the isolation level for a name (e.g. "UR", "read committed").
+// Invalid values return an error.
+func ParseIsolationLevel(value string) (IsolationLevel, error) {
+	// an index means a different level on every RDBMS (e.g. 1 is REPEATABLE READ on PostgreSQL and CS on DB2)
+	if _, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+		return CursorStability, fmt.Errorf("isolation level %q is an index, use a name like 'read committed' instead", value)
+	}
+	name := strings.Join(strings.Fields(strings.ToUpper(strings.NewReplacer("_", " ", "-", " ").Replace(value))), " ")
+	if level, exists := nameToLevel[name]; exists {
+		return level, nil
+	}
+	return CursorStability, fmt.Errorf("invalid isolation level %q", value)
+}
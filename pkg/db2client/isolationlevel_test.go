@@ -0,0 +1,45 @@
+package db2client
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("IsolationLevel", func() {
+	Context("AsQuery", func() {
+		It("should return the correct query", func() {
+			Ω(UncommittedRead.AsQuery()).To(Equal("SET CURRENT ISOLATION UR"))
+			Ω(RepeatableRead.AsQuery()).To(Equal("SET CURRENT ISOLATION RR"))
+		})
+	})
+
+	DescribeTable("ParseIsolationLevel should map valid values",
+		func(value string, expected IsolationLevel) {
+			level, err := ParseIsolationLevel(value)
+			Ω(err).NotTo(HaveOccurred())
+			Ω(level).To(Equal(expected))
+		},
+		Entry("UR", "UR", UncommittedRead),
+		Entry("ur", "ur", UncommittedRead),
+		Entry("cursor stability", "cursor stability", CursorStability),
+		Entry("RS", "RS", ReadStability),
+		Entry("RR", "RR", RepeatableRead),
+		Entry("read uncommitted", "read uncommitted", UncommittedRead),
+		Entry("read committed", "read_committed", CursorStability),
+		Entry("repeatable read", "repeatable read", ReadStability),
+		Entry("serializable", "SERIALIZABLE", RepeatableRead),
+	)
+
+	DescribeTable("ParseIsolationLevel should reject invalid values",
+		func(value string) {
+			_, err := ParseIsolationLevel(value)
+			Ω(err).To(HaveOccurred())
+		},
+		Entry("empty", ""),
+		Entry("unknown name", "snapshot"),
+		Entry("index 0", "0"),
+		Entry("index 1", "1"),
+		Entry("index too high", "4"),
+		Entry("negative index", "-1"),
+	)
+})
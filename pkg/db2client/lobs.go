@@ -187,7 +187,7 @@ func (c *Connection) runDB2Load(ctx context.Context, b *lobLoadBatch) error {
 		b.payloadCol,
 	)
 
-	_, err := c.conn.ExecContext(ctx, "CALL SYSPROC.ADMIN_CMD(?)", loadCmd)
+	_, err := c.Conn().ExecContext(ctx, "CALL SYSPROC.ADMIN_CMD(?)", loadCmd)
 	if err != nil {
 		return fmt.Errorf("ADMIN_CMD LOAD failed: %w", err)
 	}
@@ -1,5 +1,5 @@
 package db2client
 
-import "github.com/rs/zerolog/log"
+import "github.com/pgvillage-tools/dbtwool/pkg/logging"
 
-var logger = log.With().Logger()
+var logger = logging.Logger()
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 
 	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+	"github.com/pgvillage-tools/dbtwool/pkg/sqlconn"
 )
 
 // Pool is a wrapper around sql.DB, so we can add methods on top of it
@@ -18,5 +19,5 @@ func (p Pool) Connect(ctx context.Context) (dbinterface.Connection, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &Connection{conn: conn}, nil
+	return &Connection{Connection: sqlconn.New(conn)}, nil
 }
@@ -32,10 +32,10 @@ func (s *db2PreparedStmt) Close(_ context.Context) error {
 
 // PrepareInTx is used to prepare a statement on the current transaction in the current connection.
 func (c *Connection) PrepareInTx(ctx context.Context, sqlText string) (dbinterface.PreparedStatement, error) {
-	if c.tx == nil {
+	if c.Tx() == nil {
 		return nil, errors.New("PrepareInTx requires active transaction")
 	}
-	st, err := c.tx.PrepareContext(ctx, sqlText)
+	st, err := c.Tx().PrepareContext(ctx, sqlText)
 	if err != nil {
 		return nil, err
 	}
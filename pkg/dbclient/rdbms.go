@@ -13,12 +13,15 @@ const (
 	DB2 RDBMS = "db2"
 	// Postgres means PostgreSQL
 	Postgres RDBMS = "pg"
+	// SQLite means an embedded SQLite database
+	SQLite RDBMS = "sqlite"
 )
 
 // Drivers defines all available driver names to rdbms names
 var Drivers = map[RDBMS]string{
 	DB2:      "go_ibm_db",
 	Postgres: "pgx",
+	SQLite:   "sqlite",
 }
 
 // DriverName is a helper to get the driver name safely
@@ -32,13 +35,12 @@ func GetRDBMSFromString(rdbmsText string) RDBMS {
 	var rdbms RDBMS
 
 	switch strings.ToLower(rdbmsText) {
-	case "postgresql":
-	case "postgres":
-	case "pg":
+	case "postgresql", "postgres", "pg":
 		rdbms = Postgres
-	case "ibmdb":
-	case "db2":
+	case "ibmdb", "db2":
 		rdbms = DB2
+	case "sqlite", "sqlite3":
+		rdbms = SQLite
 	default:
 		rdbms = Postgres
 	}
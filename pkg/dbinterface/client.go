@@ -2,7 +2,10 @@
 // functions that should work on all types of rdbms'es
 package dbinterface
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Client represents any database client (either DB2, PostgreSQL or in the future something else)
 type Client interface {
@@ -19,9 +22,12 @@ type Connection interface {
 	Close(context.Context) error
 	Begin(context.Context) error
 	Commit(context.Context) error
-	Execute(context.Context, string) (int64, error)
+	Execute(context.Context, string, ...any) (int64, error)
 	ExecuteWithPayload(context.Context, string, any, ...any) (int64, error) // ctx, query, payload, arguments
 	SetIsolationLevel(context.Context, IsolationLevel) error
+	SetLockTimeout(context.Context, time.Duration) error
+	SetStatementTimeout(context.Context, time.Duration) error
+	Query(context.Context, string, ...any) ([]map[string]any, error)
 	QueryOneRow(context.Context, string, ...any) (map[string]any, error)
 	Rollback(context.Context) error
 }
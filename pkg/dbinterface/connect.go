@@ -0,0 +1,64 @@
+package dbinterface
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// ErrDirectConnectUnsupported is returned for clients which cannot open connections outside of their pool
+var ErrDirectConnectUnsupported = errors.New("client cannot open connections outside of its pool")
+
+// ConnectTiming holds how long it took to set up one physical connection
+type ConnectTiming struct {
+	// Network is the time to set up the network connection, including the TLS handshake. It is 0 when the driver
+	// does not tell it apart from authentication (database/sql drivers).
+	Network time.Duration
+	// Total is the time until the connection was ready for use, including authentication, and including the test
+	// query when it was requested
+	Total time.Duration
+}
+
+// ConnectFunc opens a new physical connection, runs a test query on it when query is true, and closes it again.
+// Closing the connection is not part of the timing. A ConnectFunc is safe for concurrent use.
+type ConnectFunc func(ctx context.Context, query bool) (ConnectTiming, error)
+
+// DirectConnector is implemented by clients which can open physical connections, bypassing their pool.
+// ConnectFunc also returns a function which releases what the ConnectFunc holds on to, once it is no longer used.
+type DirectConnector interface {
+	ConnectFunc() (ConnectFunc, func() error, error)
+}
+
+// NewConnectFunc returns the ConnectFunc of client and the function which releases it, or an error wrapping
+// ErrDirectConnectUnsupported
+func NewConnectFunc(client Client) (ConnectFunc, func() error, error) {
+	connector, ok := client.(DirectConnector)
+	if !ok {
+		return nil, nil, ErrDirectConnectUnsupported
+	}
+	return connector.ConnectFunc()
+}
+
+// DBConnectFunc returns a ConnectFunc for a database/sql pool, which should be dedicated to it, and a function which
+// closes the pool. The pool keeps no idle connections, so that every call opens a new physical connection and
+// closing it really disconnects.
+func DBConnectFunc(db *sql.DB, testQuery string) (ConnectFunc, func() error) {
+	db.SetMaxIdleConns(0)
+	return func(ctx context.Context, query bool) (ConnectTiming, error) {
+		start := time.Now()
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			return ConnectTiming{}, err
+		}
+		defer func() { _ = conn.Close() }()
+		if query {
+			rows, queryErr := conn.QueryContext(ctx, testQuery)
+			if queryErr != nil {
+				return ConnectTiming{}, queryErr
+			}
+			_ = rows.Close()
+		}
+		return ConnectTiming{Total: time.Since(start)}, nil
+	}, db.Close
+}
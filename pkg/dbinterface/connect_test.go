@@ -0,0 +1,28 @@
+package dbinterface
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	_ "modernc.org/sqlite"
+)
+
+var _ = Describe("DBConnectFunc", func() {
+	It("should connect until the pool is closed", func() {
+		db, err := sql.Open("sqlite", filepath.Join(GinkgoT().TempDir(), "test.sqlite"))
+		Expect(err).NotTo(HaveOccurred())
+		connect, closeDB := DBConnectFunc(db, "SELECT 1")
+
+		timing, err := connect(context.Background(), true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(timing.Total).To(BeNumerically(">", 0))
+		Expect(db.Stats().OpenConnections).To(BeZero())
+
+		Expect(closeDB()).To(Succeed())
+		_, err = connect(context.Background(), false)
+		Expect(err).To(MatchError("sql: database is closed"))
+	})
+})
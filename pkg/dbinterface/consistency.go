@@ -2,35 +2,43 @@ package dbinterface
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/rs/zerolog/log"
 )
 
-// ConsistencyTest runs a consistency test against DB2
+// DefaultConsistencyHoldTime is how long T1 keeps its transaction open after the update, while T2 runs its query
+const DefaultConsistencyHoldTime = 10 * time.Second
+
+// ConsistencyTest runs a consistency test: T1 locks and updates rows and keeps its transaction open for holdTime,
+// while T2 runs olapQuery with isolationLevel. A failing T2 query is an expected outcome (e.g. with a lock timeout)
+// and is only logged.
 func ConsistencyTest(
 	ctx context.Context,
 	cl Client,
 	olapQuery string,
 	isolationLevel IsolationLevel,
+	settings SessionSettings,
 	oltpLockQuery string,
 	oltpUpdateQuery string,
-) {
+	holdTime time.Duration,
+) error {
 	var logger = log.With().Logger()
 	pool, poolErr := cl.Pool(ctx)
 	if poolErr != nil {
-		logger.Fatal().Msgf("Failed to connect: %v", poolErr)
+		return fmt.Errorf("failed to connect: %w", poolErr)
 	}
 
 	conn1, connectErr1 := pool.Connect(ctx) //
 	if connectErr1 != nil {
-		logger.Fatal().Msgf("connect error for connection 1: %v", connectErr1)
+		return fmt.Errorf("connect error for connection 1: %w", connectErr1)
 	}
 	defer conn1.Close(ctx)
 
 	conn2, connectErr2 := pool.Connect(ctx)
 	if connectErr2 != nil {
-		logger.Fatal().Msgf("connect error for connection 1: %v", connectErr2)
+		return fmt.Errorf("connect error for connection 2: %w", connectErr2)
 	}
 	defer conn2.Close(ctx)
 
@@ -42,50 +50,88 @@ func ConsistencyTest(
 	}
 
 	if err := conn2.SetIsolationLevel(ctx, isolationLevel); err != nil {
-		logger.Fatal().Msgf("error while setting iso level on connection 1: %v", err)
+		return fmt.Errorf("error while setting iso level on connection 2: %w", err)
+	}
+
+	if err := settings.ApplyAll(ctx, []Connection{conn1, conn2}); err != nil {
+		return fmt.Errorf("error while applying session settings: %w", err)
 	}
 
 	logSinceElapsed("T1: BEGIN;")
 	if err := conn1.Begin(ctx); err != nil {
-		logger.Fatal().Msgf("error during begin transaction on connection 1: %v", err)
+		return fmt.Errorf("error during begin transaction on connection 1: %w", err)
 	}
+	t1Done := false
+	defer func() {
+		if !t1Done {
+			_ = conn1.Rollback(ctx)
+		}
+	}()
 
-	if row, err := conn1.QueryOneRow(ctx, olapQuery); err != nil {
-		logger.Fatal().Msgf("error during fetch of olap query: %v", err)
-	} else {
-		logger.Info().Msgf("T1: result: %v", row)
+	row, err := conn1.QueryOneRow(ctx, olapQuery)
+	if err != nil {
+		return fmt.Errorf("error during fetch of olap query: %w", err)
 	}
+	logger.Info().Msgf("T1: result: %v", row)
 
 	logSinceElapsed("T1: %s", oltpLockQuery)
 	if _, err := conn1.Execute(ctx, oltpLockQuery); err != nil {
-		logger.Fatal().Err(err)
+		return fmt.Errorf("error during T1 lock query: %w", err)
 	}
 
+	t2Err := make(chan error, 1)
 	go func() {
-		logSinceElapsed("T2: BEGIN;")
-		if err := conn2.Begin(ctx); err != nil {
-			logger.Fatal().Msgf("error during begin transaction: %v", err)
-		}
-
-		logSinceElapsed("T2: %s", olapQuery)
-		if row, err := conn2.QueryOneRow(ctx, olapQuery); err != nil {
-			logger.Fatal().Msgf("error during fetch of olap query: %v", err)
-		} else {
-			logger.Info().Msgf("T2: result: %v", row)
-		}
-		conn2.Commit(ctx)
+		t2Err <- runT2(ctx, conn2, olapQuery, logSinceElapsed, start)
 	}()
 
 	logSinceElapsed("T1: %s", oltpUpdateQuery)
 	if _, err := conn1.Execute(ctx, oltpUpdateQuery); err != nil {
-		logger.Fatal().Err(err)
+		_ = conn1.Rollback(ctx)
+		t1Done = true
+		<-t2Err
+		return fmt.Errorf("error during T1 update query: %w", err)
 	}
 
-	logSinceElapsed("T1: sleeping 10s');")
-	time.Sleep(10 * time.Second)
+	logSinceElapsed("T1: sleeping %v;", holdTime)
+	time.Sleep(holdTime)
 
 	logSinceElapsed("T1: COMMIT;")
+	t1Done = true
 	if err := conn1.Commit(ctx); err != nil {
-		logger.Fatal().Err(err)
+		<-t2Err
+		return fmt.Errorf("error during T1 commit: %w", err)
+	}
+
+	return <-t2Err
+}
+
+// runT2 runs the olap query of T2. Only an error to begin or commit the transaction is returned.
+func runT2(
+	ctx context.Context,
+	conn Connection,
+	olapQuery string,
+	logSinceElapsed func(string, ...any),
+	start time.Time,
+) error {
+	logSinceElapsed("T2: BEGIN;")
+	if err := conn.Begin(ctx); err != nil {
+		return fmt.Errorf("error during begin transaction on connection 2: %w", err)
+	}
+
+	logSinceElapsed("T2: %s", olapQuery)
+	row, err := conn.QueryOneRow(ctx, olapQuery)
+	if err != nil {
+		// with a lock or statement timeout, failing here is an expected outcome of the test
+		log.Warn().
+			Int64("elapsed (ms)", time.Since(start).Milliseconds()).
+			Str("error_class", string(ClassifyError(conn, err))).
+			Msgf("T2: olap query failed: %v", err)
+		_ = conn.Rollback(ctx)
+		return nil
+	}
+	log.Info().Msgf("T2: result: %v", row)
+	if err := conn.Commit(ctx); err != nil {
+		return fmt.Errorf("error during commit on connection 2: %w", err)
 	}
+	return nil
 }
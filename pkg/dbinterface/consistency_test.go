@@ -0,0 +1,76 @@
+package dbinterface_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface/fake"
+)
+
+type isoLevel string
+
+func (i isoLevel) AsQuery() string { return string(i) }
+
+var _ = Describe("ConsistencyTest", func() {
+	const (
+		olapQuery   = "SELECT AVG(price) AS avgprice FROM products"
+		lockQuery   = "SELECT * FROM products FOR UPDATE"
+		updateQuery = "UPDATE products SET price = 5000"
+	)
+	var (
+		ctx    context.Context
+		client *fake.Client
+	)
+
+	run := func(settings dbinterface.SessionSettings) error {
+		return dbinterface.ConsistencyTest(ctx, client, olapQuery, isoLevel("SET ISOLATION UR"), settings,
+			lockQuery, updateQuery, 10*time.Millisecond)
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		client = fake.NewClient()
+	})
+
+	It("should run T1 and T2 and commit both", func() {
+		client.Script.On(olapQuery).Return(map[string]any{"avgprice": 10})
+		Expect(run(dbinterface.SessionSettings{LockTimeout: time.Second})).To(Succeed())
+
+		Expect(client.Script.Count("SET ISOLATION UR")).To(Equal(1))
+		Expect(client.Script.Count(fake.StmtLockTimeout)).To(Equal(2))
+		Expect(client.Script.Count(updateQuery)).To(Equal(1))
+		Expect(client.Script.Count(olapQuery)).To(Equal(2))
+		Expect(client.Script.Count(fake.StmtCommit)).To(Equal(2))
+	})
+
+	It("should only log a failing T2 query", func() {
+		// the first olap query is from T1, the second one from T2
+		client.Script.On(olapQuery).After(1).
+			Fail(fake.NewError(dbinterface.ErrorClassLockTimeout, "lock timeout"))
+		client.Script.On(olapQuery).Return(map[string]any{"avgprice": 10})
+		Expect(run(dbinterface.SessionSettings{})).To(Succeed())
+		Expect(client.Script.Count(fake.StmtRollback)).To(Equal(1))
+	})
+
+	It("should return an error when the T1 update fails", func() {
+		client.Script.On(olapQuery).Return(map[string]any{"avgprice": 10})
+		client.Script.On(updateQuery).Fail(errors.New("permission denied"))
+		Expect(run(dbinterface.SessionSettings{})).To(MatchError(ContainSubstring("permission denied")))
+	})
+
+	It("should return an error when T2 cannot begin", func() {
+		client.Script.On(olapQuery).Return(map[string]any{"avgprice": 10})
+		client.Script.On(fake.StmtBegin).After(1).Fail(errors.New("too many transactions"))
+		Expect(run(dbinterface.SessionSettings{})).To(MatchError(ContainSubstring("too many transactions")))
+	})
+
+	It("should return an error when connecting fails", func() {
+		client.Script.On(fake.StmtConnect).After(1).Fail(errors.New("too many connections"))
+		Expect(run(dbinterface.SessionSettings{})).To(MatchError(ContainSubstring("connection 2")))
+	})
+})
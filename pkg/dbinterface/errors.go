@@ -0,0 +1,57 @@
+package dbinterface
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ErrorClass groups database errors by their cause, so that tests can decide which errors are expected
+type ErrorClass string
+
+const (
+	// ErrorClassSerialization means the transaction was rolled back because it could not be serialized
+	ErrorClassSerialization ErrorClass = "serialization"
+	// ErrorClassDeadlock means the transaction was rolled back to resolve a deadlock
+	ErrorClassDeadlock ErrorClass = "deadlock"
+	// ErrorClassLockTimeout means the statement waited too long for a lock
+	ErrorClassLockTimeout ErrorClass = "lock_timeout"
+	// ErrorClassStatementTimeout means the statement was canceled because it ran too long
+	ErrorClassStatementTimeout ErrorClass = "statement_timeout"
+	// ErrorClassConnectionLost means the connection to the database was lost
+	ErrorClassConnectionLost ErrorClass = "connection_lost"
+	// ErrorClassOther holds all other errors
+	ErrorClassOther ErrorClass = "other"
+)
+
+var allErrorClasses = []ErrorClass{
+	ErrorClassSerialization,
+	ErrorClassDeadlock,
+	ErrorClassLockTimeout,
+	ErrorClassStatementTimeout,
+	ErrorClassConnectionLost,
+	ErrorClassOther,
+}
+
+// ErrorClassifier can be implemented by a Connection which knows how to classify the errors of its RDBMS
+type ErrorClassifier interface {
+	ClassifyError(error) ErrorClass
+}
+
+// ClassifyError returns the class of an error which was returned by conn
+func ClassifyError(conn Connection, err error) ErrorClass {
+	if classifier, ok := conn.(ErrorClassifier); ok {
+		return classifier.ClassifyError(err)
+	}
+	return ErrorClassOther
+}
+
+// ParseErrorClass returns the ErrorClass for its name
+func ParseErrorClass(name string) (ErrorClass, error) {
+	normalized := ErrorClass(strings.ToLower(strings.TrimSpace(name)))
+	for _, class := range allErrorClasses {
+		if class == normalized {
+			return class, nil
+		}
+	}
+	return ErrorClassOther, fmt.Errorf("unknown error class %q (expected one of %v)", name, allErrorClasses)
+}
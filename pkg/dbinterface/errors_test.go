@@ -0,0 +1,24 @@
+package dbinterface
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ErrorClass", func() {
+	Context("ParseErrorClass", func() {
+		It("should parse known classes", func() {
+			Ω(ParseErrorClass(" Deadlock")).To(Equal(ErrorClassDeadlock))
+			Ω(ParseErrorClass("lock_timeout")).To(Equal(ErrorClassLockTimeout))
+		})
+		It("should reject unknown classes", func() {
+			_, err := ParseErrorClass("timeout")
+			Ω(err).To(HaveOccurred())
+		})
+	})
+	Context("ClassifyError", func() {
+		It("should return other for connections without a classifier", func() {
+			Ω(ClassifyError(nil, nil)).To(Equal(ErrorClassOther))
+		})
+	})
+})
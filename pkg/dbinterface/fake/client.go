@@ -0,0 +1,81 @@
+// Package fake holds an in-memory implementation of dbinterface.Client, Pool and Connection.
+// It records all statements and can be scripted to return rows, add latency and fail, so that code which runs
+// against a database can be tested deterministically, without a database.
+package fake
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+)
+
+// Client implements dbinterface.Client. All pools and connections of a client share its Script.
+type Client struct {
+	Script *Script
+	// PoolErr is returned by Pool when set
+	PoolErr error
+	pool    *Pool
+}
+
+// NewClient returns a new Client with an empty Script
+func NewClient() *Client {
+	return &Client{Script: NewScript()}
+}
+
+// Pool returns the (one) pool of this client
+func (c *Client) Pool(_ context.Context) (dbinterface.Pool, error) {
+	if c.PoolErr != nil {
+		return nil, c.PoolErr
+	}
+	if c.pool == nil {
+		c.pool = &Pool{script: c.Script}
+	}
+	return c.pool, nil
+}
+
+// ConnectFunc implements dbinterface.DirectConnector. Every call connects, runs SELECT 1 when query is set, and
+// closes the connection again, so the calls are recorded as CONNECT, SELECT 1 and CLOSE.
+func (c *Client) ConnectFunc() (dbinterface.ConnectFunc, func() error, error) {
+	pool, err := c.Pool(context.Background())
+	if err != nil {
+		return nil, nil, err
+	}
+	return func(ctx context.Context, query bool) (dbinterface.ConnectTiming, error) {
+		start := time.Now()
+		conn, err := pool.Connect(ctx)
+		if err != nil {
+			return dbinterface.ConnectTiming{}, err
+		}
+		defer func() { _ = conn.Close(ctx) }()
+		if query {
+			if _, err = conn.Query(ctx, "SELECT 1"); err != nil {
+				return dbinterface.ConnectTiming{}, err
+			}
+		}
+		return dbinterface.ConnectTiming{Total: time.Since(start)}, nil
+	}, func() error { return nil }, nil
+}
+
+// Pool implements dbinterface.Pool
+type Pool struct {
+	script  *Script
+	lastID  atomic.Int32
+	openNow atomic.Int32
+}
+
+// Connect returns a new Connection. Connecting is recorded as CONNECT, so it can be scripted to fail or be slow.
+func (p *Pool) Connect(ctx context.Context) (dbinterface.Connection, error) {
+	conn := &Connection{id: int(p.lastID.Add(1)), pool: p}
+	if _, _, err := conn.run(ctx, StmtConnect, nil, nil); err != nil {
+		return nil, err
+	}
+	p.openNow.Add(1)
+	return conn, nil
+}
+
+// Open returns the number of connections which are not closed yet
+func (p *Pool) Open() int {
+	return int(p.openNow.Load())
+}
@@ -0,0 +1,215 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+)
+
+// Connection implements dbinterface.Connection, as well as dbinterface.BulkInserter, dbinterface.TxPreparer and
+// dbinterface.ErrorClassifier. It is not safe for concurrent use, just like real connections.
+type Connection struct {
+	id     int
+	pool   *Pool
+	inTx   bool
+	closed bool
+	// IsolationLevel, LockTimeout and StatementTimeout hold the last values that were set
+	IsolationLevel   dbinterface.IsolationLevel
+	LockTimeout      time.Duration
+	StatementTimeout time.Duration
+}
+
+// ID returns the id of the connection, which is also recorded in every Call
+func (c *Connection) ID() int {
+	return c.id
+}
+
+// InTx returns true while a transaction is active
+func (c *Connection) InTx() bool {
+	return c.inTx
+}
+
+// run records a statement and returns its scripted result
+func (c *Connection) run(ctx context.Context, sql string, args []any, payload any) ([]map[string]any, int64, error) {
+	if c.closed {
+		return nil, 0, errors.New("connection is closed")
+	}
+	return c.pool.script.result(ctx, Call{
+		ConnID:  c.id,
+		SQL:     normalize(sql),
+		Args:    args,
+		Payload: payload,
+		InTx:    c.inTx,
+	})
+}
+
+// Close closes the connection
+func (c *Connection) Close(ctx context.Context) error {
+	if c.closed {
+		return nil
+	}
+	_, _, err := c.run(ctx, StmtClose, nil, nil)
+	c.closed = true
+	c.inTx = false
+	c.pool.openNow.Add(-1)
+	return err
+}
+
+// Begin starts a transaction
+func (c *Connection) Begin(ctx context.Context) error {
+	if c.inTx {
+		return errors.New("transaction already active")
+	}
+	if _, _, err := c.run(ctx, StmtBegin, nil, nil); err != nil {
+		return err
+	}
+	c.inTx = true
+	return nil
+}
+
+// Commit ends the transaction. The transaction also ends when the commit fails.
+func (c *Connection) Commit(ctx context.Context) error {
+	if !c.inTx {
+		return errors.New("no active transaction")
+	}
+	_, _, err := c.run(ctx, StmtCommit, nil, nil)
+	c.inTx = false
+	return err
+}
+
+// Rollback ends the transaction
+func (c *Connection) Rollback(ctx context.Context) error {
+	if !c.inTx {
+		return errors.New("no active transaction")
+	}
+	_, _, err := c.run(ctx, StmtRollback, nil, nil)
+	c.inTx = false
+	return err
+}
+
+// Execute records a statement with its arguments and returns the scripted number of affected rows
+func (c *Connection) Execute(ctx context.Context, query string, args ...any) (int64, error) {
+	_, affected, err := c.run(ctx, query, args, nil)
+	return affected, err
+}
+
+// ExecuteWithPayload records a statement with its arguments and payload
+func (c *Connection) ExecuteWithPayload(ctx context.Context, query string, payload any, args ...any) (int64, error) {
+	_, affected, err := c.run(ctx, query, args, payload)
+	return affected, err
+}
+
+// Query records a query and returns the scripted rows
+func (c *Connection) Query(ctx context.Context, query string, args ...any) ([]map[string]any, error) {
+	rows, _, err := c.run(ctx, query, args, nil)
+	if err != nil {
+		return nil, err
+	}
+	return append([]map[string]any(nil), rows...), nil
+}
+
+// QueryOneRow records a query and expects exactly one scripted row
+func (c *Connection) QueryOneRow(ctx context.Context, query string, args ...any) (map[string]any, error) {
+	rows, err := c.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) != 1 {
+		return nil, fmt.Errorf("expected 1 row, got %d", len(rows))
+	}
+	return rows[0], nil
+}
+
+// SetIsolationLevel records the query of the isolation level
+func (c *Connection) SetIsolationLevel(ctx context.Context, isoLevel dbinterface.IsolationLevel) error {
+	if _, _, err := c.run(ctx, isoLevel.AsQuery(), nil, nil); err != nil {
+		return err
+	}
+	c.IsolationLevel = isoLevel
+	return nil
+}
+
+// SetLockTimeout records the lock timeout
+func (c *Connection) SetLockTimeout(ctx context.Context, timeout time.Duration) error {
+	if _, _, err := c.run(ctx, fmt.Sprintf("%s %v", StmtLockTimeout, timeout), nil, nil); err != nil {
+		return err
+	}
+	c.LockTimeout = timeout
+	return nil
+}
+
+// SetStatementTimeout records the statement timeout
+func (c *Connection) SetStatementTimeout(ctx context.Context, timeout time.Duration) error {
+	if _, _, err := c.run(ctx, fmt.Sprintf("%s %v", StmtStatementTimeout, timeout), nil, nil); err != nil {
+		return err
+	}
+	c.StatementTimeout = timeout
+	return nil
+}
+
+// ClassifyError returns the class of an Error, and ErrorClassOther for all other errors
+func (c *Connection) ClassifyError(err error) dbinterface.ErrorClass {
+	var fakeErr *Error
+	if errors.As(err, &fakeErr) {
+		return fakeErr.Class
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return dbinterface.ErrorClassStatementTimeout
+	}
+	return dbinterface.ErrorClassOther
+}
+
+// InsertLOBRowsBulk records a BULK INSERT with the rows as payload. It returns the scripted number of affected rows,
+// or the number of rows when nothing was scripted.
+func (c *Connection) InsertLOBRowsBulk(
+	ctx context.Context,
+	schema, table string,
+	rows []dbinterface.LobRow,
+) (int64, int64, error) {
+	_, affected, err := c.run(ctx, fmt.Sprintf("%s %s.%s", StmtBulkInsert, schema, table), nil, rows)
+	if err != nil {
+		return 0, 0, err
+	}
+	if affected == 0 {
+		affected = int64(len(rows))
+	}
+	var totalBytes int64
+	for _, r := range rows {
+		switch v := r.Payload.(type) {
+		case []byte:
+			totalBytes += int64(len(v))
+		case string:
+			totalBytes += int64(len(v))
+		}
+	}
+	return affected, totalBytes, nil
+}
+
+// PrepareInTx records a PREPARE, and returns a statement which records every execution as the prepared statement
+func (c *Connection) PrepareInTx(ctx context.Context, sql string) (dbinterface.PreparedStatement, error) {
+	if !c.inTx {
+		return nil, errors.New("PrepareInTx requires an active transaction")
+	}
+	if _, _, err := c.run(ctx, fmt.Sprintf("%s %s", StmtPrepare, sql), nil, nil); err != nil {
+		return nil, err
+	}
+	return &preparedStatement{conn: c, sql: sql}, nil
+}
+
+type preparedStatement struct {
+	conn *Connection
+	sql  string
+}
+
+// ExecWithPayload records the prepared statement with its arguments and payload
+func (s *preparedStatement) ExecWithPayload(ctx context.Context, payload any, args ...any) (int64, error) {
+	return s.conn.ExecuteWithPayload(ctx, s.sql, payload, args...)
+}
+
+// Close closes the prepared statement
+func (s *preparedStatement) Close(_ context.Context) error {
+	return nil
+}
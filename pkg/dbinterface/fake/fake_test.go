@@ -0,0 +1,191 @@
+package fake_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface/fake"
+)
+
+type isoLevel string
+
+func (i isoLevel) AsQuery() string { return string(i) }
+
+var _ = Describe("Fake", func() {
+	var (
+		ctx    context.Context
+		client *fake.Client
+		conn   dbinterface.Connection
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		client = fake.NewClient()
+		pool, err := client.Pool(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		conn, err = pool.Connect(ctx)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Context("Client", func() {
+		It("should return the pool error", func() {
+			client.PoolErr = errors.New("no server")
+			_, err := client.Pool(ctx)
+			Expect(err).To(MatchError("no server"))
+		})
+		It("should fail to connect when scripted", func() {
+			client.Script.On(fake.StmtConnect).Fail(errors.New("refused"))
+			pool, err := client.Pool(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = pool.Connect(ctx)
+			Expect(err).To(MatchError("refused"))
+		})
+		It("should track open connections", func() {
+			pool, _ := client.Pool(ctx)
+			Expect(pool.(*fake.Pool).Open()).To(Equal(1))
+			Expect(conn.Close(ctx)).To(Succeed())
+			Expect(pool.(*fake.Pool).Open()).To(BeZero())
+		})
+		It("should connect, query and close on every call of the connect func", func() {
+			connect, closeConnect, err := client.ConnectFunc()
+			Expect(err).NotTo(HaveOccurred())
+			defer func() { Expect(closeConnect()).To(Succeed()) }()
+			_, err = connect(ctx, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.Script.Statements()).To(Equal([]string{
+				fake.StmtConnect, fake.StmtConnect, "SELECT 1", fake.StmtClose}))
+		})
+		It("should return the errors of the connect func", func() {
+			connect, _, err := client.ConnectFunc()
+			Expect(err).NotTo(HaveOccurred())
+			client.Script.On("SELECT 1").Times(1).Fail(errors.New("shutting down"))
+			_, err = connect(ctx, true)
+			Expect(err).To(MatchError("shutting down"))
+			client.Script.On(fake.StmtConnect).Fail(errors.New("refused"))
+			_, err = connect(ctx, false)
+			Expect(err).To(MatchError("refused"))
+			client.PoolErr = errors.New("no server")
+			_, _, err = client.ConnectFunc()
+			Expect(err).To(MatchError("no server"))
+		})
+	})
+
+	Context("Script", func() {
+		It("should record statements with their connection and transaction", func() {
+			Expect(conn.Begin(ctx)).To(Succeed())
+			_, err := conn.ExecuteWithPayload(ctx, "insert into t\n  values (?, ?)", "payload", 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(conn.Commit(ctx)).To(Succeed())
+
+			calls := client.Script.Calls()
+			Expect(client.Script.Statements()).To(Equal([]string{
+				fake.StmtConnect, fake.StmtBegin, "INSERT INTO T VALUES (?, ?)", fake.StmtCommit}))
+			Expect(calls[2].ConnID).To(Equal(conn.(*fake.Connection).ID()))
+			Expect(calls[2].InTx).To(BeTrue())
+			Expect(calls[2].Args).To(Equal([]any{1}))
+			Expect(calls[2].Payload).To(Equal("payload"))
+			Expect(client.Script.Count("insert into")).To(Equal(1))
+		})
+		It("should return scripted rows and affected rows", func() {
+			client.Script.On("SELECT MAX(id)").Return(map[string]any{"max_id": int64(5)})
+			client.Script.OnRegexp(regexp.MustCompile(`^UPDATE`)).Affect(3)
+
+			row, err := conn.QueryOneRow(ctx, "select max(id) from t")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(row).To(HaveKeyWithValue("max_id", int64(5)))
+			n, err := conn.Execute(ctx, "update t set x = 1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(n).To(BeEquivalentTo(3))
+		})
+		It("should fail QueryOneRow without exactly one row", func() {
+			_, err := conn.QueryOneRow(ctx, "select 1")
+			Expect(err).To(HaveOccurred())
+		})
+		It("should fail halfway when scripted with After and Times", func() {
+			boom := errors.New("boom")
+			client.Script.On("INSERT").After(2).Times(1).Fail(boom)
+			var errs []error
+			for range 4 {
+				_, err := conn.Execute(ctx, "INSERT INTO t VALUES (1)")
+				errs = append(errs, err)
+			}
+			Expect(errs).To(Equal([]error{nil, nil, boom, nil}))
+		})
+		It("should delay statements and stop when the context is done", func() {
+			client.Script.On("SLOW").Delay(time.Hour)
+			timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+			defer cancel()
+			_, err := conn.Execute(timeoutCtx, "SLOW")
+			Expect(err).To(MatchError(context.DeadlineExceeded))
+			Expect(dbinterface.ClassifyError(conn, err)).To(Equal(dbinterface.ErrorClassStatementTimeout))
+		})
+		It("should reset rules and calls", func() {
+			client.Script.On("X").Fail(errors.New("boom"))
+			client.Script.Reset()
+			_, err := conn.Execute(ctx, "X")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.Script.Calls()).To(HaveLen(1))
+		})
+	})
+
+	Context("Connection", func() {
+		It("should guard the transaction state", func() {
+			Expect(conn.Commit(ctx)).NotTo(Succeed())
+			Expect(conn.Rollback(ctx)).NotTo(Succeed())
+			Expect(conn.Begin(ctx)).To(Succeed())
+			Expect(conn.Begin(ctx)).NotTo(Succeed())
+			Expect(conn.Rollback(ctx)).To(Succeed())
+			Expect(conn.(*fake.Connection).InTx()).To(BeFalse())
+		})
+		It("should fail after it was closed", func() {
+			Expect(conn.Close(ctx)).To(Succeed())
+			_, err := conn.Execute(ctx, "SELECT 1")
+			Expect(err).To(HaveOccurred())
+		})
+		It("should remember session settings", func() {
+			Expect(conn.SetIsolationLevel(ctx, isoLevel("SET ISOLATION UR"))).To(Succeed())
+			Expect(conn.SetLockTimeout(ctx, time.Second)).To(Succeed())
+			Expect(conn.SetStatementTimeout(ctx, time.Minute)).To(Succeed())
+			fc := conn.(*fake.Connection)
+			Expect(fc.IsolationLevel).To(Equal(isoLevel("SET ISOLATION UR")))
+			Expect(fc.LockTimeout).To(Equal(time.Second))
+			Expect(fc.StatementTimeout).To(Equal(time.Minute))
+			Expect(client.Script.Count(fake.StmtLockTimeout + " 1s")).To(Equal(1))
+		})
+		It("should classify scripted errors", func() {
+			client.Script.On("UPDATE").Fail(fake.NewError(dbinterface.ErrorClassDeadlock, "deadlock"))
+			_, err := conn.Execute(ctx, "UPDATE t SET x = 1")
+			Expect(dbinterface.ClassifyError(conn, err)).To(Equal(dbinterface.ErrorClassDeadlock))
+			Expect(dbinterface.ClassifyError(conn, errors.New("other"))).To(Equal(dbinterface.ErrorClassOther))
+		})
+		It("should insert in bulk", func() {
+			inserter := conn.(dbinterface.BulkInserter)
+			rows := []dbinterface.LobRow{{Payload: []byte("abc")}, {Payload: "de"}}
+			n, size, err := inserter.InsertLOBRowsBulk(ctx, "s", "t", rows)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(n).To(BeEquivalentTo(2))
+			Expect(size).To(BeEquivalentTo(5))
+			Expect(client.Script.Calls()[1].Payload).To(Equal(rows))
+		})
+		It("should prepare statements in a transaction", func() {
+			preparer := conn.(dbinterface.TxPreparer)
+			_, err := preparer.PrepareInTx(ctx, "INSERT INTO t VALUES (?)")
+			Expect(err).To(HaveOccurred())
+
+			Expect(conn.Begin(ctx)).To(Succeed())
+			stmt, err := preparer.PrepareInTx(ctx, "INSERT INTO t VALUES (?)")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = stmt.ExecWithPayload(ctx, "payload")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stmt.Close(ctx)).To(Succeed())
+			Expect(client.Script.Count(fake.StmtPrepare)).To(Equal(1))
+			Expect(client.Script.Count("INSERT INTO T VALUES (?)")).To(Equal(2))
+		})
+	})
+})
@@ -0,0 +1,230 @@
+package fake
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+)
+
+// Pseudo statements which are recorded (and can be scripted) for calls which do not run SQL
+const (
+	StmtConnect          = "CONNECT"
+	StmtClose            = "CLOSE"
+	StmtBegin            = "BEGIN"
+	StmtCommit           = "COMMIT"
+	StmtRollback         = "ROLLBACK"
+	StmtLockTimeout      = "SET LOCK TIMEOUT"
+	StmtStatementTimeout = "SET STATEMENT TIMEOUT"
+	StmtPrepare          = "PREPARE"
+	StmtBulkInsert       = "BULK INSERT"
+)
+
+var whitespace = regexp.MustCompile(`\s+`)
+
+// Call is a recorded call on a Connection
+type Call struct {
+	ConnID  int
+	SQL     string
+	Args    []any
+	Payload any // the payload of ExecuteWithPayload, or the rows of a bulk insert
+	InTx    bool
+	Err     error
+}
+
+// Rule defines the result for statements that match it. Rules are set up with Script.On.
+type Rule struct {
+	match    func(string) bool
+	rows     []map[string]any
+	affected int64
+	err      error
+	delay    time.Duration
+	skip     int
+	times    int
+	hits     int
+}
+
+// Return sets the rows which are returned by matching queries
+func (r *Rule) Return(rows ...map[string]any) *Rule {
+	r.rows = rows
+	return r
+}
+
+// Affect sets the number of affected rows which is returned by matching statements
+func (r *Rule) Affect(n int64) *Rule {
+	r.affected = n
+	return r
+}
+
+// Fail makes matching statements return err
+func (r *Rule) Fail(err error) *Rule {
+	r.err = err
+	return r
+}
+
+// Delay makes matching statements take d. A canceled context stops the delay and fails the statement.
+func (r *Rule) Delay(d time.Duration) *Rule {
+	r.delay = d
+	return r
+}
+
+// After lets the first n matching statements pass, which can be used for failures halfway a transaction
+func (r *Rule) After(n int) *Rule {
+	r.skip = n
+	return r
+}
+
+// Times limits the rule to n matching statements (after the ones skipped with After). 0 means no limit.
+func (r *Rule) Times(n int) *Rule {
+	r.times = n
+	return r
+}
+
+// apply returns true if the rule applies to this (matching) statement, and counts the hit
+func (r *Rule) apply() bool {
+	r.hits++
+	if r.hits <= r.skip {
+		return false
+	}
+	return r.times == 0 || r.hits <= r.skip+r.times
+}
+
+// Script holds the rules and the recorded calls of all connections of a client. It is safe for concurrent use.
+type Script struct {
+	mu    sync.Mutex
+	rules []*Rule
+	calls []Call
+}
+
+// NewScript returns an empty Script. Statements which match no rule succeed without rows.
+func NewScript() *Script {
+	return &Script{}
+}
+
+// On adds a rule for statements containing substr (case insensitive, with whitespace collapsed).
+// Rules are tried in the order they were added, and the first rule which applies is used.
+func (s *Script) On(substr string) *Rule {
+	needle := normalize(substr)
+	return s.add(func(sql string) bool { return strings.Contains(sql, needle) })
+}
+
+// OnRegexp adds a rule for statements matching re (matched against the normalized statement)
+func (s *Script) OnRegexp(re *regexp.Regexp) *Rule {
+	return s.add(re.MatchString)
+}
+
+func (s *Script) add(match func(string) bool) *Rule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rule := &Rule{match: match}
+	s.rules = append(s.rules, rule)
+	return rule
+}
+
+// Calls returns a copy of all recorded calls
+func (s *Script) Calls() []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Call(nil), s.calls...)
+}
+
+// Statements returns the normalized SQL of all recorded calls
+func (s *Script) Statements() []string {
+	calls := s.Calls()
+	statements := make([]string, len(calls))
+	for i, call := range calls {
+		statements[i] = call.SQL
+	}
+	return statements
+}
+
+// Count returns how many recorded calls contain substr (case insensitive)
+func (s *Script) Count(substr string) int {
+	needle := normalize(substr)
+	var count int
+	for _, statement := range s.Statements() {
+		if strings.Contains(statement, needle) {
+			count++
+		}
+	}
+	return count
+}
+
+// Reset removes all rules and recorded calls
+func (s *Script) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules = nil
+	s.calls = nil
+}
+
+// result finds the rule for a statement, waits for its delay and records the call
+func (s *Script) result(ctx context.Context, call Call) ([]map[string]any, int64, error) {
+	var rule *Rule
+	s.mu.Lock()
+	for _, r := range s.rules {
+		if r.match(call.SQL) && r.apply() {
+			rule = r
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	var (
+		rows     []map[string]any
+		affected int64
+		err      error
+	)
+	if rule != nil {
+		rows, affected, err = rule.rows, rule.affected, rule.err
+		if sleepErr := sleep(ctx, rule.delay); sleepErr != nil {
+			err = sleepErr
+		}
+	}
+	if err == nil {
+		err = ctx.Err()
+	}
+
+	call.Err = err
+	s.mu.Lock()
+	s.calls = append(s.calls, call)
+	s.mu.Unlock()
+	return rows, affected, err
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func normalize(sql string) string {
+	return strings.ToUpper(strings.TrimSpace(whitespace.ReplaceAllString(sql, " ")))
+}
+
+// Error is an error with an error class, which the fake Connection returns from ClassifyError
+type Error struct {
+	Class   dbinterface.ErrorClass
+	Message string
+}
+
+// NewError returns a new Error
+func NewError(class dbinterface.ErrorClass, message string) *Error {
+	return &Error{Class: class, Message: message}
+}
+
+// Error implements error
+func (e *Error) Error() string {
+	return e.Message
+}
@@ -0,0 +1,74 @@
+package dbinterface
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrPoolTooSmall is returned when a test needs more connections at once than the pool can hold
+var ErrPoolTooSmall = errors.New("connection pool is too small")
+
+// PoolSettings configure the connection pool of a client. Zero values keep the defaults of the client.
+type PoolSettings struct {
+	// MaxConns is the maximum number of connections. With 0 the pool holds as many connections as the test needs.
+	MaxConns int
+	// MinConns is the number of connections which are kept open, even when idle. database/sql does not open
+	// connections in advance, so there it only caps the number of idle connections which are kept for reuse.
+	MinConns int
+	// MaxConnLifetime is the time after which a connection is closed and replaced
+	MaxConnLifetime time.Duration
+	// MaxConnIdleTime is the time after which an idle connection is closed
+	MaxConnIdleTime time.Duration
+	// HealthCheckPeriod is the interval at which idle connections are checked (PostgreSQL only, database/sql checks
+	// connections when they are taken from the pool)
+	HealthCheckPeriod time.Duration
+	// ConnectTimeout limits the time to set up a new connection
+	ConnectTimeout time.Duration
+}
+
+// CheckSize returns an error wrapping ErrPoolTooSmall when MaxConns is limited to less than conns
+func (s PoolSettings) CheckSize(conns int) error {
+	if s.MaxConns > 0 && s.MaxConns < conns {
+		return fmt.Errorf("%w: the test needs %d connections at once, but maxConns is %d; "+
+			"raise maxConns or lower the parallelism", ErrPoolTooSmall, conns, s.MaxConns)
+	}
+	return nil
+}
+
+// ConfigureDB applies the settings to a database/sql pool. Without MaxConns the number of connections is unlimited.
+// database/sql has no minimum, so MinConns sets the maximum number of idle connections: connections are not opened
+// in advance, but up to MinConns of them stay open once a test has released them.
+// The ConnectTimeout is not handled by database/sql and should be part of the connection string.
+func (s PoolSettings) ConfigureDB(db *sql.DB) {
+	if s.MaxConns > 0 {
+		db.SetMaxOpenConns(s.MaxConns)
+	}
+	if s.MinConns > 0 {
+		db.SetMaxIdleConns(s.MinConns)
+	}
+	if s.MaxConnLifetime > 0 {
+		db.SetConnMaxLifetime(s.MaxConnLifetime)
+	}
+	if s.MaxConnIdleTime > 0 {
+		db.SetConnMaxIdleTime(s.MaxConnIdleTime)
+	}
+}
+
+// PoolSizer is implemented by clients which can size their pool to the number of connections a test needs
+type PoolSizer interface {
+	// EnsurePoolSize makes sure the pool can hold conns connections at once, or returns an error wrapping
+	// ErrPoolTooSmall when it cannot
+	EnsurePoolSize(conns int) error
+}
+
+// EnsurePoolSize sizes the pool of client for conns connections at once, when the client supports it.
+// Tests call this before opening their worker connections, so that they fail fast instead of waiting for a
+// connection which is never released.
+func EnsurePoolSize(client Client, conns int) error {
+	if sizer, ok := client.(PoolSizer); ok {
+		return sizer.EnsurePoolSize(conns)
+	}
+	return nil
+}
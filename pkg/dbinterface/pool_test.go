@@ -0,0 +1,82 @@
+package dbinterface
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// sizedClient records the pool size which is requested from it
+type sizedClient struct {
+	Client
+	settings  PoolSettings
+	requested int
+}
+
+func (c *sizedClient) EnsurePoolSize(conns int) error {
+	c.requested = conns
+	return c.settings.CheckSize(conns)
+}
+
+// unsizedClient does not implement PoolSizer
+type unsizedClient struct{}
+
+func (unsizedClient) Pool(_ context.Context) (Pool, error) {
+	return nil, errors.New("not implemented")
+}
+
+var _ = Describe("PoolSettings", func() {
+	Describe("CheckSize", func() {
+		It("should accept any size without maxConns", func() {
+			Ω(PoolSettings{}.CheckSize(1000)).To(Succeed())
+		})
+		It("should accept a size up to maxConns", func() {
+			Ω(PoolSettings{MaxConns: 8}.CheckSize(8)).To(Succeed())
+		})
+		It("should fail fast when the test needs more connections than maxConns", func() {
+			err := PoolSettings{MaxConns: 8}.CheckSize(64)
+			Ω(err).To(MatchError(ErrPoolTooSmall))
+			Ω(err).To(MatchError(ContainSubstring("needs 64 connections at once, but maxConns is 8")))
+		})
+	})
+	Describe("ConfigureDB", func() {
+		It("should keep up to minConns idle connections, without opening them in advance", func(ctx context.Context) {
+			db, err := sql.Open("sqlite", filepath.Join(GinkgoT().TempDir(), "test.sqlite"))
+			Ω(err).NotTo(HaveOccurred())
+			defer func() { Ω(db.Close()).To(Succeed()) }()
+			PoolSettings{MaxConns: 4, MinConns: 2}.ConfigureDB(db)
+			Ω(db.Stats().OpenConnections).To(BeZero())
+
+			var conns []*sql.Conn
+			for range 4 {
+				conn, connErr := db.Conn(ctx)
+				Ω(connErr).NotTo(HaveOccurred())
+				conns = append(conns, conn)
+			}
+			Ω(db.Stats().MaxOpenConnections).To(Equal(4))
+			for _, conn := range conns {
+				Ω(conn.Close()).To(Succeed())
+			}
+			Ω(db.Stats().Idle).To(Equal(2))
+		})
+	})
+	Describe("EnsurePoolSize", func() {
+		It("should size clients which support it", func() {
+			client := &sizedClient{}
+			Ω(EnsurePoolSize(client, 12)).To(Succeed())
+			Ω(client.requested).To(Equal(12))
+		})
+		It("should return the error of the client", func() {
+			client := &sizedClient{settings: PoolSettings{MaxConns: 2, MaxConnLifetime: time.Minute}}
+			Ω(EnsurePoolSize(client, 3)).To(MatchError(ErrPoolTooSmall))
+		})
+		It("should ignore clients which do not support it", func() {
+			Ω(EnsurePoolSize(unsizedClient{}, 12)).To(Succeed())
+		})
+	})
+})
@@ -0,0 +1,38 @@
+package dbinterface
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SessionSettings are applied to every connection of a test. Zero values leave the server default in place.
+type SessionSettings struct {
+	LockTimeout      time.Duration
+	StatementTimeout time.Duration
+}
+
+// Apply applies the settings to a connection
+func (s SessionSettings) Apply(ctx context.Context, conn Connection) error {
+	if s.LockTimeout > 0 {
+		if err := conn.SetLockTimeout(ctx, s.LockTimeout); err != nil {
+			return fmt.Errorf("failed to set lock timeout: %w", err)
+		}
+	}
+	if s.StatementTimeout > 0 {
+		if err := conn.SetStatementTimeout(ctx, s.StatementTimeout); err != nil {
+			return fmt.Errorf("failed to set statement timeout: %w", err)
+		}
+	}
+	return nil
+}
+
+// ApplyAll applies the settings to all connections
+func (s SessionSettings) ApplyAll(ctx context.Context, conns []Connection) error {
+	for i, conn := range conns {
+		if err := s.Apply(ctx, conn); err != nil {
+			return fmt.Errorf("connection %d: %w", i, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package dbinterface
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// timeoutConn records the timeouts which are set on it
+type timeoutConn struct {
+	Connection
+	lockTimeout      time.Duration
+	statementTimeout time.Duration
+	err              error
+}
+
+func (c *timeoutConn) SetLockTimeout(_ context.Context, timeout time.Duration) error {
+	c.lockTimeout = timeout
+	return c.err
+}
+
+func (c *timeoutConn) SetStatementTimeout(_ context.Context, timeout time.Duration) error {
+	c.statementTimeout = timeout
+	return c.err
+}
+
+var _ = Describe("SessionSettings", func() {
+	ctx := context.Background()
+	It("should apply the timeouts to all connections", func() {
+		conns := []Connection{&timeoutConn{}, &timeoutConn{}}
+		settings := SessionSettings{LockTimeout: time.Second, StatementTimeout: time.Minute}
+		Ω(settings.ApplyAll(ctx, conns)).To(Succeed())
+		for _, conn := range conns {
+			Ω(conn.(*timeoutConn).lockTimeout).To(Equal(time.Second))
+			Ω(conn.(*timeoutConn).statementTimeout).To(Equal(time.Minute))
+		}
+	})
+	It("should leave the server defaults alone for zero values", func() {
+		conn := &timeoutConn{err: errors.New("should not be called")}
+		Ω(SessionSettings{}.Apply(ctx, conn)).To(Succeed())
+	})
+	It("should return errors", func() {
+		conn := &timeoutConn{err: errors.New("boom")}
+		err := SessionSettings{LockTimeout: time.Second}.ApplyAll(ctx, []Connection{conn})
+		Ω(err).To(MatchError(ContainSubstring("boom")))
+	})
+})
@@ -0,0 +1,51 @@
+package environment
+
+// db2DatabaseSettings and db2InstanceSettings are the configuration parameters which matter most for the results of
+// the tests
+var (
+	db2DatabaseSettings = []string{
+		"self_tuning_mem", "database_memory", "locklist", "maxlocks", "locktimeout", "cur_commit", "sortheap",
+		"sheapthres_shr", "pckcachesz", "catalogcache_sz", "logbufsz", "logfilsiz", "logprimary", "logsecond",
+		"num_iocleaners", "num_ioservers", "dft_prefetch_sz", "dft_extent_sz", "dft_table_org", "blocknonlogged",
+	}
+	db2InstanceSettings = []string{
+		"instance_memory", "sheapthres", "intra_parallel", "max_querydegree", "cpuspeed", "comm_bandwidth",
+		"numdb",
+	}
+)
+
+// DB2Queries returns the queries which describe a DB2 server: the instance (SYSIBMADM.ENV_INST_INFO), the key
+// database and instance configuration parameters, and the sizes of the buffer pools. NPAGES is -2 for automatic
+// buffer pools, which is why the current size (from MON_GET_BUFFERPOOL) is added as well.
+func DB2Queries() []Query {
+	return []Query{
+		{
+			Name: "env_inst_info",
+			SQL: `SELECT inst_name, num_members, inst_ptr_size, release_num, service_level, bld_level, fixpack_num
+FROM sysibmadm.env_inst_info`,
+		},
+		{
+			Name: "dbcfg",
+			SQL: `SELECT name, value FROM sysibmadm.dbcfg
+WHERE name IN (` + join(quoteAll(db2DatabaseSettings)) + `)`,
+			Pairs: true,
+		},
+		{
+			Name: "dbmcfg",
+			SQL: `SELECT name, value FROM sysibmadm.dbmcfg
+WHERE name IN (` + join(quoteAll(db2InstanceSettings)) + `)`,
+			Pairs: true,
+		},
+		{
+			Name: "bufferpools",
+			SQL:  `SELECT bpname, pagesize, npages FROM syscat.bufferpools`,
+			Keys: []string{"bpname"},
+		},
+		{
+			Name:     "bufferpool_size",
+			SQL:      `SELECT bp_name, member, bp_cur_buffsz FROM TABLE(MON_GET_BUFFERPOOL('', -2))`,
+			Keys:     []string{"bp_name", "member"},
+			Optional: true,
+		},
+	}
+}
@@ -0,0 +1,75 @@
+package environment_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbclient"
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface/fake"
+	"github.com/pgvillage-tools/dbtwool/pkg/environment"
+	"github.com/pgvillage-tools/dbtwool/pkg/sqlite"
+)
+
+var _ = Describe("Environment", func() {
+	var ctx context.Context
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	It("should describe the client", func() {
+		env := environment.Client()
+		Expect(env.Version).NotTo(BeEmpty())
+		Expect(env.Hostname).NotTo(BeEmpty())
+		Expect(env.CPUs).To(BeNumerically(">", 0))
+	})
+	It("should describe the server with rows, keyed rows and name value pairs", func() {
+		client := fake.NewClient()
+		client.Script.On("FROM sysibmadm.env_inst_info").Return(map[string]any{"inst_name": "db2inst1",
+			"service_level": "DB2 v11.5.9.0"})
+		client.Script.On("FROM sysibmadm.dbcfg").Return(
+			map[string]any{"name": "locklist", "value": "AUTOMATIC"},
+			map[string]any{"name": "logfilsiz", "value": "10240"})
+		client.Script.On("FROM syscat.bufferpools").Return(
+			map[string]any{"bpname": "IBMDEFAULTBP", "pagesize": int32(4096), "npages": int32(-2)})
+		client.Script.On("MON_GET_BUFFERPOOL").Fail(errors.New("SQL0551N no privilege"))
+		pool, err := client.Pool(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		conn, err := pool.Connect(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		env := environment.Environment{}
+		Expect(env.Describe(ctx, conn, environment.QueriesFor(dbclient.DB2))).To(Succeed())
+		Expect(env.Server["env_inst_info"]).To(HaveKeyWithValue("service_level", "DB2 v11.5.9.0"))
+		Expect(env.Server["dbcfg"]).To(Equal(map[string]string{"locklist": "AUTOMATIC", "logfilsiz": "10240"}))
+		Expect(env.Server["bufferpools:IBMDEFAULTBP"]).To(Equal(map[string]string{"pagesize": "4096", "npages": "-2"}))
+		Expect(env.Unavailable).To(ConsistOf("bufferpool_size"))
+	})
+	It("should fail when a required query fails", func() {
+		client := fake.NewClient()
+		client.Script.On("version()").Fail(errors.New("connection lost"))
+		pool, err := client.Pool(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		conn, err := pool.Connect(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		env := environment.Environment{}
+		Expect(env.Describe(ctx, conn, environment.QueriesFor(dbclient.Postgres))).To(HaveOccurred())
+	})
+	It("should describe an SQLite database", func() {
+		client := sqlite.NewClient(sqlite.ConnParams{Path: filepath.Join(GinkgoT().TempDir(), "env.sqlite"),
+			BusyTimeout: 1000})
+		pool, err := client.Pool(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		conn, err := pool.Connect(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { _ = conn.Close(ctx) }()
+		env := environment.Environment{}
+		Expect(env.Describe(ctx, conn, environment.QueriesFor(dbclient.SQLite))).To(Succeed())
+		Expect(env.Server["version"]).To(HaveKey("version"))
+		Expect(env.Server["pragmas"]).To(HaveKeyWithValue("journal_mode", "wal"))
+		Expect(env.Server["pragmas"]).To(HaveKeyWithValue("busy_timeout", "1000"))
+	})
+})
@@ -0,0 +1,133 @@
+// Package environment describes where a test ran: the version and the settings of the database server, and the
+// client dbtwool ran on. It is added to the result document, so that results can be reproduced and compared later.
+package environment
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/pgvillage-tools/dbtwool/internal/version"
+	"github.com/pgvillage-tools/dbtwool/pkg/dbclient"
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+	"github.com/pgvillage-tools/dbtwool/pkg/logging"
+	"github.com/pgvillage-tools/dbtwool/pkg/sqlutil"
+)
+
+var logger = logging.Logger()
+
+// Settings holds values by group and name, e.g. pg_settings, shared_buffers, 128MB
+type Settings map[string]map[string]string
+
+func (s Settings) set(group string, name string, value string) {
+	if s[group] == nil {
+		s[group] = map[string]string{}
+	}
+	s[group][name] = value
+}
+
+// Query describes a part of the server. Every row becomes a group, which is named after Name and the values of the
+// Keys columns (e.g. bufferpools:IBMDEFAULTBP), and all other columns become values of the group.
+type Query struct {
+	Name string
+	SQL  string
+	Keys []string
+	// Pairs queries return name and value columns instead, which become the values of one group (e.g. pg_settings)
+	Pairs bool
+	// Optional queries depend on the server version or privileges. When they fail, they are listed as unavailable.
+	Optional bool
+}
+
+// Environment is the context of a test run
+type Environment struct {
+	Version   string `json:"dbtwool_version"`
+	Hostname  string `json:"client_hostname"`
+	GoVersion string `json:"go_version"`
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+	CPUs      int    `json:"cpus"`
+	// Server holds the results of the queries for the RDBMS (version, settings, buffer pools, etc.)
+	Server Settings `json:"server,omitempty"`
+	// Unavailable lists the optional queries which could not be run
+	Unavailable []string `json:"unavailable,omitempty"`
+}
+
+// Client returns the environment of the client, which is known without a connection
+func Client() Environment {
+	hostname, err := os.Hostname()
+	if err != nil {
+		logger.Warn().Err(err).Msg("Could not determine the hostname of the client")
+	}
+	return Environment{
+		Version:   version.GetAppVersion(),
+		Hostname:  hostname,
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		CPUs:      runtime.NumCPU(),
+	}
+}
+
+// QueriesFor returns the queries which describe the server of an RDBMS
+func QueriesFor(dbType dbclient.RDBMS) []Query {
+	switch dbType {
+	case dbclient.Postgres:
+		return PostgresQueries()
+	case dbclient.DB2:
+		return DB2Queries()
+	case dbclient.SQLite:
+		return SQLiteQueries()
+	default:
+		return nil
+	}
+}
+
+// Describe runs queries on conn, and adds the results to the Server settings. Optional queries which fail are
+// listed as unavailable, other failures are returned.
+func (e *Environment) Describe(ctx context.Context, conn dbinterface.Connection, queries []Query) error {
+	if e.Server == nil {
+		e.Server = Settings{}
+	}
+	for _, query := range queries {
+		rows, err := conn.Query(ctx, query.SQL)
+		if err != nil {
+			if query.Optional && ctx.Err() == nil {
+				logger.Info().Err(err).Str("query", query.Name).Msg("Server setting is not available, skipping")
+				e.Unavailable = append(e.Unavailable, query.Name)
+				continue
+			}
+			return fmt.Errorf("failed to query %s: %w", query.Name, err)
+		}
+		for _, row := range rows {
+			if query.Pairs {
+				e.Server.set(query.Name, toString(row["name"]), toString(row["value"]))
+				continue
+			}
+			group := sqlutil.GroupName(query.Name, query.Keys, row)
+			for column, value := range row {
+				if !sqlutil.IsKey(query.Keys, column) && value != nil {
+					e.Server.set(group, column, toString(value))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func toString(value any) string {
+	return strings.TrimSpace(fmt.Sprint(value))
+}
+
+func quoteAll(values []string) []string {
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, sqlutil.Quote(value))
+	}
+	return quoted
+}
+
+func join(values []string) string {
+	return strings.Join(values, ", ")
+}
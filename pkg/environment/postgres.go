@@ -0,0 +1,35 @@
+package environment
+
+// postgresSettings are the settings which matter most for the results of the tests
+var postgresSettings = []string{
+	"shared_buffers", "effective_cache_size", "work_mem", "maintenance_work_mem", "huge_pages",
+	"default_toast_compression", "wal_level", "wal_buffers", "wal_compression", "synchronous_commit", "fsync",
+	"full_page_writes", "max_wal_size", "checkpoint_timeout", "checkpoint_completion_target", "max_connections",
+	"max_parallel_workers_per_gather", "random_page_cost", "effective_io_concurrency", "jit",
+	"default_transaction_isolation",
+}
+
+// PostgresQueries returns the queries which describe a PostgreSQL server: the version, the key settings, and all
+// settings which were changed from their defaults
+func PostgresQueries() []Query {
+	return []Query{
+		{
+			Name: "version",
+			SQL: `SELECT version() AS version, current_setting('server_version_num') AS server_version_num,
+  current_database() AS database, pg_is_in_recovery() AS in_recovery, pg_postmaster_start_time() AS started_at`,
+		},
+		{
+			Name: "pg_settings",
+			SQL: `SELECT name, current_setting(name) AS value FROM pg_settings
+WHERE name IN (` + join(quoteAll(postgresSettings)) + `)`,
+			Pairs: true,
+		},
+		{
+			Name: "pg_settings_changed",
+			SQL: `SELECT name, current_setting(name) AS value FROM pg_settings
+WHERE source NOT IN ('default', 'override')`,
+			Pairs:    true,
+			Optional: true,
+		},
+	}
+}
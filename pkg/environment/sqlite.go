@@ -0,0 +1,21 @@
+package environment
+
+// SQLiteQueries returns the queries which describe an embedded SQLite database: the library version and the pragmas
+// which matter most for the results of the tests
+func SQLiteQueries() []Query {
+	return []Query{
+		{
+			Name: "version",
+			SQL:  `SELECT sqlite_version() AS version`,
+		},
+		{
+			Name: "pragmas",
+			SQL: `SELECT 'journal_mode' AS name, journal_mode AS value FROM pragma_journal_mode()
+UNION ALL SELECT 'synchronous', synchronous FROM pragma_synchronous()
+UNION ALL SELECT 'page_size', page_size FROM pragma_page_size()
+UNION ALL SELECT 'cache_size', cache_size FROM pragma_cache_size()
+UNION ALL SELECT 'busy_timeout', timeout FROM pragma_busy_timeout()`,
+			Pairs: true,
+		},
+	}
+}
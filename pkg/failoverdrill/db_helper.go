@@ -0,0 +1,108 @@
+package failoverdrill
+
+import (
+	"fmt"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbclient"
+)
+
+// DBHelper is an interface to help returning queries for a specific RDBMS type
+type DBHelper interface {
+	CreateSchemaSQL() string
+	CreateTableSQL() string
+	TruncateSQL() string
+	InsertHeartbeatSQL() string
+	ReadHeartbeatSQL() string
+	CountHeartbeatsSQL() string
+}
+
+// newDBHelper returns the helper for an RDBMS type
+func newDBHelper(dbType dbclient.RDBMS, schema, table string) DBHelper {
+	if dbType == dbclient.DB2 {
+		return DB2Helper{schemaName: schema, tableName: table}
+	}
+	return PGHelper{schemaName: schema, tableName: table}
+}
+
+// PGHelper is a helper for generating queries for PostgreSQL
+type PGHelper struct {
+	schemaName string
+	tableName  string
+}
+
+// CreateSchemaSQL returns a schema query
+func (helper PGHelper) CreateSchemaSQL() string {
+	return fmt.Sprintf(`CREATE SCHEMA IF NOT EXISTS %v`, helper.schemaName)
+}
+
+// CreateTableSQL returns a CREATE TABLE query for the heartbeat table
+func (helper PGHelper) CreateTableSQL() string {
+	return fmt.Sprintf(`
+CREATE TABLE IF NOT EXISTS %v.%v (
+    seq        BIGINT NOT NULL,
+    worker     INTEGER NOT NULL,
+    written_at TIMESTAMPTZ NOT NULL DEFAULT now()
+)`, helper.schemaName, helper.tableName)
+}
+
+// TruncateSQL returns a query which removes the heartbeats of a previous run
+func (helper PGHelper) TruncateSQL() string {
+	return fmt.Sprintf(`DELETE FROM %v.%v`, helper.schemaName, helper.tableName)
+}
+
+// InsertHeartbeatSQL returns the query which writes one heartbeat, with the sequence and worker as parameters
+func (helper PGHelper) InsertHeartbeatSQL() string {
+	return fmt.Sprintf(`INSERT INTO %v.%v (seq, worker) VALUES ($1, $2)`, helper.schemaName, helper.tableName)
+}
+
+// ReadHeartbeatSQL returns the query which reads back one heartbeat by its sequence
+func (helper PGHelper) ReadHeartbeatSQL() string {
+	return fmt.Sprintf(`SELECT COUNT(*) AS cnt FROM %v.%v WHERE seq = $1`, helper.schemaName, helper.tableName)
+}
+
+// CountHeartbeatsSQL returns the query which counts the rows per sequence, for the verification
+func (helper PGHelper) CountHeartbeatsSQL() string {
+	return fmt.Sprintf(`SELECT seq, COUNT(*) AS cnt FROM %v.%v GROUP BY seq`, helper.schemaName, helper.tableName)
+}
+
+// DB2Helper is a helper for generating queries for DB2
+type DB2Helper struct {
+	schemaName string
+	tableName  string
+}
+
+// CreateSchemaSQL returns a schema query. It fails when the schema exists, which is ignored.
+func (helper DB2Helper) CreateSchemaSQL() string {
+	return fmt.Sprintf(`CREATE SCHEMA %v`, helper.schemaName)
+}
+
+// CreateTableSQL returns a CREATE TABLE query for the heartbeat table. It fails when the table exists, which is
+// ignored.
+func (helper DB2Helper) CreateTableSQL() string {
+	return fmt.Sprintf(`
+CREATE TABLE %v.%v (
+  SEQ        BIGINT NOT NULL,
+  WORKER     INTEGER NOT NULL,
+  WRITTEN_AT TIMESTAMP NOT NULL DEFAULT CURRENT TIMESTAMP
+)`, helper.schemaName, helper.tableName)
+}
+
+// TruncateSQL returns a query which removes the heartbeats of a previous run
+func (helper DB2Helper) TruncateSQL() string {
+	return fmt.Sprintf(`DELETE FROM %v.%v`, helper.schemaName, helper.tableName)
+}
+
+// InsertHeartbeatSQL returns the query which writes one heartbeat, with the sequence and worker as parameters
+func (helper DB2Helper) InsertHeartbeatSQL() string {
+	return fmt.Sprintf(`INSERT INTO %v.%v (seq, worker) VALUES (?, ?)`, helper.schemaName, helper.tableName)
+}
+
+// ReadHeartbeatSQL returns the query which reads back one heartbeat by its sequence
+func (helper DB2Helper) ReadHeartbeatSQL() string {
+	return fmt.Sprintf(`SELECT COUNT(*) AS cnt FROM %v.%v WHERE seq = ?`, helper.schemaName, helper.tableName)
+}
+
+// CountHeartbeatsSQL returns the query which counts the rows per sequence, for the verification
+func (helper DB2Helper) CountHeartbeatsSQL() string {
+	return fmt.Sprintf(`SELECT seq, COUNT(*) AS cnt FROM %v.%v GROUP BY seq`, helper.schemaName, helper.tableName)
+}
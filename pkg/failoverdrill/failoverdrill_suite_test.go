@@ -0,0 +1,13 @@
+package failoverdrill_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestFailoverdrill(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Failoverdrill Suite")
+}
@@ -0,0 +1,23 @@
+// Package failoverdrill measures what a failover (or switchover) means for clients. Workers write a heartbeat (a row
+// with a monotonic sequence number) and read it back on a fixed interval, and reconnect when their connection is
+// lost. After the run the downtime windows are reported, with the time it took until writes succeeded again, and the
+// heartbeat table is checked for acknowledged writes which were lost or duplicated.
+package failoverdrill
+
+import (
+	"time"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/logging"
+)
+
+var logger = logging.Logger()
+
+const (
+	defaultExecutionTime = 60
+	defaultInterval      = 100 * time.Millisecond
+	connectTimeout       = 60 * time.Second
+	// verifyTimeout limits how long the drill waits for the database to come back for the verification
+	verifyTimeout = 5 * time.Minute
+	decimalSystem = 10
+	bitSize64     = 64
+)
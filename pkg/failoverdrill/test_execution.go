@@ -0,0 +1,475 @@
+package failoverdrill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbclient"
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+	"github.com/pgvillage-tools/dbtwool/pkg/testrunner"
+)
+
+// maxLoggedSeqs limits the number of lost or duplicated sequences which are logged
+const maxLoggedSeqs = 20
+
+// ExecuteTest (re)creates the heartbeat table and writes heartbeats on parallel workers for executionTime seconds,
+// while the failover is triggered from outside. Workers always reconnect, with the backoff of reconnect, and never
+// stop on errors. Afterwards the downtime windows and the verification of the heartbeat table are reported.
+// An error is returned when acknowledged writes were lost or duplicated, or when no heartbeat was written at all.
+func ExecuteTest(
+	ctx context.Context,
+	dbType dbclient.RDBMS,
+	client dbinterface.Client,
+	schemaName string,
+	tableName string,
+	parallel int,
+	executionTime int,
+	interval time.Duration,
+	reconnect testrunner.ReconnectPolicy,
+) error {
+	logger := logger.With().Int("parallel", parallel).Dur("interval", interval).Logger()
+	helper := newDBHelper(dbType, schemaName, tableName)
+	result, err := run(ctx, helper, client, parallel, executionTime, interval, reconnect)
+	if err != nil {
+		return err
+	}
+	logResults(logger, result)
+	return result.check()
+}
+
+// window is a period in which at least one worker could not write
+type window struct {
+	start time.Time
+	// duration is the time until all workers wrote again, or until the end of the run
+	duration time.Duration
+	// firstWrite is the time until the first worker wrote again. It is only set when recovered.
+	firstWrite time.Duration
+	recovered  bool
+	// failedWrites is the number of heartbeats which failed in the window
+	failedWrites int
+}
+
+// drillResult holds the results of a drill
+type drillResult struct {
+	// writes is the number of acknowledged (committed) heartbeats
+	writes         int64
+	failedWrites   int64
+	errorsByClass  map[dbinterface.ErrorClass]int64
+	readMismatches int64
+	reconnects     int
+	windows        []window
+	// lost holds the sequences which were acknowledged, but are missing
+	lost []int64
+	// duplicated holds the sequences which are present more than once
+	duplicated []int64
+	// inDoubt holds the sequences which are present, but for which the commit failed (e.a. the connection was lost
+	// while committing). These are not an error.
+	inDoubt []int64
+}
+
+// check returns an error when writes were lost or duplicated, or when no write succeeded
+func (r drillResult) check() error {
+	if r.writes == 0 {
+		return errors.New("no heartbeat could be written during the drill")
+	}
+	if len(r.lost) > 0 || len(r.duplicated) > 0 {
+		return fmt.Errorf("%d acknowledged writes were lost and %d were duplicated", len(r.lost), len(r.duplicated))
+	}
+	return nil
+}
+
+func run(
+	ctx context.Context,
+	helper DBHelper,
+	client dbinterface.Client,
+	parallel int,
+	executionTime int,
+	interval time.Duration,
+	reconnect testrunner.ReconnectPolicy,
+) (drillResult, error) {
+	if parallel <= 0 {
+		return drillResult{}, errors.New("parallel must be > 0")
+	}
+	if executionTime <= 0 {
+		executionTime = defaultExecutionTime
+	}
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	if err := dbinterface.EnsurePoolSize(client, parallel); err != nil {
+		return drillResult{}, err
+	}
+	pool, err := client.Pool(ctx)
+	if err != nil {
+		return drillResult{}, fmt.Errorf("failed to init pool: %w", err)
+	}
+	if err = prepare(ctx, pool, helper); err != nil {
+		return drillResult{}, err
+	}
+
+	logger.Info().Msgf("Acquiring %v connections from pool.", parallel)
+	conns, err := testrunner.OpenConns(ctx, pool, parallel, connectTimeout)
+	if err != nil {
+		return drillResult{}, err
+	}
+	// the drill is about surviving a failover, so workers always reconnect
+	reconnect.Enabled = true
+	d := &drill{
+		helper:        helper,
+		interval:      interval,
+		reconnector:   testrunner.NewReconnector(reconnect, pool, nil),
+		acked:         map[int64]bool{},
+		errorsByClass: map[dbinterface.ErrorClass]int64{},
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, time.Duration(executionTime)*time.Second)
+	logger.Info().Msg("Starting heartbeat workers. Trigger the failover now.")
+	var wg sync.WaitGroup
+	for workerID := range parallel {
+		// a replaced connection is stored in conns, so that it is closed at the end
+		wg.Go(func() { d.worker(runCtx, workerID, &conns[workerID]) })
+	}
+	wg.Wait()
+	cancel()
+	testrunner.CloseAll(ctx, conns)
+
+	result := d.result()
+	logger.Info().Msg("Verifying the heartbeat table.")
+	if err = d.verify(ctx, pool, reconnect, &result); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// prepare creates the heartbeat table when it does not exist yet, and removes the heartbeats of a previous run
+func prepare(ctx context.Context, pool dbinterface.Pool, helper DBHelper) error {
+	connectCtx, cancel := context.WithTimeout(ctx, connectTimeout)
+	defer cancel()
+	conn, err := pool.Connect(connectCtx)
+	if err != nil {
+		return fmt.Errorf("connect error: %w", err)
+	}
+	defer func() { _ = conn.Close(ctx) }()
+	for _, createSQL := range []string{helper.CreateSchemaSQL(), helper.CreateTableSQL()} {
+		// DB2 cannot create objects only when they do not exist, so these errors are expected
+		if err = execInTx(ctx, conn, createSQL); err != nil {
+			logger.Debug().Err(err).Msg("Error while creating the heartbeat table, which might already exist")
+		}
+	}
+	if err = execInTx(ctx, conn, helper.TruncateSQL()); err != nil {
+		return fmt.Errorf("failed to clean the heartbeat table: %w", err)
+	}
+	return nil
+}
+
+func execInTx(ctx context.Context, conn dbinterface.Connection, sql string) error {
+	if err := conn.Begin(ctx); err != nil {
+		return err
+	}
+	if _, err := conn.Execute(ctx, sql); err != nil {
+		_ = conn.Rollback(ctx)
+		return err
+	}
+	return conn.Commit(ctx)
+}
+
+// drill holds the state which is shared by all workers
+type drill struct {
+	helper      DBHelper
+	interval    time.Duration
+	reconnector *testrunner.Reconnector
+	seq         atomic.Int64
+
+	writes         atomic.Int64
+	failedWrites   atomic.Int64
+	readMismatches atomic.Int64
+
+	mutex         sync.Mutex
+	acked         map[int64]bool
+	errorsByClass map[dbinterface.ErrorClass]int64
+	// writeOutages run from the first failed heartbeat of a worker until its next successful one
+	writeOutages testrunner.Outages
+	// recoveries are the moments workers wrote again after an outage
+	recoveries []time.Time
+	// unresolved are the starts of outages which lasted until the end of the run
+	unresolved []time.Time
+}
+
+// worker writes and reads back a heartbeat every interval, until ctx is done
+func (d *drill) worker(ctx context.Context, workerID int, conn *dbinterface.Connection) {
+	var outage *testrunner.Outage
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for ctx.Err() == nil {
+		start := time.Now()
+		seq := d.seq.Add(1)
+		err := d.write(ctx, *conn, seq, workerID)
+		if err == nil {
+			d.ack(seq)
+			err = d.read(ctx, *conn, seq)
+		}
+		switch {
+		case err == nil:
+			if outage != nil {
+				outage.End = time.Now()
+				d.recovered(*outage)
+				outage = nil
+			}
+		case ctx.Err() != nil:
+			// the run is over, a heartbeat which was interrupted does not count
+		default:
+			d.fail(dbinterface.ClassifyError(*conn, err))
+			if outage == nil {
+				logger.Warn().Err(err).Int("worker", workerID).Msg("Heartbeat failed")
+				outage = &testrunner.Outage{WorkerID: workerID, Start: start}
+			}
+			outage.Attempts++
+			if d.reconnector.Recover(ctx, workerID, conn, err) {
+				// reconnecting took its own time, so the next heartbeat is written right away
+				continue
+			}
+		}
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+		}
+	}
+	if outage != nil {
+		outage.End = time.Now()
+		d.mutex.Lock()
+		d.unresolved = append(d.unresolved, outage.Start)
+		d.mutex.Unlock()
+		d.writeOutages.Add(*outage)
+	}
+}
+
+func (d *drill) write(ctx context.Context, conn dbinterface.Connection, seq int64, workerID int) error {
+	if err := conn.Begin(ctx); err != nil {
+		return err
+	}
+	if _, err := conn.Execute(ctx, d.helper.InsertHeartbeatSQL(), seq, workerID); err != nil {
+		_ = conn.Rollback(ctx)
+		return err
+	}
+	return conn.Commit(ctx)
+}
+
+func (d *drill) read(ctx context.Context, conn dbinterface.Connection, seq int64) error {
+	rows, err := conn.Query(ctx, d.helper.ReadHeartbeatSQL(), seq)
+	if err != nil {
+		return err
+	}
+	var count int64
+	if len(rows) > 0 {
+		if count, err = toInt64(rows[0]["cnt"]); err != nil {
+			return err
+		}
+	}
+	if count != 1 {
+		d.readMismatches.Add(1)
+		logger.Warn().Int64("seq", seq).Int64("count", count).Msg("Heartbeat was not read back after it was written")
+	}
+	return nil
+}
+
+func (d *drill) ack(seq int64) {
+	d.writes.Add(1)
+	d.mutex.Lock()
+	d.acked[seq] = true
+	d.mutex.Unlock()
+}
+
+func (d *drill) fail(class dbinterface.ErrorClass) {
+	d.failedWrites.Add(1)
+	d.mutex.Lock()
+	d.errorsByClass[class]++
+	d.mutex.Unlock()
+}
+
+func (d *drill) recovered(outage testrunner.Outage) {
+	d.mutex.Lock()
+	d.recoveries = append(d.recoveries, outage.End)
+	d.mutex.Unlock()
+	d.writeOutages.Add(outage)
+	logger.Info().Int("worker", outage.WorkerID).Dur("downtime", outage.Duration()).Msg("Heartbeat written again")
+}
+
+// result returns the results of the run, before verification
+func (d *drill) result() drillResult {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	result := drillResult{
+		writes:         d.writes.Load(),
+		failedWrites:   d.failedWrites.Load(),
+		readMismatches: d.readMismatches.Load(),
+		reconnects:     d.reconnector.Outages().Reconnects(),
+		errorsByClass:  map[dbinterface.ErrorClass]int64{},
+	}
+	for class, count := range d.errorsByClass {
+		result.errorsByClass[class] = count
+	}
+	for _, outageWindow := range d.writeOutages.Windows() {
+		w := window{
+			start:        outageWindow.Start,
+			duration:     outageWindow.Duration(),
+			failedWrites: outageWindow.Attempts,
+			recovered:    true,
+		}
+		for _, start := range d.unresolved {
+			if !start.Before(outageWindow.Start) && !start.After(outageWindow.End) {
+				w.recovered = false
+			}
+		}
+		for _, recovery := range d.recoveries {
+			if recovery.Before(outageWindow.Start) || recovery.After(outageWindow.End) {
+				continue
+			}
+			if firstWrite := recovery.Sub(outageWindow.Start); w.firstWrite == 0 || firstWrite < w.firstWrite {
+				w.firstWrite = firstWrite
+			}
+		}
+		result.windows = append(result.windows, w)
+	}
+	return result
+}
+
+// verify compares the heartbeat table with the acknowledged writes. The database might still be recovering, so
+// connecting is retried with the reconnect backoff.
+func (d *drill) verify(
+	ctx context.Context,
+	pool dbinterface.Pool,
+	reconnect testrunner.ReconnectPolicy,
+	result *drillResult,
+) error {
+	conn, err := connectWithRetry(ctx, pool, reconnect)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close(ctx) }()
+	rows, err := conn.Query(ctx, d.helper.CountHeartbeatsSQL())
+	if err != nil {
+		return fmt.Errorf("failed to read the heartbeat table: %w", err)
+	}
+	present := make(map[int64]int64, len(rows))
+	for _, row := range rows {
+		seq, seqErr := toInt64(row["seq"])
+		if seqErr != nil {
+			return seqErr
+		}
+		count, countErr := toInt64(row["cnt"])
+		if countErr != nil {
+			return countErr
+		}
+		present[seq] = count
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	for seq := range d.acked {
+		if present[seq] == 0 {
+			result.lost = append(result.lost, seq)
+		}
+	}
+	for seq, count := range present {
+		if count > 1 {
+			result.duplicated = append(result.duplicated, seq)
+		}
+		if !d.acked[seq] {
+			result.inDoubt = append(result.inDoubt, seq)
+		}
+	}
+	for _, seqs := range [][]int64{result.lost, result.duplicated, result.inDoubt} {
+		sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	}
+	return nil
+}
+
+func connectWithRetry(
+	ctx context.Context,
+	pool dbinterface.Pool,
+	policy testrunner.ReconnectPolicy,
+) (dbinterface.Connection, error) {
+	deadline := time.Now().Add(verifyTimeout)
+	backoff := max(policy.Backoff, defaultInterval)
+	for {
+		connectCtx, cancel := context.WithTimeout(ctx, connectTimeout)
+		conn, err := pool.Connect(connectCtx)
+		cancel()
+		if err == nil {
+			return conn, nil
+		}
+		if time.Now().Add(backoff).After(deadline) {
+			return nil, fmt.Errorf("failed to connect for the verification of the heartbeat table: %w", err)
+		}
+		logger.Warn().Err(err).Msg("Connect for the verification failed, retrying")
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, max(policy.MaxBackoff, backoff))
+	}
+}
+
+func toInt64(value any) (int64, error) {
+	switch v := value.(type) {
+	case int64:
+		return v, nil
+	case int32:
+		return int64(v), nil
+	case int:
+		return int64(v), nil
+	case string:
+		return strconv.ParseInt(v, decimalSystem, bitSize64)
+	default:
+		return 0, fmt.Errorf("unexpected number type %T (%v)", value, value)
+	}
+}
+
+func logResults(logger zerolog.Logger, result drillResult) {
+	var downtime time.Duration
+	for i, w := range result.windows {
+		downtime += w.duration
+		event := logger.Info().
+			Int("window", i+1).
+			Time("start", w.start).
+			Dur("downtime", w.duration).
+			Int("failed_writes", w.failedWrites).
+			Bool("recovered", w.recovered)
+		if w.firstWrite > 0 {
+			event = event.Dur("first_write_after", w.firstWrite)
+		}
+		event.Msg("Downtime window")
+	}
+	if len(result.lost) > 0 {
+		logger.Error().Int("count", len(result.lost)).Ints64("seqs", firstSeqs(result.lost)).
+			Msg("Acknowledged writes were lost")
+	}
+	if len(result.duplicated) > 0 {
+		logger.Error().Int("count", len(result.duplicated)).Ints64("seqs", firstSeqs(result.duplicated)).
+			Msg("Writes were duplicated")
+	}
+	logger.Info().
+		Int64("writes", result.writes).
+		Int64("failed_writes", result.failedWrites).
+		Interface("errors_by_class", result.errorsByClass).
+		Int64("read_mismatches", result.readMismatches).
+		Int("reconnects", result.reconnects).
+		Int("downtime_windows", len(result.windows)).
+		Dur("downtime", downtime).
+		Int("lost", len(result.lost)).
+		Int("duplicated", len(result.duplicated)).
+		Int("unacknowledged_present", len(result.inDoubt)).
+		Msg("Failover drill finished")
+}
+
+func firstSeqs(seqs []int64) []int64 {
+	return seqs[:min(len(seqs), maxLoggedSeqs)]
+}
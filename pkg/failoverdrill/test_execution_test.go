@@ -0,0 +1,104 @@
+package failoverdrill
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbclient"
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface/fake"
+	"github.com/pgvillage-tools/dbtwool/pkg/testrunner"
+)
+
+// heartbeats returns the rows of the verification query for the sequences 1 to n, each counted once
+func heartbeats(n int64) []map[string]any {
+	rows := make([]map[string]any, 0, n)
+	for seq := int64(1); seq <= n; seq++ {
+		rows = append(rows, map[string]any{"seq": seq, "cnt": int64(1)})
+	}
+	return rows
+}
+
+var _ = Describe("ExecuteTest", func() {
+	ctx := context.Background()
+	helper := newDBHelper(dbclient.Postgres, "dbtwooltests", "heartbeat")
+	policy := testrunner.ReconnectPolicy{Backoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	var client *fake.Client
+	BeforeEach(func() {
+		client = fake.NewClient()
+		client.Script.On("WHERE seq =").Return(map[string]any{"cnt": int64(1)})
+	})
+
+	It("should write and read back heartbeats, and find nothing lost", func() {
+		client.Script.On("GROUP BY seq").Return(heartbeats(100000)...)
+		result, err := run(ctx, helper, client, 2, 1, 10*time.Millisecond, policy)
+		Ω(err).NotTo(HaveOccurred())
+		Ω(result.writes).To(BeNumerically(">", 10))
+		Ω(result.windows).To(BeEmpty())
+		Ω(result.lost).To(BeEmpty())
+		Ω(result.readMismatches).To(BeZero())
+		Ω(result.check()).To(Succeed())
+		Ω(client.Script.Count("DELETE FROM dbtwooltests.heartbeat")).To(Equal(1))
+		Ω(client.Script.Count("INSERT INTO dbtwooltests.heartbeat")).To(BeNumerically(">=", result.writes))
+	})
+	It("should report acknowledged writes which are missing, and duplicates", func() {
+		client.Script.On("GROUP BY seq").Return(map[string]any{"seq": int64(1), "cnt": int64(2)})
+		result, err := run(ctx, helper, client, 1, 1, 10*time.Millisecond, policy)
+		Ω(err).NotTo(HaveOccurred())
+		Ω(result.duplicated).To(Equal([]int64{1}))
+		Ω(result.lost).To(HaveLen(int(result.writes) - 1))
+		Ω(result.check()).To(MatchError(ContainSubstring("were lost and 1 were duplicated")))
+	})
+	It("should reconnect and measure the downtime window", func() {
+		client.Script.On("INSERT INTO").After(10).Times(5).
+			Fail(fake.NewError(dbinterface.ErrorClassConnectionLost, "server closed the connection"))
+		client.Script.On("GROUP BY seq").Return(heartbeats(100000)...)
+		result, err := run(ctx, helper, client, 1, 1, 10*time.Millisecond, policy)
+		Ω(err).NotTo(HaveOccurred())
+		Ω(result.reconnects).To(Equal(5))
+		Ω(result.errorsByClass).To(HaveKeyWithValue(dbinterface.ErrorClassConnectionLost, int64(5)))
+		Ω(result.windows).To(HaveLen(1))
+		Ω(result.windows[0].recovered).To(BeTrue())
+		Ω(result.windows[0].failedWrites).To(Equal(5))
+		Ω(result.windows[0].firstWrite).To(BeNumerically(">", 0))
+		Ω(result.windows[0].firstWrite).To(BeNumerically("<=", result.windows[0].duration))
+	})
+	It("should report a window without recovery when writes keep failing", func() {
+		client.Script.On("INSERT INTO").After(3).Fail(fake.NewError(dbinterface.ErrorClassOther, "read-only"))
+		client.Script.On("GROUP BY seq").Return(heartbeats(3)...)
+		result, err := run(ctx, helper, client, 1, 1, 10*time.Millisecond, policy)
+		Ω(err).NotTo(HaveOccurred())
+		Ω(result.writes).To(Equal(int64(3)))
+		Ω(result.windows).To(HaveLen(1))
+		Ω(result.windows[0].recovered).To(BeFalse())
+		Ω(result.check()).To(Succeed())
+	})
+	It("should fail when no heartbeat could be written", func() {
+		client.Script.On("INSERT INTO").Fail(fake.NewError(dbinterface.ErrorClassOther, "read-only"))
+		result, err := run(ctx, helper, client, 1, 1, 10*time.Millisecond, policy)
+		Ω(err).NotTo(HaveOccurred())
+		Ω(result.check()).To(MatchError(ContainSubstring("no heartbeat")))
+	})
+	It("should use parameter markers for DB2", func() {
+		db2Helper := newDBHelper(dbclient.DB2, "dbtwooltests", "heartbeat")
+		Ω(db2Helper.InsertHeartbeatSQL()).To(ContainSubstring("VALUES (?, ?)"))
+		Ω(helper.InsertHeartbeatSQL()).To(ContainSubstring("VALUES ($1, $2)"))
+	})
+	It("should run the drill with the DB2 queries", func() {
+		client.Script.On("INSERT INTO").After(10).Times(2).
+			Fail(fake.NewError(dbinterface.ErrorClassConnectionLost, "SQL30081N"))
+		client.Script.On("GROUP BY seq").Return(heartbeats(100000)...)
+		Ω(ExecuteTest(ctx, dbclient.DB2, client, "dbtwooltests", "heartbeat", 1, 1, 10*time.Millisecond, policy)).
+			To(Succeed())
+		Ω(client.Script.Count("CREATE TABLE dbtwooltests.heartbeat")).To(Equal(1))
+		Ω(client.Script.Count("WHERE seq = ?")).To(BeNumerically(">", 0))
+	})
+	It("should return an error when writes were lost or duplicated", func() {
+		client.Script.On("GROUP BY seq").Return(map[string]any{"seq": int64(1), "cnt": int64(2)})
+		Ω(ExecuteTest(ctx, dbclient.Postgres, client, "dbtwooltests", "heartbeat", 1, 1, 10*time.Millisecond,
+			policy)).To(MatchError(ContainSubstring("were duplicated")))
+	})
+})
@@ -0,0 +1,52 @@
+package history
+
+// dialect holds the statements of the history store for one RDBMS. The statements use $1, $2, etc. as
+// placeholders, which both PostgreSQL and SQLite understand.
+type dialect struct {
+	create []string
+	insert string
+	list   string
+	get    string
+	delete string
+}
+
+// postgresDialect stores the history in the dbtwool_results schema, with the document as jsonb so that it can be
+// queried with SQL
+var postgresDialect = dialect{
+	create: []string{
+		`CREATE SCHEMA IF NOT EXISTS dbtwool_results`,
+		`CREATE TABLE IF NOT EXISTS dbtwool_results.runs (
+			id bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+			test text NOT NULL,
+			rdbms text NOT NULL,
+			started_at timestamptz,
+			finished_at timestamptz,
+			saved_at timestamptz NOT NULL DEFAULT now(),
+			document jsonb NOT NULL)`,
+	},
+	insert: `INSERT INTO dbtwool_results.runs (test, rdbms, started_at, finished_at, document)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+	list: `SELECT id, document::text AS document FROM dbtwool_results.runs
+		WHERE $1 = '' OR test = $1 ORDER BY id`,
+	get:    `SELECT document::text AS document FROM dbtwool_results.runs WHERE id = $1`,
+	delete: `DELETE FROM dbtwool_results.runs WHERE id = $1 RETURNING id`,
+}
+
+// sqliteDialect stores the history in the runs table of a database file, which is meant for the history only
+var sqliteDialect = dialect{
+	create: []string{
+		`CREATE TABLE IF NOT EXISTS runs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			test TEXT NOT NULL,
+			rdbms TEXT NOT NULL,
+			started_at TIMESTAMP,
+			finished_at TIMESTAMP,
+			saved_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			document TEXT NOT NULL)`,
+	},
+	insert: `INSERT INTO runs (test, rdbms, started_at, finished_at, document)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+	list:   `SELECT id, document FROM runs WHERE $1 = '' OR test = $1 ORDER BY id`,
+	get:    `SELECT document FROM runs WHERE id = $1`,
+	delete: `DELETE FROM runs WHERE id = $1 RETURNING id`,
+}
@@ -0,0 +1,94 @@
+package history_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface/fake"
+	"github.com/pgvillage-tools/dbtwool/pkg/history"
+	"github.com/pgvillage-tools/dbtwool/pkg/result"
+)
+
+var _ = Describe("PostgreSQL dialect", func() {
+	var (
+		ctx    context.Context
+		client *fake.Client
+	)
+	BeforeEach(func() {
+		ctx = context.Background()
+		client = fake.NewClient()
+	})
+
+	It("should create the schema and table in a transaction", func() {
+		store, err := history.OpenPostgres(ctx, client)
+		Expect(err).NotTo(HaveOccurred())
+		statements := client.Script.Statements()
+		Expect(statements).To(HaveLen(5))
+		Expect(statements[1]).To(Equal(fake.StmtBegin))
+		Expect(statements[2]).To(Equal("CREATE SCHEMA IF NOT EXISTS DBTWOOL_RESULTS"))
+		Expect(statements[3]).To(HavePrefix("CREATE TABLE IF NOT EXISTS DBTWOOL_RESULTS.RUNS"))
+		Expect(statements[4]).To(Equal(fake.StmtCommit))
+		for _, call := range client.Script.Calls() {
+			if call.SQL != fake.StmtConnect && call.SQL != fake.StmtBegin {
+				Expect(call.InTx).To(BeTrue(), call.SQL)
+			}
+		}
+		Expect(store.Close(ctx)).To(Succeed())
+	})
+	It("should roll back and close when the history can not be created", func() {
+		client.Script.On("CREATE TABLE").Fail(errors.New("permission denied for database"))
+		_, err := history.OpenPostgres(ctx, client)
+		Expect(err).To(MatchError(ContainSubstring("permission denied")))
+		Expect(client.Script.Count(fake.StmtRollback)).To(Equal(1))
+		Expect(client.Script.Count(fake.StmtCommit)).To(BeZero())
+		Expect(client.Script.Count(fake.StmtClose)).To(Equal(1))
+	})
+	It("should save and read runs with the jsonb document", func() {
+		client.Script.On("INSERT INTO dbtwool_results.runs").Return(map[string]any{"id": int64(7)})
+		client.Script.On("SELECT document::text AS document FROM dbtwool_results.runs WHERE id").
+			Return(map[string]any{"document": `{"test": "lob-performance", "rdbms": "pg"}`})
+		store, err := history.OpenPostgres(ctx, client)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(func() { Expect(store.Close(ctx)).To(Succeed()) })
+
+		started := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+		id, err := store.Save(ctx, result.Document{Test: "lob-performance", RDBMS: "pg", StartedAt: started})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(id).To(BeEquivalentTo(7))
+		calls := client.Script.Calls()
+		Expect(calls[len(calls)-1].Args[:3]).To(Equal([]any{"lob-performance", "pg", started}))
+
+		doc, err := store.Get(ctx, id)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(doc.Test).To(Equal("lob-performance"))
+	})
+	It("should accept an int32 id", func() {
+		client.Script.On("INSERT INTO dbtwool_results.runs").Return(map[string]any{"id": int32(7)})
+		store, err := history.OpenPostgres(ctx, client)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(func() { Expect(store.Close(ctx)).To(Succeed()) })
+		Expect(store.Save(ctx, result.Document{Test: "lob-performance"})).To(BeEquivalentTo(7))
+	})
+	It("should return the errors of the history", func() {
+		client.Script.On("INSERT INTO dbtwool_results.runs").Return(map[string]any{"id": "seven"})
+		client.Script.On("SELECT document::text AS document FROM dbtwool_results.runs WHERE id").
+			Return(map[string]any{"document": 7})
+		client.Script.On("SELECT id, document::text").Fail(errors.New("relation does not exist"))
+		client.Script.On("DELETE FROM dbtwool_results.runs").Fail(errors.New("permission denied"))
+		store, err := history.OpenPostgres(ctx, client)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(func() { Expect(store.Close(ctx)).To(Succeed()) })
+
+		_, err = store.Save(ctx, result.Document{Test: "lob-performance"})
+		Expect(err).To(MatchError(ContainSubstring("unexpected type string for an id")))
+		_, err = store.Get(ctx, 7)
+		Expect(err).To(MatchError(ContainSubstring("unexpected type int for a document")))
+		_, err = store.List(ctx, "")
+		Expect(err).To(MatchError(ContainSubstring("relation does not exist")))
+		Expect(store.Delete(ctx, 7)).To(MatchError(ContainSubstring("permission denied")))
+	})
+})
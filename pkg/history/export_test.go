@@ -0,0 +1,12 @@
+package history
+
+import (
+	"context"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+)
+
+// OpenPostgres opens the history of any client with the PostgreSQL dialect, so that it can be tested without a server
+func OpenPostgres(ctx context.Context, client dbinterface.Client) (*Store, error) {
+	return open(ctx, client, postgresDialect)
+}
@@ -0,0 +1,201 @@
+// Package history keeps the result documents of all runs in a database: a SQLite file, or the dbtwool_results
+// schema of a PostgreSQL database. This keeps track of hundreds of runs, where separate JSON files get lost.
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+	"github.com/pgvillage-tools/dbtwool/pkg/logging"
+	"github.com/pgvillage-tools/dbtwool/pkg/pg"
+	"github.com/pgvillage-tools/dbtwool/pkg/result"
+	"github.com/pgvillage-tools/dbtwool/pkg/secrets"
+	"github.com/pgvillage-tools/dbtwool/pkg/sqlite"
+)
+
+var logger = logging.Logger()
+
+const sqliteBusyTimeout = 5000 // milliseconds
+
+var (
+	// ErrNotConfigured is returned by Open when neither a file nor a dsn is set
+	ErrNotConfigured = errors.New("no history file or dsn is set")
+	// ErrNotFound is returned for a run which is not in the history
+	ErrNotFound = errors.New("run not found in the history")
+)
+
+// Config selects where the history is stored. The dsn takes precedence over the file.
+type Config struct {
+	// File is a SQLite database file
+	File string
+	// DSN is a PostgreSQL connection string
+	DSN string
+}
+
+// Enabled returns whether a history is configured
+func (c Config) Enabled() bool {
+	return c.File != "" || c.DSN != ""
+}
+
+// Entry is a run in the history
+type Entry struct {
+	ID       int64
+	Document result.Document
+}
+
+// Store is an opened history
+type Store struct {
+	conn    dbinterface.Connection
+	dialect dialect
+}
+
+// Open connects to the history of cfg, and creates it when it does not exist yet. The password in the dsn is
+// registered as a secret, so that it is masked in the documents and logs from then on.
+func Open(ctx context.Context, cfg Config) (*Store, error) {
+	switch {
+	case cfg.DSN != "":
+		params, err := pg.ParseDSN(cfg.DSN)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the history dsn: %w", err)
+		}
+		for _, secret := range []string{params.Password, params.SslPassword} {
+			if err = secrets.Register(secret); err != nil {
+				logger.Warn().Err(err).Msg("A password of the history dsn is not masked in the output")
+			}
+		}
+		client := pg.NewClient(params)
+		return open(ctx, &client, postgresDialect)
+	case cfg.File != "":
+		client := sqlite.NewClient(sqlite.ConnParams{Path: cfg.File, BusyTimeout: sqliteBusyTimeout})
+		return open(ctx, &client, sqliteDialect)
+	default:
+		return nil, ErrNotConfigured
+	}
+}
+
+func open(ctx context.Context, client dbinterface.Client, d dialect) (*Store, error) {
+	pool, err := client.Pool(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the history: %w", err)
+	}
+	conn, err := pool.Connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the history: %w", err)
+	}
+	if err = create(ctx, conn, d); err != nil {
+		_ = conn.Close(ctx)
+		return nil, fmt.Errorf("failed to create the history: %w", err)
+	}
+	return &Store{conn: conn, dialect: d}, nil
+}
+
+// create creates the history in one transaction, as PostgreSQL connections only execute statements in one
+func create(ctx context.Context, conn dbinterface.Connection, d dialect) error {
+	if err := conn.Begin(ctx); err != nil {
+		return err
+	}
+	for _, statement := range d.create {
+		if _, err := conn.Execute(ctx, statement); err != nil {
+			_ = conn.Rollback(ctx)
+			return err
+		}
+	}
+	return conn.Commit(ctx)
+}
+
+// Close closes the connection to the history
+func (s *Store) Close(ctx context.Context) error {
+	return s.conn.Close(ctx)
+}
+
+// Save adds a result document to the history, and returns its id. Secrets are masked, as in the result file.
+func (s *Store) Save(ctx context.Context, doc result.Document) (int64, error) {
+	content, err := doc.Marshal()
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal the result document: %w", err)
+	}
+	row, err := s.conn.QueryOneRow(ctx, s.dialect.insert,
+		doc.Test, doc.RDBMS, doc.StartedAt, doc.FinishedAt, string(content))
+	if err != nil {
+		return 0, fmt.Errorf("failed to save the result in the history: %w", err)
+	}
+	id, err := toInt64(row["id"])
+	if err != nil {
+		return 0, err
+	}
+	logger.Info().Int64("id", id).Str("test", doc.Test).Msg("Result saved in the history")
+	return id, nil
+}
+
+// List returns the runs of a test in the order they were saved, or of all tests when test is empty
+func (s *Store) List(ctx context.Context, test string) ([]Entry, error) {
+	rows, err := s.conn.Query(ctx, s.dialect.list, test)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the history: %w", err)
+	}
+	entries := make([]Entry, 0, len(rows))
+	for _, row := range rows {
+		id, idErr := toInt64(row["id"])
+		if idErr != nil {
+			return nil, idErr
+		}
+		doc, parseErr := parse(row["document"])
+		if parseErr != nil {
+			return nil, fmt.Errorf("failed to parse run %d: %w", id, parseErr)
+		}
+		entries = append(entries, Entry{ID: id, Document: doc})
+	}
+	return entries, nil
+}
+
+// Get returns the result document of a run, or ErrNotFound
+func (s *Store) Get(ctx context.Context, id int64) (result.Document, error) {
+	rows, err := s.conn.Query(ctx, s.dialect.get, id)
+	if err != nil {
+		return result.Document{}, fmt.Errorf("failed to read run %d: %w", id, err)
+	}
+	if len(rows) == 0 {
+		return result.Document{}, fmt.Errorf("%w: %d", ErrNotFound, id)
+	}
+	doc, err := parse(rows[0]["document"])
+	if err != nil {
+		return result.Document{}, fmt.Errorf("failed to parse run %d: %w", id, err)
+	}
+	return doc, nil
+}
+
+// Delete removes a run from the history, or returns ErrNotFound
+func (s *Store) Delete(ctx context.Context, id int64) error {
+	rows, err := s.conn.Query(ctx, s.dialect.delete, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete run %d: %w", id, err)
+	}
+	if len(rows) == 0 {
+		return fmt.Errorf("%w: %d", ErrNotFound, id)
+	}
+	return nil
+}
+
+func parse(value any) (result.Document, error) {
+	var doc result.Document
+	content, ok := value.(string)
+	if !ok {
+		return doc, fmt.Errorf("unexpected type %T for a document", value)
+	}
+	err := json.Unmarshal([]byte(content), &doc)
+	return doc, err
+}
+
+func toInt64(value any) (int64, error) {
+	switch v := value.(type) {
+	case int64:
+		return v, nil
+	case int32:
+		return int64(v), nil
+	default:
+		return 0, fmt.Errorf("unexpected type %T for an id", value)
+	}
+}
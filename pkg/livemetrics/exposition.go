@@ -0,0 +1,157 @@
+package livemetrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// latencyBuckets are the upper bounds of the buckets of the latency histograms
+var latencyBuckets = []time.Duration{
+	500 * time.Microsecond,
+	time.Millisecond,
+	2500 * time.Microsecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// unclassified is the error class of failures which were counted without a class
+const unclassified = "unclassified"
+
+type exposition struct {
+	w *bufio.Writer
+}
+
+func (e exposition) header(name string, kind string, help string) {
+	fmt.Fprintf(e.w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func (e exposition) sample(name string, labels []string, value float64) {
+	e.w.WriteString(name)
+	if len(labels) > 0 {
+		e.w.WriteByte('{')
+		for i := 0; i < len(labels); i += 2 {
+			if i > 0 {
+				e.w.WriteByte(',')
+			}
+			fmt.Fprintf(e.w, `%s="%s"`, labels[i], escapeLabel(labels[i+1]))
+		}
+		e.w.WriteByte('}')
+	}
+	fmt.Fprintf(e.w, " %s\n", formatValue(value))
+}
+
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func formatValue(value float64) string {
+	if math.IsInf(value, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, bitSize64)
+}
+
+const (
+	bitSize64     = 64
+	kindCounter   = "counter"
+	kindGauge     = "gauge"
+	kindHistogram = "histogram"
+
+	labelTest      = "test"
+	labelOperation = "operation"
+
+	metricOperations = "dbtwool_operations_total"
+	metricErrors     = "dbtwool_operation_errors_total"
+	metricRetries    = "dbtwool_operation_retries_total"
+	metricDuration   = "dbtwool_operation_duration_seconds"
+)
+
+// Write writes all metrics in the Prometheus text format
+func Write(w io.Writer) error {
+	e := exposition{w: bufio.NewWriter(w)}
+	e.operations(registeredOperations())
+	e.totals()
+	return e.w.Flush()
+}
+
+func (e exposition) operations(ops []testOperation) {
+	e.header(metricOperations, kindCounter, "Successful operations in the measurement window.")
+	for _, o := range ops {
+		e.sample(metricOperations, o.labels(), float64(o.op.Count()))
+	}
+	e.header(metricErrors, kindCounter, "Failed operations in the measurement window by error class.")
+	for _, o := range ops {
+		byClass := o.op.ErrorsByClass()
+		classes := make([]string, 0, len(byClass))
+		var classified int64
+		for class, count := range byClass {
+			classes = append(classes, class)
+			classified += count
+		}
+		sort.Strings(classes)
+		for _, class := range classes {
+			e.sample(metricErrors, append(o.labels(), "class", class), float64(byClass[class]))
+		}
+		if rest := o.op.Errors() - classified; rest > 0 {
+			e.sample(metricErrors, append(o.labels(), "class", unclassified), float64(rest))
+		}
+	}
+	e.header(metricRetries, kindCounter, "Retried operations in the measurement window.")
+	for _, o := range ops {
+		e.sample(metricRetries, o.labels(), float64(o.op.Retries()))
+	}
+	e.header(metricDuration, kindHistogram, "Latency of successful operations in the measurement window.")
+	for _, o := range ops {
+		latency := o.op.Latency()
+		for _, bound := range latencyBuckets {
+			e.sample(metricDuration+"_bucket", append(o.labels(), "le", formatValue(bound.Seconds())),
+				float64(latency.CountAtMost(bound)))
+		}
+		e.sample(metricDuration+"_bucket", append(o.labels(), "le", "+Inf"), float64(latency.Count))
+		e.sample(metricDuration+"_sum", o.labels(), time.Duration(latency.Sum).Seconds())
+		e.sample(metricDuration+"_count", o.labels(), float64(latency.Count))
+	}
+}
+
+func (o testOperation) labels() []string {
+	return []string{labelTest, o.test, labelOperation, o.op.Name()}
+}
+
+func (e exposition) totals() {
+	for _, metric := range []struct {
+		name  string
+		kind  string
+		help  string
+		value float64
+	}{
+		{"dbtwool_bytes_read_total", kindCounter, "Bytes of LOBs read from the database.", float64(bytesRead.Load())},
+		{"dbtwool_bytes_written_total", kindCounter, "Bytes of LOBs written to the database.",
+			float64(bytesWritten.Load())},
+		{"dbtwool_rows_generated_total", kindCounter, "Rows inserted by gen commands.", float64(rowsGenerated.Load())},
+		{"dbtwool_gen_rows_done", kindGauge, "Rows of the running gen command which are done.",
+			float64(rowsDone.Load())},
+		{"dbtwool_gen_rows", kindGauge, "Rows the running gen command inserts in total.", float64(rowsTotal.Load())},
+		{"dbtwool_gen_progress_percent", kindGauge, "Progress of the running gen command.",
+			math.Float64frombits(progressPct.Load())},
+		{"dbtwool_gen_eta_seconds", kindGauge, "Estimated time until the running gen command is done.",
+			time.Duration(eta.Load()).Seconds()},
+	} {
+		e.header(metric.name, metric.kind, metric.help)
+		e.sample(metric.name, nil, metric.value)
+	}
+}
@@ -0,0 +1,13 @@
+package livemetrics_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestLivemetrics(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Livemetrics Suite")
+}
@@ -0,0 +1,82 @@
+package livemetrics_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/livemetrics"
+	"github.com/pgvillage-tools/dbtwool/pkg/stats"
+)
+
+var _ = Describe("Live metrics", func() {
+	BeforeEach(func() {
+		op := stats.NewOperation("oltp")
+		op.Observe(2 * time.Millisecond)
+		op.Observe(20 * time.Millisecond)
+		op.Observe(2 * time.Second)
+		op.FailWith("serialization")
+		op.Fail()
+		op.Retry()
+		livemetrics.RegisterOperations("ru-performance", op)
+		livemetrics.SetProgress(25, 100, 25, 90*time.Second)
+	})
+
+	It("should write operations, errors, retries and latency buckets", func() {
+		var buf bytes.Buffer
+		Expect(livemetrics.Write(&buf)).To(Succeed())
+		text := buf.String()
+		Expect(text).To(ContainSubstring("# TYPE dbtwool_operations_total counter\n"))
+		Expect(text).To(ContainSubstring(`dbtwool_operations_total{test="ru-performance",operation="oltp"} 3`))
+		Expect(text).To(ContainSubstring(
+			`dbtwool_operation_errors_total{test="ru-performance",operation="oltp",class="serialization"} 1`))
+		Expect(text).To(ContainSubstring(
+			`dbtwool_operation_errors_total{test="ru-performance",operation="oltp",class="unclassified"} 1`))
+		Expect(text).To(ContainSubstring(`dbtwool_operation_retries_total{test="ru-performance",operation="oltp"} 1`))
+		Expect(text).To(ContainSubstring(
+			`dbtwool_operation_duration_seconds_bucket{test="ru-performance",operation="oltp",le="0.005"} 1`))
+		Expect(text).To(ContainSubstring(
+			`dbtwool_operation_duration_seconds_bucket{test="ru-performance",operation="oltp",le="0.05"} 2`))
+		Expect(text).To(ContainSubstring(
+			`dbtwool_operation_duration_seconds_bucket{test="ru-performance",operation="oltp",le="+Inf"} 3`))
+		Expect(text).To(ContainSubstring(
+			`dbtwool_operation_duration_seconds_count{test="ru-performance",operation="oltp"} 3`))
+		Expect(text).To(ContainSubstring("dbtwool_gen_progress_percent 25\n"))
+		Expect(text).To(ContainSubstring("dbtwool_gen_eta_seconds 90\n"))
+	})
+
+	It("should replace an operation which is registered again", func() {
+		livemetrics.RegisterOperations("ru-performance", stats.NewOperation("oltp"))
+		var buf bytes.Buffer
+		Expect(livemetrics.Write(&buf)).To(Succeed())
+		Expect(buf.String()).To(ContainSubstring(`dbtwool_operations_total{test="ru-performance",operation="oltp"} 0`))
+	})
+
+	It("should serve the metrics over http", func() {
+		livemetrics.AddBytesRead(1)
+		server, err := livemetrics.Serve("127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { Expect(server.Close(context.Background())).To(Succeed()) }()
+
+		response, err := http.Get("http://" + server.Addr() + "/metrics")
+		Expect(err).NotTo(HaveOccurred())
+		defer response.Body.Close()
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+		body, err := io.ReadAll(response.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(ContainSubstring("dbtwool_bytes_read_total"))
+	})
+
+	It("should fail to serve on an address which is in use", func() {
+		server, err := livemetrics.Serve("127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { _ = server.Close(context.Background()) }()
+		_, err = livemetrics.Serve(server.Addr())
+		Expect(err).To(MatchError(ContainSubstring("failed to listen for metrics")))
+	})
+})
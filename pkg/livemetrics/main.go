@@ -0,0 +1,85 @@
+// Package livemetrics publishes the progress of a running command in the Prometheus text format, so that long runs
+// can be followed on a dashboard next to the metrics of the database. The metrics are process wide, like the
+// default registry of the Prometheus client: every package updates them, and Serve publishes them with
+// --metricsListen.
+package livemetrics
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/logging"
+	"github.com/pgvillage-tools/dbtwool/pkg/stats"
+)
+
+var logger = logging.Logger()
+
+type testOperation struct {
+	test string
+	op   *stats.Operation
+}
+
+var (
+	operationsMutex sync.Mutex
+	operations      []testOperation
+
+	bytesRead     atomic.Int64
+	bytesWritten  atomic.Int64
+	rowsGenerated atomic.Int64
+
+	rowsDone    atomic.Int64
+	rowsTotal   atomic.Int64
+	progressPct atomic.Uint64 // float64 bits
+	eta         atomic.Int64  // time.Duration
+)
+
+// RegisterOperations publishes the operations of a test. An operation replaces the one of the same test and name
+// which was registered before.
+func RegisterOperations(test string, ops ...*stats.Operation) {
+	operationsMutex.Lock()
+	defer operationsMutex.Unlock()
+	for _, op := range ops {
+		replaced := false
+		for i, registered := range operations {
+			if registered.test == test && registered.op.Name() == op.Name() {
+				operations[i].op = op
+				replaced = true
+			}
+		}
+		if !replaced {
+			operations = append(operations, testOperation{test: test, op: op})
+		}
+	}
+}
+
+func registeredOperations() []testOperation {
+	operationsMutex.Lock()
+	defer operationsMutex.Unlock()
+	return append([]testOperation(nil), operations...)
+}
+
+// AddBytesRead counts bytes (of LOBs) which were read from the database
+func AddBytesRead(n int64) {
+	bytesRead.Add(n)
+}
+
+// AddBytesWritten counts bytes (of LOBs) which were written to the database
+func AddBytesWritten(n int64) {
+	bytesWritten.Add(n)
+}
+
+// AddRowsGenerated counts rows which were inserted by a gen command
+func AddRowsGenerated(n int64) {
+	rowsGenerated.Add(n)
+}
+
+// SetProgress sets the progress of a gen command: done of total rows, as a percentage, and the estimated time until
+// all rows are done
+func SetProgress(done int64, total int64, pct float64, remaining time.Duration) {
+	rowsDone.Store(done)
+	rowsTotal.Store(total)
+	progressPct.Store(math.Float64bits(pct))
+	eta.Store(int64(remaining))
+}
@@ -0,0 +1,51 @@
+package livemetrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+const readHeaderTimeout = 10 * time.Second
+
+// Server serves the metrics on /metrics
+type Server struct {
+	listener net.Listener
+	server   *http.Server
+}
+
+// Serve starts serving the metrics on addr (e.g. :9187) in the background
+func Serve(addr string) (*Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for metrics on %s: %w", addr, err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if writeErr := Write(w); writeErr != nil {
+			logger.Debug().Err(writeErr).Msg("Writing metrics failed")
+		}
+	})
+	s := &Server{listener: listener, server: &http.Server{Handler: mux, ReadHeaderTimeout: readHeaderTimeout}}
+	go func() {
+		if serveErr := s.server.Serve(listener); serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+			logger.Error().Err(serveErr).Msg("Serving metrics failed")
+		}
+	}()
+	logger.Info().Str("addr", listener.Addr().String()).Msg("Serving metrics on /metrics")
+	return s, nil
+}
+
+// Addr returns the address the metrics are served on
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Close stops serving the metrics
+func (s *Server) Close(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
@@ -9,6 +9,8 @@ import (
 
 	"github.com/pgvillage-tools/dbtwool/pkg/dbclient"
 	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+	"github.com/pgvillage-tools/dbtwool/pkg/livemetrics"
+	"github.com/pgvillage-tools/dbtwool/pkg/tracing"
 )
 
 // GenerateBulk generates LOB data and inserts using the bulk path (COPY/LOAD) via processLobRowsBatchBulk.
@@ -23,14 +25,32 @@ func GenerateBulk(
 	byteSize string,
 	batchSize int,
 	lobType string,
-) {
-	conn := mustConnect(ctx, client)
+) (err error) {
+	ctx, span := tracing.Start(ctx, "lob-performance gen", tracing.RDBMS(dbType), tracing.Table(schemaName, tableName),
+		tracing.LobType(lobType))
+	defer func() { tracing.End(span, err) }()
+	if batchSize <= 0 {
+		return errors.New("batchSize must be > 0")
+	}
+	conn, err := connect(ctx, client)
+	if err != nil {
+		return err
+	}
 	defer conn.Close(ctx)
 
-	totalBytes := mustParseByteSize(byteSize)
-	buckets := createSpreadBuckets(spread)
+	totalBytes, err := parseTotalBytes(byteSize)
+	if err != nil {
+		return err
+	}
+	buckets, err := createSpreadBuckets(spread)
+	if err != nil {
+		return err
+	}
 
-	plan := mustBuildLOBPlan(totalBytes, lobType, buckets, emptyLobs)
+	plan, err := buildLOBPlan(totalBytes, lobType, buckets, emptyLobs)
+	if err != nil {
+		return err
+	}
 	logger.Info().Msgf("Plan built: %d rows; batch size %d", len(plan), batchSize)
 
 	const randomSeed = 12345
@@ -39,7 +59,10 @@ func GenerateBulk(
 
 	for b, start := 0, 0; start < len(idx); b, start = b+1, start+batchSize {
 		end := min(start+batchSize, len(idx))
-		rows := mustBuildBatchRows(plan, idx[start:end])
+		rows, err := buildBatchRows(plan, idx[start:end])
+		if err != nil {
+			return err
+		}
 
 		doneAfter := end
 		logger.Info().Msgf(
@@ -50,52 +73,55 @@ func GenerateBulk(
 		)
 
 		if err := processLobRowsBatchBulk(ctx, conn, schemaName, tableName, rows, b); err != nil {
-			logger.Fatal().Msgf("Something went wrong while processing the bulk LOB batch: %v", err)
+			return fmt.Errorf("something went wrong while processing the bulk LOB batch: %w", err)
 		}
+		livemetrics.SetProgress(int64(doneAfter), int64(len(idx)), progressPct(doneAfter, len(idx)),
+			estimateRemaining(startedAt, doneAfter, len(idx)))
 	}
+	return nil
 }
 
-// --- small helpers used by GenerateBulk ---
-func mustConnect(ctx context.Context, client dbinterface.Client) dbinterface.Connection {
+// --- small helpers used by Generate and GenerateBulk ---
+func connect(ctx context.Context, client dbinterface.Client) (dbinterface.Connection, error) {
 	logger.Info().Msg("Initiating connection pool.")
 	pool, err := client.Pool(ctx)
 	if err != nil {
-		logger.Fatal().Msgf("Failed to connect: %v", err)
+		return nil, fmt.Errorf("failed to connect: %w", err)
 	}
 
 	logger.Info().Msg("Connecting to database.")
 	conn, err := pool.Connect(ctx)
 	if err != nil {
-		logger.Fatal().Msgf("connect error: %v", err)
+		return nil, fmt.Errorf("connect error: %w", err)
 	}
-	return conn
+	return conn, nil
 }
 
-func mustParseByteSize(byteSize string) int64 {
+func parseTotalBytes(byteSize string) (int64, error) {
 	totalBytes, err := ParseByteSize(byteSize)
 	if err != nil {
-		logger.Fatal().Msgf("Cannot parse bytes from byteSize argument: %v", err)
+		return 0, fmt.Errorf("cannot parse bytes from byteSize argument: %w", err)
 	}
 	logger.Info().Msgf("Totalbytes set to %v", totalBytes)
-	return totalBytes
+	return totalBytes, nil
 }
 
-func mustBuildLOBPlan(totalBytes int64, lobType string, buckets []SpreadBucket, emptyLobs int64) []LOBRowPlan {
+func buildLOBPlan(totalBytes int64, lobType string, buckets []SpreadBucket, emptyLobs int64) ([]LOBRowPlan, error) {
 	logger.Info().Msg("Building LOB generation plan")
 	plan, err := BuildLOBPlan(totalBytes, lobType, buckets, emptyLobs)
 	if err != nil {
-		logger.Fatal().Msgf("Something went wrong building the LOB generation plan: %v", err)
+		return nil, fmt.Errorf("something went wrong building the LOB generation plan: %w", err)
 	}
-	return plan
+	return plan, nil
 }
 
-func mustBuildBatchRows(plan []LOBRowPlan, batchIdx []int) []dbinterface.LobRow {
+func buildBatchRows(plan []LOBRowPlan, batchIdx []int) ([]dbinterface.LobRow, error) {
 	rows := make([]dbinterface.LobRow, 0, len(batchIdx))
 	for _, k := range batchIdx {
 		p := plan[k]
 		payload, err := createLobPayload(p.LobType, p.LobBytes)
 		if err != nil {
-			logger.Fatal().Msgf("create payload failed for row_index=%d: %v", p.RowIndex, err)
+			return nil, fmt.Errorf("create payload failed for row_index=%d: %w", p.RowIndex, err)
 		}
 		rows = append(rows, dbinterface.LobRow{
 			TenantID: p.TenantID,
@@ -104,7 +130,7 @@ func mustBuildBatchRows(plan []LOBRowPlan, batchIdx []int) []dbinterface.LobRow
 			Payload:  payload,
 		})
 	}
-	return rows
+	return rows, nil
 }
 
 func processLobRowsBatchBulk(
@@ -113,7 +139,9 @@ func processLobRowsBatchBulk(
 	schema, table string,
 	rows []dbinterface.LobRow,
 	batchIndex int,
-) error {
+) (err error) {
+	ctx, span := tracing.Start(ctx, "lob-performance batch", tracing.Batch(batchIndex), tracing.Rows(int64(len(rows))))
+	defer func() { tracing.End(span, err) }()
 	bi, ok := any(conn).(dbinterface.BulkInserter)
 	if !ok {
 		return errors.New("bulk mode requested but connection does not support bulk insert")
@@ -123,6 +151,9 @@ func processLobRowsBatchBulk(
 	if err != nil {
 		return fmt.Errorf("bulk insert failed (batch %d): %w", batchIndex, err)
 	}
+	span.SetAttributes(tracing.Bytes(insBytes))
+	livemetrics.AddRowsGenerated(insRows)
+	livemetrics.AddBytesWritten(insBytes)
 
 	logger.Debug().
 		Int("batch_index", batchIndex).
@@ -1,5 +1,7 @@
 package lobperformance
 
+import "github.com/pgvillage-tools/dbtwool/pkg/dbclient"
+
 // DBHelper is an interface to help returning queries for a specific RDBMS type
 type DBHelper interface {
 	CreateSchemaSQL() string
@@ -9,3 +11,15 @@ type DBHelper interface {
 	SelectMinMaxIDSQL() string
 	PayloadColumnForLOBType(lobType string) string
 }
+
+// newDBHelper returns the helper for an RDBMS type
+func newDBHelper(dbType dbclient.RDBMS, schema, table string) DBHelper {
+	switch dbType {
+	case dbclient.DB2:
+		return DB2Helper{schemaName: schema, tableName: table}
+	case dbclient.SQLite:
+		return SQLiteHelper{schemaName: schema, tableName: table}
+	default:
+		return PGHelper{schemaName: schema, tableName: table}
+	}
+}
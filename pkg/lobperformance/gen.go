@@ -10,6 +10,8 @@ import (
 
 	"github.com/pgvillage-tools/dbtwool/pkg/dbclient"
 	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+	"github.com/pgvillage-tools/dbtwool/pkg/livemetrics"
+	"github.com/pgvillage-tools/dbtwool/pkg/tracing"
 	"github.com/rs/zerolog/log"
 )
 
@@ -24,37 +26,35 @@ type LOBRowPlan struct {
 
 // Generate generates LOB data
 func Generate(ctx context.Context, dbType dbclient.RDBMS, client dbinterface.Client, schemaName string,
-	tableName string, spread []string, emptyLobs int64, byteSize string, batchSize int, lobType string) {
+	tableName string, spread []string, emptyLobs int64, byteSize string, batchSize int, lobType string) (err error) {
 	var logger = log.With().Logger()
-	logger.Info().Msg("Initiating connection pool.")
-	pool, poolErr := client.Pool(ctx)
-	if poolErr != nil {
-		logger.Fatal().Msgf("Failed to connect: %v", poolErr)
+	ctx, span := tracing.Start(ctx, "lob-performance gen", tracing.RDBMS(dbType), tracing.Table(schemaName, tableName),
+		tracing.LobType(lobType))
+	defer func() { tracing.End(span, err) }()
+	if batchSize <= 0 {
+		return errors.New("batchSize must be > 0")
 	}
-
-	logger.Info().Msg("Connecting to database.")
-	conn, connectErr1 := pool.Connect(ctx) //
-	if connectErr1 != nil {
-		logger.Fatal().Msgf("connect error for connection 1: %v", connectErr1)
+	conn, err := connect(ctx, client)
+	if err != nil {
+		return err
 	}
 	defer conn.Close(ctx)
 
-	dbHelper := initDBHelper(dbType, schemaName, tableName)
+	dbHelper := newDBHelper(dbType, schemaName, tableName)
 
-	// Interpret byte size
-	totalBytes, err := ParseByteSize(byteSize)
+	totalBytes, err := parseTotalBytes(byteSize)
 	if err != nil {
-		logger.Fatal().Msgf("Cannot parse bytes from byteSize argument: %v", err)
+		return err
 	}
 
-	logger.Info().Msgf("Totalbytes set to %v", totalBytes)
-
-	var buckets = createSpreadBuckets(spread)
+	buckets, err := createSpreadBuckets(spread)
+	if err != nil {
+		return err
+	}
 
-	logger.Info().Msg("Building LOB generation plan")
-	plan, err := BuildLOBPlan(totalBytes, lobType, buckets, int64(emptyLobs))
+	plan, err := buildLOBPlan(totalBytes, lobType, buckets, emptyLobs)
 	if err != nil {
-		logger.Fatal().Msgf("Something went wrong building the LOB generation plan: %v", err)
+		return err
 	}
 
 	totalNumOfRows := len(plan)
@@ -63,7 +63,7 @@ func Generate(ctx context.Context, dbType dbclient.RDBMS, client dbinterface.Cli
 
 	insertSQL, err := dbHelper.CreateInsertLOBRowBaseSQL(lobType)
 	if err != nil {
-		logger.Fatal().Msgf("Could not establish base SQL insert query: %v", err)
+		return fmt.Errorf("could not establish base SQL insert query: %w", err)
 	}
 	const randomSeed = 12345
 	idx := ShuffledIndices(len(plan), randomSeed)
@@ -89,11 +89,12 @@ func Generate(ctx context.Context, dbType dbclient.RDBMS, client dbinterface.Cli
 			start+1, end, totalNumOfRows, pct, eta.Truncate(time.Second),
 		)
 
-		err := processLobBatch(ctx, conn, batch, start/batchSize, insertSQL)
-		if err != nil {
-			logger.Fatal().Msgf("Something went wrong while processing the LOB batch %v", err)
+		if err := processLobBatch(ctx, conn, batch, start/batchSize, insertSQL); err != nil {
+			return fmt.Errorf("something went wrong while processing the LOB batch: %w", err)
 		}
+		livemetrics.SetProgress(int64(doneAfter), int64(total), pct, estimateRemaining(startedAt, doneAfter, total))
 	}
+	return nil
 }
 
 func processLobBatch(
@@ -102,10 +103,13 @@ func processLobBatch(
 	batch []LOBRowPlan,
 	batchIndex int,
 	insertSQL string,
-) error {
+) (err error) {
 	if len(batch) == 0 {
 		return nil
 	}
+	ctx, span := tracing.Start(ctx, "lob-performance batch", tracing.Batch(batchIndex),
+		tracing.Rows(int64(len(batch))), tracing.LobType(batch[0].LobType))
+	defer func() { tracing.End(span, err) }()
 
 	if err := validateBatch(batch, batchIndex); err != nil {
 		return err
@@ -163,6 +167,9 @@ func processLobBatch(
 		return fmt.Errorf("commit batch tx failed: %w", err)
 	}
 	committed = true
+	span.SetAttributes(tracing.Bytes(totalBytes))
+	livemetrics.AddRowsGenerated(rowsAltered)
+	livemetrics.AddBytesWritten(totalBytes)
 
 	logger.Debug().
 		Int("batch_index", batchIndex).
@@ -191,17 +198,17 @@ func validateBatch(batch []LOBRowPlan, batchIndex int) error {
 	return nil
 }
 
-func createSpreadBuckets(spread []string) []SpreadBucket {
+func createSpreadBuckets(spread []string) ([]SpreadBucket, error) {
 	var buckets []SpreadBucket
 	for _, s := range spread {
 		b, err := ParseSpread(s)
 		if err != nil {
-			logger.Fatal().Msgf("Cannot parse spread argument: %v", err)
+			return nil, fmt.Errorf("cannot parse spread argument: %w", err)
 		}
 		buckets = append(buckets, b)
 	}
 
-	return buckets
+	return buckets, nil
 }
 
 func createLobPayload(lobType string, size int64) (any, error) {
@@ -303,10 +310,3 @@ func estimateRemaining(start time.Time, done, total int) time.Duration {
 
 	return time.Duration(etaSeconds * float64(time.Second))
 }
-
-func initDBHelper(dbType dbclient.RDBMS, schemaName, tableName string) DBHelper {
-	if dbType == dbclient.DB2 {
-		return DB2Helper{schemaName: schemaName, tableName: tableName}
-	}
-	return PGHelper{schemaName: schemaName, tableName: tableName}
-}
@@ -0,0 +1,176 @@
+package lobperformance_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbclient"
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface/fake"
+	"github.com/pgvillage-tools/dbtwool/pkg/lobperformance"
+	"github.com/pgvillage-tools/dbtwool/pkg/testrunner"
+)
+
+var _ = Describe("LOB performance with a fake database", func() {
+	var (
+		ctx    context.Context
+		client *fake.Client
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		client = fake.NewClient()
+	})
+
+	Context("Stage", func() {
+		It("should create the schema and table in one transaction", func() {
+			Expect(lobperformance.Stage(ctx, dbclient.Postgres, client, "s", "t")).To(Succeed())
+			Expect(client.Script.Count("CREATE SCHEMA IF NOT EXISTS s")).To(Equal(1))
+			Expect(client.Script.Count("CREATE TABLE IF NOT EXISTS s.t")).To(Equal(1))
+			Expect(client.Script.Count(fake.StmtCommit)).To(Equal(1))
+		})
+		It("should continue when the schema already exists", func() {
+			client.Script.On("CREATE SCHEMA").Fail(errors.New("schema exists"))
+			Expect(lobperformance.Stage(ctx, dbclient.DB2, client, "s", "t")).To(Succeed())
+		})
+		It("should roll back and return an error when the table cannot be created", func() {
+			client.Script.On("CREATE TABLE").Fail(errors.New("no permission"))
+			err := lobperformance.Stage(ctx, dbclient.Postgres, client, "s", "t")
+			Expect(err).To(MatchError(ContainSubstring("no permission")))
+			Expect(client.Script.Count(fake.StmtRollback)).To(Equal(1))
+		})
+		It("should return an error when the pool cannot be created", func() {
+			client.PoolErr = errors.New("no server")
+			Expect(lobperformance.Stage(ctx, dbclient.Postgres, client, "s", "t")).NotTo(Succeed())
+		})
+	})
+
+	Context("GenerateBulk", func() {
+		It("should insert all rows in batches", func() {
+			Expect(lobperformance.GenerateBulk(ctx, dbclient.Postgres, client, "s", "t",
+				[]string{"100%:8b"}, 0, "800b", 40, "blob")).To(Succeed())
+			Expect(client.Script.Count(fake.StmtBulkInsert + " s.t")).To(Equal(3))
+			var rows int
+			for _, call := range client.Script.Calls() {
+				if batch, ok := call.Payload.([]dbinterface.LobRow); ok {
+					rows += len(batch)
+				}
+			}
+			Expect(rows).To(Equal(100))
+		})
+		It("should stop at the first failing batch", func() {
+			client.Script.On(fake.StmtBulkInsert).After(1).Fail(errors.New("disk full"))
+			err := lobperformance.GenerateBulk(ctx, dbclient.Postgres, client, "s", "t",
+				[]string{"100%:8b"}, 0, "800b", 40, "blob")
+			Expect(err).To(MatchError(ContainSubstring("disk full")))
+			Expect(client.Script.Count(fake.StmtBulkInsert)).To(Equal(2))
+		})
+		It("should reject invalid arguments", func() {
+			Expect(lobperformance.GenerateBulk(ctx, dbclient.Postgres, client, "s", "t",
+				[]string{"100%:8b"}, 0, "lots", 40, "blob")).NotTo(Succeed())
+			Expect(lobperformance.GenerateBulk(ctx, dbclient.Postgres, client, "s", "t",
+				[]string{"all"}, 0, "800b", 40, "blob")).NotTo(Succeed())
+			Expect(lobperformance.GenerateBulk(ctx, dbclient.Postgres, client, "s", "t",
+				[]string{"100%:8b"}, 0, "800b", 0, "blob")).NotTo(Succeed())
+		})
+	})
+
+	Context("Generate", func() {
+		It("should insert with a prepared statement per batch", func() {
+			Expect(lobperformance.Generate(ctx, dbclient.DB2, client, "s", "t",
+				[]string{"100%:8b"}, 0, "800b", 40, "clob")).To(Succeed())
+			Expect(client.Script.Count(fake.StmtPrepare)).To(Equal(3))
+			Expect(client.Script.Count("INSERT INTO s.t (tenant_id, doc_type, payload_text)")).To(Equal(3 + 100))
+			Expect(client.Script.Count(fake.StmtCommit)).To(Equal(3))
+		})
+		It("should roll back the batch when an insert fails halfway", func() {
+			client.Script.On("INSERT INTO").After(50).Fail(errors.New("constraint violation"))
+			err := lobperformance.Generate(ctx, dbclient.Postgres, client, "s", "t",
+				[]string{"100%:8b"}, 0, "800b", 40, "blob")
+			Expect(err).To(MatchError(ContainSubstring("constraint violation")))
+			Expect(client.Script.Count(fake.StmtCommit)).To(Equal(1))
+			Expect(client.Script.Count(fake.StmtRollback)).To(Equal(1))
+		})
+	})
+
+	Context("ExecuteTest", func() {
+		It("should read LOBs until the execution time is over", func() {
+			client.Script.On("MIN(id)").Return(map[string]any{"min_id": int64(1), "max_id": int64(10)})
+			client.Script.On("SELECT payload_bin").Delay(time.Millisecond).
+				Return(map[string]any{"payload_bin": []byte("abc")})
+			Expect(lobperformance.ExecuteTest(ctx, dbclient.Postgres, client, "s", "t", "1", 2, 1, 1,
+				"scattered", "blob", dbinterface.SessionSettings{LockTimeout: time.Second},
+				testrunner.ReconnectPolicy{}, nil)).To(Succeed())
+			Expect(client.Script.Count(fake.StmtLockTimeout)).To(Equal(2))
+			Expect(client.Script.Count("SELECT payload_bin")).To(BeNumerically(">", 0))
+		})
+		It("should return an error when the table is empty", func() {
+			client.Script.On("MIN(id)").Return(map[string]any{"min_id": nil, "max_id": nil})
+			Expect(lobperformance.ExecuteTest(ctx, dbclient.Postgres, client, "s", "t", "1", 2, 1, 1,
+				"scattered", "blob", dbinterface.SessionSettings{}, testrunner.ReconnectPolicy{}, nil)).NotTo(Succeed())
+		})
+		It("should return the error of a failing reader", func() {
+			client.Script.On("MIN(id)").Return(map[string]any{"min_id": int64(1), "max_id": int64(10)})
+			client.Script.On("SELECT payload_bin").Fail(errors.New("corrupt page"))
+			err := lobperformance.ExecuteTest(ctx, dbclient.Postgres, client, "s", "t", "1", 2, 1, 1,
+				"scattered", "blob", dbinterface.SessionSettings{}, testrunner.ReconnectPolicy{}, nil)
+			Expect(err).To(MatchError(ContainSubstring("corrupt page")))
+		})
+		It("should fail on a lost connection without reconnect", func() {
+			client.Script.On("MIN(id)").Return(map[string]any{"min_id": int64(1), "max_id": int64(10)})
+			client.Script.On("SELECT payload_bin").Fail(
+				fake.NewError(dbinterface.ErrorClassConnectionLost, "server closed the connection"))
+			err := lobperformance.ExecuteTest(ctx, dbclient.Postgres, client, "s", "t", "1", 1, 1, 1,
+				"scattered", "blob", dbinterface.SessionSettings{}, testrunner.ReconnectPolicy{}, nil)
+			Expect(err).To(MatchError(ContainSubstring("server closed the connection")))
+		})
+		It("should reconnect and keep reading when the connection is lost", func() {
+			client.Script.On("MIN(id)").Return(map[string]any{"min_id": int64(1), "max_id": int64(10)})
+			client.Script.On("SELECT payload_bin").After(3).Times(2).Fail(
+				fake.NewError(dbinterface.ErrorClassConnectionLost, "server closed the connection"))
+			client.Script.On("SELECT payload_bin").Delay(time.Millisecond).
+				Return(map[string]any{"payload_bin": []byte("abc")})
+			Expect(lobperformance.ExecuteTest(ctx, dbclient.Postgres, client, "s", "t", "1", 1, 1, 1,
+				"scattered", "blob", dbinterface.SessionSettings{LockTimeout: time.Second},
+				testrunner.ReconnectPolicy{Enabled: true, Backoff: time.Millisecond}, nil)).To(Succeed())
+			// the metadata connection, the reader and two reconnects
+			Expect(client.Script.Count(fake.StmtConnect)).To(Equal(4))
+			// the session settings are applied to the new connections as well
+			Expect(client.Script.Count(fake.StmtLockTimeout)).To(Equal(3))
+		})
+	})
+
+	Context("SQLite", func() {
+		It("should create the table in the attached schema", func() {
+			Expect(lobperformance.Stage(ctx, dbclient.SQLite, client, "s", "t")).To(Succeed())
+			Expect(client.Script.Count("CREATE SCHEMA")).To(BeZero())
+			Expect(client.Script.Count("payload_bin BLOB")).To(Equal(1))
+		})
+		It("should insert text and binary payloads with placeholders", func() {
+			Expect(lobperformance.Generate(ctx, dbclient.SQLite, client, "s", "t",
+				[]string{"100%:8b"}, 0, "80b", 10, "clob")).To(Succeed())
+			Expect(client.Script.Count("INSERT INTO s.t (tenant_id, doc_type, payload_text) VALUES (?, ?, ?)")).
+				To(Equal(1 + 10))
+			Expect(lobperformance.Generate(ctx, dbclient.SQLite, client, "s", "t",
+				[]string{"100%:8b"}, 0, "80b", 10, "blob")).To(Succeed())
+			Expect(client.Script.Count("INSERT INTO s.t (tenant_id, doc_type, payload_bin) VALUES (?, ?, ?)")).
+				To(Equal(1 + 10))
+		})
+		It("should reject unknown LOB types", func() {
+			Expect(lobperformance.Generate(ctx, dbclient.SQLite, client, "s", "t",
+				[]string{"100%:8b"}, 0, "80b", 10, "xml")).NotTo(Succeed())
+		})
+		It("should read LOBs by id", func() {
+			client.Script.On("MIN(id)").Return(map[string]any{"min_id": int64(1), "max_id": int64(10)})
+			client.Script.On("SELECT payload_text").Delay(time.Millisecond).
+				Return(map[string]any{"payload_text": "abc"})
+			Expect(lobperformance.ExecuteTest(ctx, dbclient.SQLite, client, "s", "t", "1", 1, 1, 1,
+				"scattered", "clob", dbinterface.SessionSettings{}, testrunner.ReconnectPolicy{}, nil)).To(Succeed())
+			Expect(client.Script.Count("SELECT payload_text FROM s.t WHERE id = ?")).To(BeNumerically(">", 0))
+		})
+	})
+})
@@ -1,10 +1,10 @@
 package lobperformance
 
 import (
-	"github.com/rs/zerolog/log"
+	"github.com/pgvillage-tools/dbtwool/pkg/logging"
 )
 
-var logger = log.With().Logger()
+var logger = logging.Logger()
 
 const (
 	kilo       = 1024
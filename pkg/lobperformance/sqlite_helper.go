@@ -0,0 +1,94 @@
+package lobperformance
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SQLiteHelper is a helper for generating queries for SQLite.
+// SQLite has no schemas; the client attaches a database file per schema instead.
+type SQLiteHelper struct {
+	schemaName string
+	tableName  string
+}
+
+// CreateSchemaSQL returns a no-op query, as the schema is attached by the client when connecting
+func (helper SQLiteHelper) CreateSchemaSQL() string {
+	sql := "SELECT 1;"
+
+	logger.Debug().Msg(sql)
+	return sql
+}
+
+// CreateTableSQL returns a table query to be used for CLOB data
+func (helper SQLiteHelper) CreateTableSQL() string {
+	sql := fmt.Sprintf(`
+CREATE TABLE IF NOT EXISTS %v.%v (
+  id            INTEGER PRIMARY KEY AUTOINCREMENT,
+  tenant_id     INTEGER NOT NULL,
+  created_at    TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at    TEXT NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  doc_type      TEXT NOT NULL,
+  payload_bin   BLOB,
+  payload_text  TEXT
+);`, helper.schemaName, helper.tableName)
+
+	logger.Debug().Msg(sql)
+	return sql
+}
+
+// CreateInsertLOBRowBaseSQL returns a query for inserting LOB data
+func (helper SQLiteHelper) CreateInsertLOBRowBaseSQL(lobType string) (string, error) {
+	col := helper.PayloadColumnForLOBType(lobType)
+	if col == "" {
+		return "", fmt.Errorf("unsupported lobType %q", lobType)
+	}
+	sql := fmt.Sprintf(`
+INSERT INTO %v.%v (tenant_id, doc_type, %v)
+VALUES (?, ?, ?);`, helper.schemaName, helper.tableName, col)
+
+	logger.Debug().Msg(sql)
+	return sql, nil
+}
+
+// SelectMinMaxIDSQL returns a query to fetch the min and max id of a table
+func (helper SQLiteHelper) SelectMinMaxIDSQL() string {
+	sql := fmt.Sprintf(`
+SELECT
+  MIN(id) AS min_id,
+  MAX(id) AS max_id
+FROM %v.%v;
+`, helper.schemaName, helper.tableName)
+
+	logger.Debug().Msg(sql)
+	return sql
+}
+
+// SelectReadLOBByIDSQL returns a query to fetch a LOB
+func (helper SQLiteHelper) SelectReadLOBByIDSQL(lobType string) (string, error) {
+	col := helper.PayloadColumnForLOBType(lobType)
+	if col == "" {
+		return "", fmt.Errorf("unsupported lobType %q", lobType)
+	}
+
+	sql := fmt.Sprintf(`
+SELECT %v
+FROM %v.%v
+WHERE id = ?;
+`, col, helper.schemaName, helper.tableName)
+
+	logger.Debug().Msg(sql)
+	return sql, nil
+}
+
+// PayloadColumnForLOBType returns the payload type
+func (helper SQLiteHelper) PayloadColumnForLOBType(lobType string) string {
+	switch strings.ToLower(lobType) {
+	case "clob", "text":
+		return "payload_text"
+	case "blob", "bytea":
+		return "payload_bin"
+	default:
+		return ""
+	}
+}
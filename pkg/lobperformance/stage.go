@@ -2,42 +2,40 @@ package lobperformance
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/pgvillage-tools/dbtwool/pkg/dbclient"
 	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+	"github.com/pgvillage-tools/dbtwool/pkg/tracing"
 	"github.com/rs/zerolog/log"
 )
 
 // Stage is the main handler for the staging phase of the LOB tests
 func Stage(ctx context.Context, dbType dbclient.RDBMS, client dbinterface.Client,
-	schemaName string, tableName string) {
+	schemaName string, tableName string) (err error) {
 	var logger = log.With().Logger()
+	ctx, span := tracing.Start(ctx, "lob-performance stage", tracing.RDBMS(dbType),
+		tracing.Table(schemaName, tableName))
+	defer func() { tracing.End(span, err) }()
 
-	logger.Info().Msg("Initiating connection pool.")
-	pool, poolErr := client.Pool(ctx)
-	if poolErr != nil {
-		logger.Fatal().Msgf("Failed to connect: %v", poolErr)
-	}
-
-	logger.Info().Msg("Connecting to database.")
-	conn, connectErr1 := pool.Connect(ctx) //
-	if connectErr1 != nil {
-		logger.Fatal().Msgf("connect error for connection 1: %v", connectErr1)
+	conn, err := connect(ctx, client)
+	if err != nil {
+		return err
 	}
 	defer conn.Close(ctx)
 
 	logger.Info().Msg("Starting transaction")
 	if err := conn.Begin(ctx); err != nil {
-		logger.Fatal().Msgf("error during begin transaction on connection: %v", err)
+		return fmt.Errorf("error during begin transaction on connection: %w", err)
 	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = conn.Rollback(ctx)
+		}
+	}()
 
-	var dbHelper DBHelper
-
-	if dbType == dbclient.DB2 {
-		dbHelper = DB2Helper{schemaName: schemaName, tableName: tableName}
-	} else {
-		dbHelper = PGHelper{schemaName: schemaName, tableName: tableName}
-	}
+	dbHelper := newDBHelper(dbType, schemaName, tableName)
 
 	logger.Info().Msg("Executing create schema")
 
@@ -48,14 +46,17 @@ func Stage(ctx context.Context, dbType dbclient.RDBMS, client dbinterface.Client
 	}
 
 	logger.Info().Msg("Executing create table")
-	if rowsAltered, err := conn.Execute(ctx, dbHelper.CreateTableSQL()); err != nil {
-		logger.Fatal().Msgf("Error while creating the table: %v", err)
-	} else {
-		logger.Info().Msgf("Rows altered: %v", rowsAltered)
+	rowsAltered, err := conn.Execute(ctx, dbHelper.CreateTableSQL())
+	if err != nil {
+		return fmt.Errorf("error while creating the table: %w", err)
 	}
+	logger.Info().Msgf("Rows altered: %v", rowsAltered)
 
-	conn.Commit(ctx)
+	if err := conn.Commit(ctx); err != nil {
+		return fmt.Errorf("error while committing transaction: %w", err)
+	}
+	committed = true
 
 	logger.Info().Msg("Closing connection")
-	conn.Close(ctx)
+	return nil
 }
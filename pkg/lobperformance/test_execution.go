@@ -12,6 +12,11 @@ import (
 
 	"github.com/pgvillage-tools/dbtwool/pkg/dbclient"
 	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+	"github.com/pgvillage-tools/dbtwool/pkg/livemetrics"
+	"github.com/pgvillage-tools/dbtwool/pkg/result"
+	"github.com/pgvillage-tools/dbtwool/pkg/stats"
+	"github.com/pgvillage-tools/dbtwool/pkg/testrunner"
+	"github.com/pgvillage-tools/dbtwool/pkg/tracing"
 )
 
 // ExecuteTest executes the performance test
@@ -27,7 +32,13 @@ func ExecuteTest(
 	executionTime int,
 	readMode string,
 	lobType string,
-) error {
+	settings dbinterface.SessionSettings,
+	reconnect testrunner.ReconnectPolicy,
+	run *result.Run,
+) (err error) {
+	ctx, span := tracing.Start(ctx, "lob-performance test", tracing.RDBMS(dbType), tracing.Table(schemaName, tableName),
+		tracing.LobType(lobType))
+	defer func() { tracing.End(span, err) }()
 	dbHelper := newDBHelper(dbType, schemaName, tableName)
 
 	logger := log.With().
@@ -37,7 +48,7 @@ func ExecuteTest(
 		Str("lob_type", lobType).
 		Logger()
 
-	parallel, warmupTime, executionTime, err := normalizeArgs(parallel, warmupTime, executionTime)
+	parallel, warmupTime, executionTime, err = normalizeArgs(parallel, warmupTime, executionTime)
 	if err != nil {
 		return err
 	}
@@ -52,6 +63,9 @@ func ExecuteTest(
 		return fmt.Errorf("failed to determine column to select from based on lobType: %s", lobType)
 	}
 
+	if err = dbinterface.EnsurePoolSize(client, parallel+run.Conns()); err != nil {
+		return err
+	}
 	pool, err := client.Pool(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to init pool: %w", err)
@@ -74,9 +88,11 @@ func ExecuteTest(
 
 	metaConn.Close(ctx)
 
+	run.Start(ctx, pool)
+	defer run.Stop(ctx)
 	logger.Info().Msgf("Starting read test with parallel=%d (max_id=%d)", parallel, maxID)
 
-	startTime, reads, err := runReaders(
+	startTime, readOp, outages, err := runReaders(
 		ctx,
 		pool,
 		readSQL,
@@ -88,30 +104,33 @@ func ExecuteTest(
 		parallel,
 		warmupTime,
 		executionTime,
+		settings,
+		reconnect,
+		run,
 	)
 	if err != nil {
 		return err
 	}
 
+	reads := readOp.Count()
 	readsPerSec := computeReadsPerSec(startTime, reads, executionTime)
+	summary := readOp.Summarize(time.Since(startTime))
+	summary.PerSecond = readsPerSec
+	run.Finish(ctx, []stats.OperationSummary{summary}, outages.Reconnects(), outages.Downtime())
 
 	logger.Info().
 		Int("parallel", parallel).
 		Int64("reads", reads).
 		Float64("reads_per_sec", readsPerSec).
+		Float64("p99_ms", summary.Latency.P99Ms).
+		Int("reconnects", outages.Reconnects()).
+		Dur("downtime", outages.Downtime()).
 		Str("column", col).
 		Msg("Read test finished")
 
 	return nil
 }
 
-func newDBHelper(dbType dbclient.RDBMS, schema, table string) DBHelper {
-	if dbType == dbclient.DB2 {
-		return DB2Helper{schemaName: schema, tableName: table}
-	}
-	return PGHelper{schemaName: schema, tableName: table}
-}
-
 func normalizeArgs(parallel, warmupTime, executionTime int) (int, int, int, error) {
 	if parallel <= 0 {
 		return 0, 0, 0, errors.New("parallel must be > 0")
@@ -167,85 +186,57 @@ func runReaders(
 	parallel int,
 	warmupTime int,
 	executionTime int,
-) (time.Time, int64, error) {
+	settings dbinterface.SessionSettings,
+	reconnect testrunner.ReconnectPolicy,
+	run *result.Run,
+) (time.Time, *stats.Operation, *testrunner.Outages, error) {
 	logger.Info().Msgf("Acquiring %v connections from pool.", parallel)
-	conns, err := openWorkerConns(parent, pool, parallel, 60*time.Second)
+	conns, err := testrunner.OpenConns(parent, pool, parallel, 60*time.Second)
 	if err != nil {
-		return time.Time{}, 0, err
+		return time.Time{}, nil, nil, err
+	}
+	defer func() { testrunner.CloseAll(parent, conns) }()
+
+	if err := settings.ApplyAll(parent, conns); err != nil {
+		return time.Time{}, nil, nil, err
 	}
-	defer closeAll(parent, conns)
+	reconnector := testrunner.NewReconnector(reconnect, pool, settings.Apply)
 
 	logger.Info().Msg("Acquiring connections finished.")
 
-	warmupCtx, totalCtx, cancel := makeWarmupAndTotalContexts(parent, warmupTime, executionTime)
+	warmupCtx, totalCtx, cancel := testrunner.WarmupAndTotalContexts(parent, warmupTime, executionTime)
 	defer cancel()
 
 	safeRng, err := buildSafeRng(minID, maxID, readMode, rngSeed)
 	if err != nil {
-		return time.Time{}, 0, err
+		return time.Time{}, nil, nil, err
 	}
 
 	var measuring atomic.Int32
-	var readCount atomic.Int64
 	var startTime atomic.Value // stores time.Time
+	readOp := stats.NewOperation("read")
+	livemetrics.RegisterOperations("lob-performance", readOp)
+	run.Track(readOp)
 
 	logger.Info().Msg("Starting workers.")
-	errCh := startWorkers(parallel, conns, func(workerID int, conn dbinterface.Connection) error {
-		return readerWorkerLoop(totalCtx, workerID, conn, safeRng, readSQL, col, &measuring, &readCount)
+	errCh := testrunner.StartWorkers(parallel, conns, func(workerID int, _ dbinterface.Connection) error {
+		// a replaced connection is stored in conns, so that it is closed at the end
+		return readerWorkerLoop(totalCtx, workerID, &conns[workerID], reconnector, safeRng, readSQL, col,
+			&measuring, readOp)
 	})
 
 	<-warmupCtx.Done()
 	logger.Info().Msg("Warmup finished. Starting measurements.")
 	measuring.Store(1)
 	startTime.Store(time.Now())
+	run.MeasurementStarted()
 
 	<-totalCtx.Done()
 
-	if firstErr := collectFirstError(errCh, parallel); firstErr != nil {
-		return time.Time{}, 0, firstErr
-	}
-	return resolveStartTime(&startTime, executionTime), readCount.Load(), nil
-}
-
-func openWorkerConns(
-	parent context.Context,
-	pool dbinterface.Pool,
-	parallel int,
-	timeout time.Duration,
-) ([]dbinterface.Connection, error) {
-	conns := make([]dbinterface.Connection, 0, parallel)
-	for i := 0; i < parallel; i++ {
-		connectCtx, cancel := context.WithTimeout(parent, timeout)
-		conn, err := pool.Connect(connectCtx)
-		cancel()
-		if err != nil {
-			closeAll(parent, conns)
-			return nil, fmt.Errorf("worker %d connect failed: %w", i, err)
-		}
-		conns = append(conns, conn)
-	}
-	return conns, nil
-}
-
-func closeAll(ctx context.Context, conns []dbinterface.Connection) {
-	for _, c := range conns {
-		_ = c.Close(ctx)
+	if firstErr := testrunner.CollectFirstError(errCh, parallel); firstErr != nil {
+		return time.Time{}, nil, nil, firstErr
 	}
-}
-
-func makeWarmupAndTotalContexts(
-	parent context.Context,
-	warmupTime int,
-	executionTime int,
-) (context.Context, context.Context, func()) {
-	warmupCtx, cancelWarmup := context.WithTimeout(parent, time.Duration(warmupTime)*time.Second)
-	totalCtx, cancelTotal := context.WithTimeout(parent, time.Duration(warmupTime+executionTime)*time.Second)
-
-	cancel := func() {
-		cancelWarmup()
-		cancelTotal()
-	}
-	return warmupCtx, totalCtx, cancel
+	return resolveStartTime(&startTime, executionTime), readOp, reconnector.Outages(), nil
 }
 
 func buildSafeRng(minID, maxID int, readMode string, rngSeed int64) (*SafeRandGenerator, error) {
@@ -256,29 +247,16 @@ func buildSafeRng(minID, maxID int, readMode string, rngSeed int64) (*SafeRandGe
 	return NewSafeRandGenerator(rg), nil
 }
 
-func startWorkers(
-	parallel int,
-	conns []dbinterface.Connection,
-	fn func(workerID int, conn dbinterface.Connection) error,
-) <-chan error {
-	errCh := make(chan error, parallel)
-	for i := 0; i < parallel; i++ {
-		workerID := i
-		conn := conns[i]
-		go func() { errCh <- fn(workerID, conn) }()
-	}
-	return errCh
-}
-
 func readerWorkerLoop(
 	ctx context.Context,
 	workerID int,
-	conn dbinterface.Connection,
+	conn *dbinterface.Connection,
+	reconnector *testrunner.Reconnector,
 	safeRng *SafeRandGenerator,
 	readSQL string,
 	col string,
 	measuring *atomic.Int32,
-	readCount *atomic.Int64,
+	readOp *stats.Operation,
 ) error {
 	for {
 		if ctx.Err() != nil {
@@ -286,34 +264,35 @@ func readerWorkerLoop(
 		}
 
 		id := safeRng.NextRand()
-		row, qErr := conn.QueryOneRow(ctx, readSQL, int64(id))
+		queryCtx, span := tracing.StartQuery(ctx, "lob-performance read", tracing.RowID(int64(id)))
+		start := time.Now()
+		row, qErr := (*conn).QueryOneRow(queryCtx, readSQL, int64(id))
 		if qErr != nil {
+			tracing.End(span, qErr)
 			if ctx.Err() != nil {
 				return nil
 			}
+			if reconnector.Recover(ctx, workerID, conn, qErr) {
+				continue
+			}
 			return fmt.Errorf("worker %d read failed (id=%d): %w", workerID, id, qErr)
 		}
 
 		v, ok := row[col]
 		if !ok {
-			return fmt.Errorf("worker %d: column %q not found in result", workerID, col)
+			err := fmt.Errorf("worker %d: column %q not found in result", workerID, col)
+			tracing.End(span, err)
+			return err
 		}
 
-		touchValue(v)
+		size := touchValue(v)
+		span.SetAttributes(tracing.Bytes(size))
+		tracing.End(span, nil)
+		livemetrics.AddBytesRead(size)
 		if measuring.Load() == 1 {
-			readCount.Add(1)
-		}
-	}
-}
-
-func collectFirstError(errCh <-chan error, parallel int) error {
-	var firstErr error
-	for i := 0; i < parallel; i++ {
-		if wErr := <-errCh; wErr != nil && firstErr == nil {
-			firstErr = wErr
+			readOp.Observe(time.Since(start))
 		}
 	}
-	return firstErr
 }
 
 func resolveStartTime(startTime *atomic.Value, executionTime int) time.Time {
@@ -325,20 +304,23 @@ func resolveStartTime(startTime *atomic.Value, executionTime int) time.Time {
 	return time.Now().Add(-time.Duration(executionTime) * time.Second)
 }
 
-func touchValue(v any) {
+// touchValue makes sure a value is read, and returns its size in bytes
+func touchValue(v any) int64 {
 	switch t := v.(type) {
 	case []byte:
 		if len(t) > 0 {
 			_ = t[0]
 		}
+		return int64(len(t))
 	case string:
 		if len(t) > 0 {
 			_ = t[0]
 		}
+		return int64(len(t))
 	case nil:
-		return
+		return 0
 	default:
-		_ = fmt.Sprintf("%v", t)
+		return int64(len(fmt.Sprintf("%v", t)))
 	}
 }
 
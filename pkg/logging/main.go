@@ -0,0 +1,142 @@
+// Package logging holds the global zerolog logger, which all packages derive their logger from with Logger, and which
+// all log output of dbtwool goes through. Since the package loggers write through a writer which can be switched,
+// Setup can change the level, format and destination after they were created. Every line has the id of the run and
+// the current phase, and all registered secrets (see package secrets) are masked.
+package logging
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/secrets"
+)
+
+const (
+	// FormatJSON writes every line as a JSON object
+	FormatJSON = "json"
+	// FormatConsole writes human readable lines
+	FormatConsole = "console"
+
+	runIDBytes = 8
+	fileMode   = 0o600
+)
+
+var (
+	output = &switchWriter{w: os.Stderr}
+	runID  = newRunID()
+	phase  atomic.Value
+)
+
+func init() {
+	phase.Store("")
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	log.Logger = zerolog.New(secrets.NewWriter(output)).With().Timestamp().Logger().Hook(contextHook{})
+}
+
+// Logger returns a logger derived from the global logger. Packages should create their logger with this function,
+// so that the global logger is set up before it is copied.
+func Logger() zerolog.Logger {
+	return log.With().Logger()
+}
+
+// Config holds the logging options of the command line
+type Config struct {
+	// Level is a zerolog level (trace, debug, info, warn, error)
+	Level string
+	// Format is FormatJSON or FormatConsole
+	Format string
+	// File is a file where the lines are appended to, next to stderr
+	File string
+}
+
+// Setup applies cfg to the global logger, and to all loggers derived from it. It returns the function which closes
+// the log file.
+func Setup(cfg Config) (func(), error) {
+	level, err := zerolog.ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log level %q: %w", cfg.Level, err)
+	}
+	if cfg.Format != FormatJSON && cfg.Format != FormatConsole {
+		return nil, fmt.Errorf("invalid log format %q, use %s or %s", cfg.Format, FormatJSON, FormatConsole)
+	}
+	writers := []io.Writer{format(os.Stderr, cfg.Format, false)}
+	closeFile := func() {}
+	if cfg.File != "" {
+		// #nosec G304 -- the log file is chosen by the user running dbtwool
+		f, openErr := os.OpenFile(cfg.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, fileMode)
+		if openErr != nil {
+			return nil, fmt.Errorf("failed to open the log file: %w", openErr)
+		}
+		writers = append(writers, format(f, cfg.Format, true))
+		closeFile = func() {
+			output.set(os.Stderr)
+			_ = f.Close()
+		}
+	}
+	zerolog.SetGlobalLevel(level)
+	output.set(io.MultiWriter(writers...))
+	return closeFile, nil
+}
+
+func format(w io.Writer, logFormat string, noColor bool) io.Writer {
+	if logFormat == FormatConsole {
+		return zerolog.ConsoleWriter{Out: w, NoColor: noColor, TimeFormat: "2006-01-02T15:04:05Z07:00"}
+	}
+	return w
+}
+
+// RunID returns the id of this run of dbtwool, which is added to every log line and to the result document
+func RunID() string {
+	return runID
+}
+
+// SetPhase sets the phase (e.g. gen, warmup or measurement) which is added to every log line from now on
+func SetPhase(p string) {
+	phase.Store(p)
+}
+
+func newRunID() string {
+	id := make([]byte, runIDBytes)
+	if _, err := rand.Read(id); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(id)
+}
+
+// contextHook adds the run id and the phase to every line
+type contextHook struct{}
+
+// Run implements zerolog.Hook
+func (contextHook) Run(e *zerolog.Event, _ zerolog.Level, _ string) {
+	e.Str("run_id", runID)
+	if p, _ := phase.Load().(string); p != "" {
+		e.Str("phase", p)
+	}
+}
+
+// switchWriter writes to a writer which can be replaced while loggers write to it
+type switchWriter struct {
+	mu sync.RWMutex
+	w  io.Writer
+}
+
+func (s *switchWriter) set(w io.Writer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.w = w
+}
+
+// Write implements io.Writer
+func (s *switchWriter) Write(p []byte) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.w.Write(p)
+}
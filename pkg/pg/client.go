@@ -3,6 +3,8 @@ package pg
 
 import (
 	"context"
+	"fmt"
+	"math"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
@@ -11,6 +13,8 @@ import (
 // Client is the main object to connect to PostgreSQL
 type Client struct {
 	ConnectParams ConnParams
+	PoolSettings  dbinterface.PoolSettings
+	requiredConns int
 	pool          *Pool
 }
 
@@ -23,25 +27,92 @@ func NewClient(connectionParams ConnParams) Client {
 
 // Pool will connect to PostgreSQL and return a new PostgreSQL pool
 func (cl *Client) Pool(ctx context.Context) (dbinterface.Pool, error) {
+	created := false
 	if cl.pool == nil {
-		cfg, err := pgxpool.ParseConfig(cl.ConnectParams.GetConnString())
+		logger.Debug().Msgf("Connecting to %s", cl.ConnectParams)
+		cfg, err := cl.PoolConfig()
 		if err != nil {
 			return Pool{}, err
 		}
 
-		cfg.MaxConns = maxPoolSizeDefault
-		cfg.MinConns = minPoolSizeDefault
-
 		p, err := pgxpool.NewWithConfig(ctx, cfg)
 		if err != nil {
 			return Pool{}, err
 		}
 		cl.pool = &Pool{pool: p}
+		created = true
 	}
 
 	if err := cl.pool.validate(ctx); err != nil {
 		cl.pool = nil
 		return Pool{}, err
 	}
+	if created {
+		if err := cl.pool.logTLS(ctx); err != nil {
+			return Pool{}, err
+		}
+	}
 	return *cl.pool, nil
 }
+
+// EnsurePoolSize implements dbinterface.PoolSizer. Without maxConns the pool is sized to the largest number of
+// connections requested before it is created.
+func (cl *Client) EnsurePoolSize(conns int) error {
+	if err := cl.PoolSettings.CheckSize(conns); err != nil {
+		return err
+	}
+	if cl.pool != nil {
+		if maxConns := int(cl.pool.pool.Config().MaxConns); maxConns < conns {
+			return fmt.Errorf("%w: the test needs %d connections at once, but the pool was created for %d",
+				dbinterface.ErrPoolTooSmall, conns, maxConns)
+		}
+		return nil
+	}
+	cl.requiredConns = max(cl.requiredConns, conns)
+	return nil
+}
+
+// PoolConfig returns the pgxpool configuration for the connection parameters and pool settings
+func (cl *Client) PoolConfig() (*pgxpool.Config, error) {
+	cp := cl.ConnectParams
+	cfg, err := pgxpool.ParseConfig(cp.GetConnString())
+	if err != nil {
+		return nil, err
+	}
+
+	settings := cl.PoolSettings
+	cfg.MaxConns = toInt32(max(maxPoolSizeDefault, cl.requiredConns))
+	if settings.MaxConns > 0 {
+		cfg.MaxConns = toInt32(settings.MaxConns)
+	}
+	cfg.MinConns = minPoolSizeDefault
+	if settings.MinConns > 0 {
+		cfg.MinConns = toInt32(min(settings.MinConns, int(cfg.MaxConns)))
+	}
+	if settings.MaxConnLifetime > 0 {
+		cfg.MaxConnLifetime = settings.MaxConnLifetime
+	}
+	if settings.MaxConnIdleTime > 0 {
+		cfg.MaxConnIdleTime = settings.MaxConnIdleTime
+	}
+	if settings.HealthCheckPeriod > 0 {
+		cfg.HealthCheckPeriod = settings.HealthCheckPeriod
+	}
+	if settings.ConnectTimeout > 0 {
+		cfg.ConnConfig.ConnectTimeout = settings.ConnectTimeout
+	}
+
+	if cp.SslCrl != "" {
+		if err = applyCRL(&cfg.ConnConfig.Config, cp.SslCrl, cp.SslRootCert); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+func toInt32(i int) int32 {
+	if i > math.MaxInt32 {
+		return math.MaxInt32
+	}
+	return int32(i)
+}
@@ -0,0 +1,59 @@
+package pg_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+	"github.com/pgvillage-tools/dbtwool/pkg/pg"
+)
+
+var _ = Describe("Client", func() {
+	params := pg.ConnParams{Host: "myhost", Port: "5432", Database: "mydb", User: "myuser"}
+	Describe("PoolConfig", func() {
+		It("should keep the default size for a small test", func() {
+			client := pg.NewClient(params)
+			Expect(client.EnsurePoolSize(4)).To(Succeed())
+			cfg, err := client.PoolConfig()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.MaxConns).To(BeEquivalentTo(33))
+			Expect(cfg.MinConns).To(BeEquivalentTo(1))
+		})
+		It("should size the pool to the parallelism of the test", func() {
+			client := pg.NewClient(params)
+			Expect(client.EnsurePoolSize(64)).To(Succeed())
+			Expect(client.EnsurePoolSize(2)).To(Succeed())
+			cfg, err := client.PoolConfig()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.MaxConns).To(BeEquivalentTo(64))
+		})
+		It("should apply the pool settings", func() {
+			client := pg.NewClient(params)
+			client.PoolSettings = dbinterface.PoolSettings{
+				MaxConns:          10,
+				MinConns:          20,
+				MaxConnLifetime:   time.Hour,
+				MaxConnIdleTime:   time.Minute,
+				HealthCheckPeriod: 5 * time.Second,
+				ConnectTimeout:    3 * time.Second,
+			}
+			cfg, err := client.PoolConfig()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.MaxConns).To(BeEquivalentTo(10))
+			Expect(cfg.MinConns).To(BeEquivalentTo(10))
+			Expect(cfg.MaxConnLifetime).To(Equal(time.Hour))
+			Expect(cfg.MaxConnIdleTime).To(Equal(time.Minute))
+			Expect(cfg.HealthCheckPeriod).To(Equal(5 * time.Second))
+			Expect(cfg.ConnConfig.ConnectTimeout).To(Equal(3 * time.Second))
+		})
+	})
+	Describe("EnsurePoolSize", func() {
+		It("should fail fast when the test needs more connections than maxConns", func() {
+			client := pg.NewClient(params)
+			client.PoolSettings.MaxConns = 8
+			Expect(client.EnsurePoolSize(64)).To(MatchError(dbinterface.ErrPoolTooSmall))
+		})
+	})
+})
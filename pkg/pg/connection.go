@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -14,6 +15,8 @@ import (
 type Connection struct {
 	pconn *pgxpool.Conn
 	tx    pgx.Tx
+	// isoLevel is set on every transaction, so that it does not stick to the connection when it is released
+	isoLevel pgx.TxIsoLevel
 }
 
 // Close closes the connection
@@ -26,25 +29,48 @@ func (c *Connection) Close(ctx context.Context) error {
 	return nil
 }
 
-// SetIsolationLevel can be used to change the isolation level on a connection
-func (c *Connection) SetIsolationLevel(ctx context.Context, isoLevel dbinterface.IsolationLevel) error {
-	qryIsoLevel := isoLevel.AsQuery()
-	logger.Info().Msgf("Set Isolation level: %s", qryIsoLevel)
-	if err := c.Begin(ctx); err != nil {
-		return err
+// SetIsolationLevel can be used to change the isolation level of the transactions on a connection
+func (c *Connection) SetIsolationLevel(_ context.Context, isoLevel dbinterface.IsolationLevel) error {
+	if c.tx != nil {
+		return errors.New("cannot change the isolation level while a transaction is active")
 	}
-	if _, err := c.Execute(ctx, qryIsoLevel); err != nil {
-		return err
+	pgIsoLevel, ok := isoLevel.(IsolationLevel)
+	if !ok {
+		return fmt.Errorf("%q does not set a PostgreSQL isolation level", isoLevel.AsQuery())
+	}
+	logger.Info().Msgf("Set Isolation level: %s", pgIsoLevel.AsQuery())
+	c.isoLevel = pgIsoLevel.txIsoLevel()
+	return nil
+}
+
+// SetLockTimeout sets how long a statement waits for a lock before it fails. 0 means wait forever.
+func (c *Connection) SetLockTimeout(ctx context.Context, timeout time.Duration) error {
+	return c.setTimeout(ctx, "lock_timeout", timeout)
+}
+
+// SetStatementTimeout sets how long a statement may run before it is canceled. 0 means no limit.
+func (c *Connection) SetStatementTimeout(ctx context.Context, timeout time.Duration) error {
+	return c.setTimeout(ctx, "statement_timeout", timeout)
+}
+
+func (c *Connection) setTimeout(ctx context.Context, parameter string, timeout time.Duration) error {
+	qry := fmt.Sprintf("SET %s = %d", parameter, timeout.Milliseconds())
+	logger.Info().Msgf("Set timeout: %s", qry)
+	var err error
+	if c.tx != nil {
+		_, err = c.tx.Exec(ctx, qry)
+	} else {
+		_, err = c.pconn.Exec(ctx, qry)
 	}
-	return c.Commit(ctx)
+	return err
 }
 
-// Execute will execute a query and return number of affected rows
-func (c *Connection) Execute(ctx context.Context, sql string) (int64, error) {
+// Execute will execute a query with its arguments and return number of affected rows
+func (c *Connection) Execute(ctx context.Context, sql string, args ...any) (int64, error) {
 	if c.tx == nil {
 		return 0, errors.New("Execute requires an active transaction; call Begin() first")
 	}
-	ct, err := c.tx.Exec(ctx, sql)
+	ct, err := c.tx.Exec(ctx, sql, args...)
 	if err != nil {
 		return 0, err
 	}
@@ -89,7 +115,7 @@ func (c *Connection) Begin(ctx context.Context) error {
 	if c.tx != nil {
 		return errors.New("transaction already active")
 	}
-	tx, err := c.pconn.Conn().Begin(ctx)
+	tx, err := c.pconn.Conn().BeginTx(ctx, pgx.TxOptions{IsoLevel: c.isoLevel})
 	if err != nil {
 		return err
 	}
@@ -2,7 +2,9 @@ package pg
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/pgvillage-tools/dbtwool/pkg/secrets"
 	"github.com/pgvillage-tools/dbtwool/pkg/utils"
 )
 
@@ -14,29 +16,96 @@ type ConnParams struct {
 	User     string
 	Password string
 	SslMode  string
+	// SslRootCert, SslCert, SslKey and SslPassword (of the key) are the TLS files, as in libpq
+	SslRootCert string
+	SslCert     string
+	SslKey      string
+	SslPassword string
+	// SslCrl is a file with revoked server certificates. pgx has no sslcrl, so it is checked by the Client.
+	SslCrl string
+	// Host and Port can hold comma separated lists for a multi-host setup, where TargetSessionAttrs (read-write,
+	// primary, any, etc.) selects the server to connect to
+	TargetSessionAttrs string
 }
 
 // GetConnString builds and returns a string that can be used to connect to PostgreSQL
 func (cp ConnParams) GetConnString() string {
-	return fmt.Sprintf(
-		"host=%s port=%s dbname=%s user=%s password=%s sslmode=%s application_name=pgtwool",
-		cp.Host,
-		cp.Port,
-		cp.Database,
-		cp.User,
-		cp.Password,
-		cp.SslMode,
-	)
+	return cp.connString(cp.Password)
+}
+
+// Redacted returns the connection string with the password masked, so that it can be logged
+func (cp ConnParams) Redacted() string {
+	if cp.Password != "" {
+		cp.Password = secrets.Mask
+	}
+	if cp.SslPassword != "" {
+		cp.SslPassword = secrets.Mask
+	}
+	return cp.connString(cp.Password)
+}
+
+// String implements fmt.Stringer and returns the redacted connection string, so that printing ConnParams never
+// reveals the password
+func (cp ConnParams) String() string {
+	return cp.Redacted()
+}
+
+func (cp ConnParams) connString(password string) string {
+	var l []string
+	for _, kv := range []struct {
+		key      string
+		value    string
+		optional bool
+	}{
+		{key: "host", value: cp.Host},
+		{key: "port", value: cp.Port},
+		{key: "dbname", value: cp.Database},
+		{key: "user", value: cp.User},
+		// without a password pgx looks it up in PGPASSFILE or ~/.pgpass
+		{key: "password", value: password, optional: true},
+		{key: "sslmode", value: cp.SslMode},
+		{key: "sslrootcert", value: cp.SslRootCert, optional: true},
+		{key: "sslcert", value: cp.SslCert, optional: true},
+		{key: "sslkey", value: cp.SslKey, optional: true},
+		{key: "sslpassword", value: cp.SslPassword, optional: true},
+		{key: "target_session_attrs", value: cp.TargetSessionAttrs, optional: true},
+		{key: "application_name", value: "pgtwool"},
+	} {
+		if kv.optional && kv.value == "" {
+			continue
+		}
+		l = append(l, fmt.Sprintf("%s=%s", kv.key, quoteConnValue(kv.value)))
+	}
+	return strings.Join(l, " ")
 }
 
 // ConnParamsFromEnv generates a new default ConnParams from env variables with defaults
 func ConnParamsFromEnv() ConnParams {
+	return ConnParamsFromEnvWithDefaults(ConnParams{})
+}
+
+// ConnParamsFromEnvWithDefaults generates a new ConnParams from env variables. Fields which are not set in the
+// environment are taken from defaults (e.a. a connection profile), and from the built-in defaults when empty there.
+func ConnParamsFromEnvWithDefaults(defaults ConnParams) ConnParams {
 	return ConnParams{
-		Host:     utils.GetEnv("PGHOST", "localhost"),
-		Port:     utils.GetEnv("PGPORT", "5432"),
-		Database: utils.GetEnv("PGDATABASE", "postgres"),
-		User:     utils.GetEnv("PGUSER", "postgres"),
-		Password: utils.GetEnv("PGPASSWORD", "postgres"),
-		SslMode:  utils.GetEnv("PGSSLMODE", "allow"),
+		Host:               utils.GetEnv("PGHOST", orDefault(defaults.Host, "localhost")),
+		Port:               utils.GetEnv("PGPORT", orDefault(defaults.Port, "5432")),
+		Database:           utils.GetEnv("PGDATABASE", orDefault(defaults.Database, "postgres")),
+		User:               utils.GetEnv("PGUSER", orDefault(defaults.User, "postgres")),
+		Password:           utils.GetEnv("PGPASSWORD", defaults.Password),
+		SslMode:            utils.GetEnv("PGSSLMODE", orDefault(defaults.SslMode, "allow")),
+		SslRootCert:        utils.GetEnv("PGSSLROOTCERT", defaults.SslRootCert),
+		SslCert:            utils.GetEnv("PGSSLCERT", defaults.SslCert),
+		SslKey:             utils.GetEnv("PGSSLKEY", defaults.SslKey),
+		SslPassword:        utils.GetEnv("PGSSLPASSWORD", defaults.SslPassword),
+		SslCrl:             utils.GetEnv("PGSSLCRL", defaults.SslCrl),
+		TargetSessionAttrs: utils.GetEnv("PGTARGETSESSIONATTRS", defaults.TargetSessionAttrs),
+	}
+}
+
+func orDefault(value string, def string) string {
+	if value != "" {
+		return value
 	}
+	return def
 }
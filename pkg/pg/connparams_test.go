@@ -2,6 +2,9 @@ package pg_test
 
 import (
 	"os"
+	"path/filepath"
+
+	"github.com/jackc/pgx/v5/pgxpool"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -31,7 +34,7 @@ var _ = Describe("ConnParams", func() {
 
 		BeforeEach(func() {
 			originalEnv = make(map[string]string)
-			vars := []string{"PGHOST", "PGPORT", "PGDATABASE", "PGUSER", "PGPASSWORD"}
+			vars := []string{"PGHOST", "PGPORT", "PGDATABASE", "PGUSER", "PGPASSWORD", "PGSSLMODE"}
 			for _, v := range vars {
 				originalEnv[v] = os.Getenv(v)
 				os.Unsetenv(v)
@@ -50,7 +53,7 @@ var _ = Describe("ConnParams", func() {
 			Expect(params.Port).To(Equal("5432"))
 			Expect(params.Database).To(Equal("postgres"))
 			Expect(params.User).To(Equal("postgres"))
-			Expect(params.Password).To(Equal("postgres"))
+			Expect(params.Password).To(BeEmpty())
 			Expect(params.SslMode).To(Equal("allow"))
 		})
 
@@ -69,5 +72,31 @@ var _ = Describe("ConnParams", func() {
 			Expect(params.Password).To(Equal("testpass"))
 			Expect(params.SslMode).To(Equal("allow"))
 		})
+
+		It("should prefer env vars over defaults and defaults over the built-in defaults", func() {
+			os.Setenv("PGHOST", "envhost")
+
+			params := pg.ConnParamsFromEnvWithDefaults(pg.ConnParams{
+				Host:    "profilehost",
+				Port:    "5433",
+				SslMode: "require",
+			})
+			Expect(params.Host).To(Equal("envhost"))
+			Expect(params.Port).To(Equal("5433"))
+			Expect(params.Database).To(Equal("postgres"))
+			Expect(params.SslMode).To(Equal("require"))
+		})
+
+		It("should leave the password to the passfile when it is not set", func() {
+			passFile := filepath.Join(GinkgoT().TempDir(), "pgpass")
+			Expect(os.WriteFile(passFile, []byte("pghost:5432:*:tester:frompassfile\n"), 0o600)).To(Succeed())
+			GinkgoT().Setenv("PGPASSFILE", passFile)
+
+			params := pg.ConnParamsFromEnvWithDefaults(pg.ConnParams{Host: "pghost", User: "tester"})
+			Expect(params.GetConnString()).NotTo(ContainSubstring("password"))
+			cfg, err := pgxpool.ParseConfig(params.GetConnString())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.ConnConfig.Password).To(Equal("frompassfile"))
+		})
 	})
 })
@@ -0,0 +1,52 @@
+package pg
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+)
+
+// ConnectFunc implements dbinterface.DirectConnector. Every call opens a new connection with the settings of the
+// pool (including TLS and the connect timeout), but outside of it. Every connection is closed again, so there is
+// nothing to release.
+func (cl *Client) ConnectFunc() (dbinterface.ConnectFunc, func() error, error) {
+	cfg, err := cl.PoolConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	connConfig := cfg.ConnConfig
+	return func(ctx context.Context, query bool) (dbinterface.ConnectTiming, error) {
+		var timing dbinterface.ConnectTiming
+		attemptConfig := connConfig.Copy()
+		start := time.Now()
+		attemptConfig.AfterNetConnect = func(ctx context.Context, _ *pgconn.Config, conn net.Conn) (net.Conn, error) {
+			// pgx only starts the TLS handshake with the first write, so shake hands here to make it part of the
+			// network time
+			if tlsConn, isTLS := conn.(*tls.Conn); isTLS {
+				if err := tlsConn.HandshakeContext(ctx); err != nil {
+					return conn, err
+				}
+			}
+			// with fallbacks (sslmode prefer), the last attempt is the one which is measured
+			timing.Network = time.Since(start)
+			return conn, nil
+		}
+		conn, err := pgx.ConnectConfig(ctx, attemptConfig)
+		if err != nil {
+			return dbinterface.ConnectTiming{}, err
+		}
+		defer func() { _ = conn.Close(context.Background()) }()
+		if query {
+			if _, err = conn.Exec(ctx, pgTestQuery); err != nil {
+				return dbinterface.ConnectTiming{}, err
+			}
+		}
+		timing.Total = time.Since(start)
+		return timing, nil
+	}, func() error { return nil }, nil
+}
@@ -0,0 +1,89 @@
+package pg_test
+
+import (
+	"context"
+	"net"
+
+	"github.com/jackc/pgx/v5/pgproto3"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/pg"
+)
+
+// serve accepts connections on listener until it is closed, and hands them to handle
+func serve(listener net.Listener, handle func(net.Conn)) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer func() { _ = conn.Close() }()
+			handle(conn)
+		}()
+	}
+}
+
+// trustServer speaks just enough of the PostgreSQL protocol to accept a connection without a password and to run
+// simple queries
+func trustServer(conn net.Conn) {
+	backend := pgproto3.NewBackend(conn, conn)
+	if _, err := backend.ReceiveStartupMessage(); err != nil {
+		return
+	}
+	backend.Send(&pgproto3.AuthenticationOk{})
+	backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+	for backend.Flush() == nil {
+		msg, err := backend.Receive()
+		if err != nil {
+			return
+		}
+		if _, isQuery := msg.(*pgproto3.Query); !isQuery {
+			return
+		}
+		backend.Send(&pgproto3.CommandComplete{CommandTag: []byte("SELECT 1")})
+		backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+	}
+}
+
+// newLocalClient returns a client for a server on listener
+func newLocalClient(listener net.Listener) pg.Client {
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	Expect(err).NotTo(HaveOccurred())
+	return pg.NewClient(pg.ConnParams{Host: host, Port: port, Database: "mydb", User: "myuser", SslMode: "disable"})
+}
+
+var _ = Describe("ConnectFunc", func() {
+	var listener net.Listener
+	BeforeEach(func() {
+		var err error
+		listener, err = net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(func() { _ = listener.Close() })
+	})
+	It("should measure the network connection, the connection and the test query", func(ctx context.Context) {
+		go serve(listener, trustServer)
+		client := newLocalClient(listener)
+		connect, closeConnect, err := client.ConnectFunc()
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { Expect(closeConnect()).To(Succeed()) }()
+		timing, err := connect(ctx, true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(timing.Network).To(BeNumerically(">", 0))
+		Expect(timing.Total).To(BeNumerically(">=", timing.Network))
+	})
+	It("should return the error of a server which hangs up", func(ctx context.Context) {
+		go serve(listener, func(net.Conn) {})
+		client := newLocalClient(listener)
+		connect, _, err := client.ConnectFunc()
+		Expect(err).NotTo(HaveOccurred())
+		_, err = connect(ctx, false)
+		Expect(err).To(HaveOccurred())
+	})
+	It("should fail for invalid connection parameters", func() {
+		client := pg.NewClient(pg.ConnParams{Host: "myhost", Port: "not a port"})
+		_, _, err := client.ConnectFunc()
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,43 @@
+package pg
+
+import (
+	"errors"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+)
+
+// ClassifyError returns the class of a PostgreSQL error, based on its SQLSTATE
+func ClassifyError(err error) dbinterface.ErrorClass {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case pgErr.Code == "40001":
+			return dbinterface.ErrorClassSerialization
+		case pgErr.Code == "40P01":
+			return dbinterface.ErrorClassDeadlock
+		case pgErr.Code == "55P03":
+			return dbinterface.ErrorClassLockTimeout
+		case pgErr.Code == "57014":
+			return dbinterface.ErrorClassStatementTimeout
+		case strings.HasPrefix(pgErr.Code, "08"), pgErr.Code == "57P01", pgErr.Code == "57P02",
+			pgErr.Code == "57P03":
+			return dbinterface.ErrorClassConnectionLost
+		}
+		return dbinterface.ErrorClassOther
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return dbinterface.ErrorClassConnectionLost
+	}
+	return dbinterface.ErrorClassOther
+}
+
+// ClassifyError implements dbinterface.ErrorClassifier
+func (c *Connection) ClassifyError(err error) dbinterface.ErrorClass {
+	return ClassifyError(err)
+}
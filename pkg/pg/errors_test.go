@@ -0,0 +1,37 @@
+package pg_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+	"github.com/pgvillage-tools/dbtwool/pkg/pg"
+)
+
+var _ = Describe("ClassifyError", func() {
+	DescribeTable("should classify errors by SQLSTATE",
+		func(code string, expected dbinterface.ErrorClass) {
+			err := fmt.Errorf("oltp execute failed: %w", &pgconn.PgError{Code: code})
+			Expect(pg.ClassifyError(err)).To(Equal(expected))
+		},
+		Entry("serialization failure", "40001", dbinterface.ErrorClassSerialization),
+		Entry("deadlock", "40P01", dbinterface.ErrorClassDeadlock),
+		Entry("lock not available", "55P03", dbinterface.ErrorClassLockTimeout),
+		Entry("query canceled", "57014", dbinterface.ErrorClassStatementTimeout),
+		Entry("connection failure", "08006", dbinterface.ErrorClassConnectionLost),
+		Entry("admin shutdown", "57P01", dbinterface.ErrorClassConnectionLost),
+		Entry("unique violation", "23505", dbinterface.ErrorClassOther),
+	)
+	It("should classify a closed connection as connection lost", func() {
+		Expect(pg.ClassifyError(fmt.Errorf("read: %w", io.ErrUnexpectedEOF))).To(
+			Equal(dbinterface.ErrorClassConnectionLost))
+	})
+	It("should classify other errors as other", func() {
+		Expect(pg.ClassifyError(errors.New("boom"))).To(Equal(dbinterface.ErrorClassOther))
+	})
+})
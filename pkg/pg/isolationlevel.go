@@ -1,6 +1,12 @@
 package pg
 
-import "strings"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
 
 // IsolationLevel is used to get rdbms specific queries for basic functions
 type IsolationLevel int
@@ -20,6 +26,26 @@ var (
 		RepeatableRead: "REPEATABLE READ",
 		Serializable:   "SERIALIZABLE",
 	}
+	levelToTxIsoLevel = map[IsolationLevel]pgx.TxIsoLevel{
+		ReadCommitted:  pgx.ReadCommitted,
+		RepeatableRead: pgx.RepeatableRead,
+		Serializable:   pgx.Serializable,
+	}
+	// nameToLevel maps PostgreSQL and DB2 names to the PostgreSQL level which offers the same guarantees.
+	// PostgreSQL never returns uncommitted data, so READ UNCOMMITTED and DB2 UR run as READ COMMITTED.
+	nameToLevel = map[string]IsolationLevel{
+		"READ UNCOMMITTED": ReadCommitted,
+		"READ COMMITTED":   ReadCommitted,
+		"REPEATABLE READ":  RepeatableRead,
+		"SERIALIZABLE":     Serializable,
+		"UR":               ReadCommitted,
+		"UNCOMMITTED READ": ReadCommitted,
+		"CS":               ReadCommitted,
+		"CURSOR STABILITY": ReadCommitted,
+		"RS":               RepeatableRead,
+		"READ STABILITY":   RepeatableRead,
+		"RR":               Serializable,
+	}
 )
 
 // AsQuery can be used to return a query for the isolation level
@@ -33,6 +59,11 @@ func (i IsolationLevel) AsQuery() string {
 	}, " ")
 }
 
+// txIsoLevel returns the isolation level as pgx sets it when a transaction begins
+func (i IsolationLevel) txIsoLevel() pgx.TxIsoLevel {
+	return levelToTxIsoLevel[i]
+}
+
 // AsString can be used to return a string version of the isolation level
 func (i IsolationLevel) AsString() string {
 	return levelToSting[i]
@@ -42,3 +73,17 @@ func (i IsolationLevel) AsString() string {
 func GetIsolationLevel(i int) IsolationLevel {
 	return ReadCommitted + IsolationLevel(i)
 }
+
+// ParseIsolationLevel returns the isolation level for a name (e.g. "read committed", "RS").
+// Invalid values return an error.
+func ParseIsolationLevel(value string) (IsolationLevel, error) {
+	// an index means a different level on every RDBMS (e.g. 1 is REPEATABLE READ on PostgreSQL and CS on DB2)
+	if _, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+		return ReadCommitted, fmt.Errorf("isolation level %q is an index, use a name like 'read committed' instead", value)
+	}
+	name := strings.Join(strings.Fields(strings.ToUpper(strings.NewReplacer("_", " ", "-", " ").Replace(value))), " ")
+	if level, exists := nameToLevel[name]; exists {
+		return level, nil
+	}
+	return ReadCommitted, fmt.Errorf("invalid isolation level %q", value)
+}
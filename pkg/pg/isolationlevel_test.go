@@ -61,4 +61,34 @@ var _ = ginkgo.Describe("IsolationLevel", func() {
 			Expect(level.AsQuery()).To(Equal("SET TRANSACTION ISOLATION LEVEL "))
 		})
 	})
+
+	ginkgo.DescribeTable("ParseIsolationLevel should map valid values",
+		func(value string, expected pg.IsolationLevel) {
+			level, err := pg.ParseIsolationLevel(value)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(level).To(Equal(expected))
+		},
+		ginkgo.Entry("read committed", "read committed", pg.ReadCommitted),
+		ginkgo.Entry("READ_COMMITTED", "READ_COMMITTED", pg.ReadCommitted),
+		ginkgo.Entry("read uncommitted", "Read  Uncommitted", pg.ReadCommitted),
+		ginkgo.Entry("repeatable-read", "repeatable-read", pg.RepeatableRead),
+		ginkgo.Entry("serializable", "serializable", pg.Serializable),
+		ginkgo.Entry("UR", "ur", pg.ReadCommitted),
+		ginkgo.Entry("CS", "CS", pg.ReadCommitted),
+		ginkgo.Entry("RS", "RS", pg.RepeatableRead),
+		ginkgo.Entry("RR", "RR", pg.Serializable),
+	)
+
+	ginkgo.DescribeTable("ParseIsolationLevel should reject invalid values",
+		func(value string) {
+			_, err := pg.ParseIsolationLevel(value)
+			Expect(err).To(HaveOccurred())
+		},
+		ginkgo.Entry("empty", ""),
+		ginkgo.Entry("unknown name", "snapshot"),
+		ginkgo.Entry("index 1", "1"),
+		ginkgo.Entry("index 2", " 2 "),
+		ginkgo.Entry("index too high", "3"),
+		ginkgo.Entry("negative index", "-1"),
+	)
 })
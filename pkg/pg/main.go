@@ -1,8 +1,8 @@
 package pg
 
-import "github.com/rs/zerolog/log"
+import "github.com/pgvillage-tools/dbtwool/pkg/logging"
 
-var logger = log.With().Logger()
+var logger = logging.Logger()
 
 const (
 	maxPoolSizeDefault = 33
@@ -0,0 +1,130 @@
+package pg
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// applyCRL makes every TLS config of cfg (including the fallbacks for sslmode allow and prefer) reject server
+// certificates which are revoked in crlFile. The CRL should be signed by a certificate in rootCertFile or in the
+// certificate chain of the server.
+func applyCRL(cfg *pgconn.Config, crlFile string, rootCertFile string) error {
+	crl, err := loadCRL(crlFile)
+	if err != nil {
+		return err
+	}
+	var issuers []*x509.Certificate
+	if rootCertFile != "" {
+		if issuers, err = loadCertificates(rootCertFile); err != nil {
+			return err
+		}
+	}
+	verify := verifyNotRevoked(crl, issuers)
+	tlsConfigs := []*tls.Config{cfg.TLSConfig}
+	for _, fallback := range cfg.Fallbacks {
+		tlsConfigs = append(tlsConfigs, fallback.TLSConfig)
+	}
+	for _, tlsConfig := range tlsConfigs {
+		if tlsConfig != nil {
+			tlsConfig.VerifyConnection = verify
+		}
+	}
+	return nil
+}
+
+// verifyNotRevoked returns a tls.Config.VerifyConnection function which fails when a certificate of the server is
+// in the crl
+func verifyNotRevoked(
+	crl *x509.RevocationList,
+	issuers []*x509.Certificate,
+) func(tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+		signed := false
+		for _, issuer := range append(issuers, cs.PeerCertificates...) {
+			if bytes.Equal(issuer.RawSubject, crl.RawIssuer) && crl.CheckSignatureFrom(issuer) == nil {
+				signed = true
+				break
+			}
+		}
+		if !signed {
+			return errors.New("the signature of the crl could not be verified")
+		}
+		for _, cert := range cs.PeerCertificates {
+			if !bytes.Equal(cert.RawIssuer, crl.RawIssuer) {
+				continue
+			}
+			for _, revoked := range crl.RevokedCertificateEntries {
+				if revoked.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+					return fmt.Errorf("server certificate %s (serial %s) is revoked", cert.Subject, cert.SerialNumber)
+				}
+			}
+		}
+		return nil
+	}
+}
+
+// loadCRL reads a certificate revocation list in PEM or DER format
+func loadCRL(path string) (*x509.RevocationList, error) {
+	// #nosec G304 -- reading the crl of the users choice is the purpose of sslcrl
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read crl: %w", err)
+	}
+	if block, _ := pem.Decode(content); block != nil {
+		content = block.Bytes
+	}
+	crl, err := x509.ParseRevocationList(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse crl %s: %w", path, err)
+	}
+	return crl, nil
+}
+
+func loadCertificates(path string) ([]*x509.Certificate, error) {
+	// #nosec G304 -- reading the root certificate of the users choice is the purpose of sslrootcert
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read root certificate: %w", err)
+	}
+	var certs []*x509.Certificate
+	for block, rest := pem.Decode(content); block != nil; block, rest = pem.Decode(rest) {
+		cert, parseErr := x509.ParseCertificate(block.Bytes)
+		if parseErr != nil {
+			return nil, fmt.Errorf("failed to parse root certificate %s: %w", path, parseErr)
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+// TLSDescription describes the encryption of a connection for logging
+func TLSDescription(state *tls.ConnectionState) string {
+	if state == nil {
+		return "not encrypted"
+	}
+	return fmt.Sprintf("%s, cipher %s", tls.VersionName(state.Version), tls.CipherSuiteName(state.CipherSuite))
+}
+
+// logTLS logs the TLS version and cipher which were negotiated for a connection from the pool
+func (p Pool) logTLS(ctx context.Context) error {
+	conn, err := p.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+	var state *tls.ConnectionState
+	if tlsConn, ok := conn.Conn().PgConn().Conn().(*tls.Conn); ok {
+		cs := tlsConn.ConnectionState()
+		state = &cs
+	}
+	logger.Info().Msgf("Connection is %s", TLSDescription(state))
+	return nil
+}
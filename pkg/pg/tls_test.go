@@ -0,0 +1,151 @@
+package pg_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/pg"
+)
+
+func newCertificate(serial int64, subject string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (
+	*x509.Certificate, *ecdsa.PrivateKey,
+) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: subject},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  parent == nil,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		DNSNames:              []string{subject},
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	Expect(err).NotTo(HaveOccurred())
+	cert, err := x509.ParseCertificate(der)
+	Expect(err).NotTo(HaveOccurred())
+	return cert, key
+}
+
+var _ = Describe("TLS", func() {
+	var (
+		ca, valid, revoked    *x509.Certificate
+		rootCertFile, crlFile string
+	)
+
+	BeforeEach(func() {
+		var caKey *ecdsa.PrivateKey
+		ca, caKey = newCertificate(1, "dbtwool test ca", nil, nil)
+		valid, _ = newCertificate(2, "pghost", ca, caKey)
+		revoked, _ = newCertificate(3, "pghost", ca, caKey)
+		crl, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+			Number:     big.NewInt(1),
+			ThisUpdate: time.Now().Add(-time.Hour),
+			NextUpdate: time.Now().Add(time.Hour),
+			RevokedCertificateEntries: []x509.RevocationListEntry{
+				{SerialNumber: revoked.SerialNumber, RevocationTime: time.Now()},
+			},
+		}, ca, caKey)
+		Expect(err).NotTo(HaveOccurred())
+
+		dir := GinkgoT().TempDir()
+		rootCertFile = filepath.Join(dir, "root.crt")
+		crlFile = filepath.Join(dir, "root.crl")
+		Expect(os.WriteFile(rootCertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Raw}),
+			0o600)).To(Succeed())
+		Expect(os.WriteFile(crlFile, pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: crl}), 0o600)).To(Succeed())
+	})
+
+	Describe("Client.PoolConfig", func() {
+		It("should add the tls files to the connection config", func() {
+			params := pg.ConnParams{Host: "pghost", Port: "5432", SslMode: "verify-full", SslRootCert: rootCertFile}
+			client := pg.NewClient(params)
+			cfg, err := client.PoolConfig()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.ConnConfig.TLSConfig).NotTo(BeNil())
+			Expect(cfg.ConnConfig.TLSConfig.RootCAs).NotTo(BeNil())
+		})
+		It("should reject revoked server certificates when a crl is set", func() {
+			params := pg.ConnParams{
+				Host:        "pghost",
+				Port:        "5432",
+				SslMode:     "verify-ca",
+				SslRootCert: rootCertFile,
+				SslCrl:      crlFile,
+			}
+			client := pg.NewClient(params)
+			cfg, err := client.PoolConfig()
+			Expect(err).NotTo(HaveOccurred())
+			verify := cfg.ConnConfig.TLSConfig.VerifyConnection
+			Expect(verify).NotTo(BeNil())
+			Expect(verify(tls.ConnectionState{PeerCertificates: []*x509.Certificate{valid}})).To(Succeed())
+			Expect(verify(tls.ConnectionState{PeerCertificates: []*x509.Certificate{revoked}})).To(
+				MatchError(ContainSubstring("is revoked")))
+		})
+		It("should fail on a crl which is not signed by a trusted certificate", func() {
+			other, otherKey := newCertificate(1, "dbtwool test ca", nil, nil)
+			crl, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{Number: big.NewInt(1)},
+				other, otherKey)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.WriteFile(crlFile, crl, 0o600)).To(Succeed())
+
+			params := pg.ConnParams{
+				Host:        "pghost",
+				Port:        "5432",
+				SslMode:     "verify-ca",
+				SslRootCert: rootCertFile,
+				SslCrl:      crlFile,
+			}
+			client := pg.NewClient(params)
+			cfg, err := client.PoolConfig()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.ConnConfig.TLSConfig.VerifyConnection(tls.ConnectionState{
+				PeerCertificates: []*x509.Certificate{valid},
+			})).To(MatchError(ContainSubstring("signature of the crl")))
+		})
+		It("should fail on a missing crl", func() {
+			missing := filepath.Join(filepath.Dir(crlFile), "missing.crl")
+			params := pg.ConnParams{Host: "pghost", Port: "5432", SslMode: "require", SslCrl: missing}
+			client := pg.NewClient(params)
+			_, err := client.PoolConfig()
+			Expect(err).To(MatchError(ContainSubstring("failed to read crl")))
+		})
+	})
+
+	Describe("GetConnString", func() {
+		It("should only add the tls files which are set", func() {
+			params := pg.ConnParams{Host: "pghost", Port: "5432", SslMode: "verify-full", SslRootCert: "/certs/root.crt",
+				SslPassword: "keysecret"}
+			Expect(params.GetConnString()).To(ContainSubstring("sslrootcert=/certs/root.crt sslpassword=keysecret"))
+			Expect(params.GetConnString()).NotTo(ContainSubstring("sslcert"))
+			Expect(params.Redacted()).NotTo(ContainSubstring("keysecret"))
+		})
+	})
+
+	Describe("TLSDescription", func() {
+		It("should describe the negotiated version and cipher", func() {
+			Expect(pg.TLSDescription(&tls.ConnectionState{
+				Version:     tls.VersionTLS13,
+				CipherSuite: tls.TLS_AES_128_GCM_SHA256,
+			})).To(Equal("TLS 1.3, cipher TLS_AES_128_GCM_SHA256"))
+			Expect(pg.TLSDescription(nil)).To(Equal("not encrypted"))
+		})
+	})
+})
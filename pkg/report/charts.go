@@ -0,0 +1,146 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"strings"
+)
+
+const (
+	chartWidth  = 760
+	labelWidth  = 260
+	valueWidth  = 110
+	barHeight   = 18
+	barGap      = 6
+	textOffset  = 13
+	lineHeight  = 260
+	axisWidth   = 70
+	axisHeight  = 30
+	legendWidth = 160
+	legendSize  = 10
+	padding     = 12
+	gridLines   = 4
+	// wholeAbove is the value above which values are shown without decimals
+	wholeAbove = 100
+)
+
+// palette holds the colors of the series and bars, which repeat when there are more
+var palette = []string{"#4e79a7", "#f28e2b", "#59a14f", "#e15759", "#76b7b2", "#edc948", "#b07aa1", "#9c755f"}
+
+// rdbmsColors keeps the colors of DB2 and PostgreSQL the same in all charts
+var rdbmsColors = map[string]string{"db2": palette[0], "pg": palette[1], "sqlite": palette[2]}
+
+type bar struct {
+	Label string
+	Value float64
+	Color string
+}
+
+type point struct {
+	X float64
+	Y float64
+}
+
+type series struct {
+	Name   string
+	Points []point
+}
+
+func colorOf(rdbms string) string {
+	if color, exists := rdbmsColors[rdbms]; exists {
+		return color
+	}
+	return palette[len(palette)-1]
+}
+
+func formatValue(value float64) string {
+	if value >= wholeAbove {
+		return fmt.Sprintf("%.0f", value)
+	}
+	return fmt.Sprintf("%.2f", value)
+}
+
+// barChart renders horizontal bars as inline SVG, scaled to the largest value
+func barChart(unit string, bars []bar) template.HTML {
+	if len(bars) == 0 {
+		return ""
+	}
+	var maxValue float64
+	for _, b := range bars {
+		maxValue = max(maxValue, b.Value)
+	}
+	plotWidth := float64(chartWidth - labelWidth - valueWidth)
+	var svg strings.Builder
+	fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" class="chart" width="%d" height="%d">`,
+		chartWidth, len(bars)*(barHeight+barGap)+barGap)
+	for i, b := range bars {
+		y := barGap + i*(barHeight+barGap)
+		var width float64
+		if maxValue > 0 {
+			width = b.Value / maxValue * plotWidth
+		}
+		fmt.Fprintf(&svg, `<text x="%d" y="%d" text-anchor="end">%s</text>`,
+			labelWidth-barGap, y+textOffset, html.EscapeString(b.Label))
+		fmt.Fprintf(&svg, `<rect x="%d" y="%d" width="%.1f" height="%d" fill="%s"/>`,
+			labelWidth, y, width, barHeight, b.Color)
+		fmt.Fprintf(&svg, `<text x="%.1f" y="%d">%s %s</text>`,
+			float64(labelWidth+barGap)+width, y+textOffset, formatValue(b.Value), html.EscapeString(unit))
+	}
+	svg.WriteString("</svg>")
+	// all text in the chart is escaped
+	return template.HTML(svg.String())
+}
+
+// lineChart renders series over time as inline SVG, with the y axis starting at 0
+func lineChart(unit string, all []series) template.HTML {
+	var maxX, maxY float64
+	for _, s := range all {
+		for _, p := range s.Points {
+			maxX, maxY = max(maxX, p.X), max(maxY, p.Y)
+		}
+	}
+	if maxX == 0 {
+		return ""
+	}
+	if maxY == 0 {
+		maxY = 1
+	}
+	left, right := float64(axisWidth), float64(chartWidth-padding)
+	top, bottom := float64(padding), float64(lineHeight-axisHeight)
+	scaleX := func(x float64) float64 { return left + x/maxX*(right-left) }
+	scaleY := func(y float64) float64 { return bottom - y/maxY*(bottom-top) }
+
+	var svg strings.Builder
+	fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" class="chart" width="%d" height="%d">`,
+		chartWidth, lineHeight+padding+legendSize)
+	for i := 0; i <= gridLines; i++ {
+		value := maxY * float64(i) / gridLines
+		y := scaleY(value)
+		fmt.Fprintf(&svg, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" class="grid"/>`, left, y, right, y)
+		fmt.Fprintf(&svg, `<text x="%.1f" y="%.1f" text-anchor="end">%s</text>`, left-barGap, y, formatValue(value))
+	}
+	fmt.Fprintf(&svg, `<text x="%.1f" y="%.1f">%s</text>`, left+barGap, top+textOffset, html.EscapeString(unit))
+	for i := 0; i <= gridLines; i++ {
+		value := maxX * float64(i) / gridLines
+		fmt.Fprintf(&svg, `<text x="%.1f" y="%.1f" text-anchor="middle">%s s</text>`,
+			scaleX(value), bottom+axisHeight/2, formatValue(value))
+	}
+	for i, s := range all {
+		color := palette[i%len(palette)]
+		points := make([]string, 0, len(s.Points))
+		for _, p := range s.Points {
+			points = append(points, fmt.Sprintf("%.1f,%.1f", scaleX(p.X), scaleY(p.Y)))
+		}
+		fmt.Fprintf(&svg, `<polyline fill="none" stroke="%s" stroke-width="2" points="%s"/>`,
+			color, strings.Join(points, " "))
+		x := left + float64(i*legendWidth)
+		fmt.Fprintf(&svg, `<rect x="%.1f" y="%d" width="%d" height="%d" fill="%s"/>`,
+			x, lineHeight, legendSize, legendSize, color)
+		fmt.Fprintf(&svg, `<text x="%.1f" y="%d">%s</text>`,
+			x+legendSize+barGap/2, lineHeight+legendSize, html.EscapeString(s.Name))
+	}
+	svg.WriteString("</svg>")
+	// all text in the chart is escaped
+	return template.HTML(svg.String())
+}
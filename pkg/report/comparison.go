@@ -0,0 +1,102 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbclient"
+	"github.com/pgvillage-tools/dbtwool/pkg/result"
+	"github.com/pgvillage-tools/dbtwool/pkg/stats"
+)
+
+type comparisonRow struct {
+	Operation string
+	DB2       *stats.OperationSummary
+	Postgres  *stats.OperationSummary
+	// Throughput and Latency are the ratios of PostgreSQL to DB2, e.g. 1.50x when PostgreSQL is 50% higher
+	Throughput string
+	Latency    string
+}
+
+type comparison struct {
+	Test     string
+	DB2      string
+	Postgres string
+	Rows     []comparisonRow
+	Chart    template.HTML
+}
+
+// compare returns a comparison of DB2 and PostgreSQL for every test which ran on both. When a test ran more than once
+// on one of them, the last run is compared.
+func compare(docs []result.Document) []comparison {
+	latest := map[string]map[string]result.Document{}
+	for _, doc := range docs {
+		if latest[doc.Test] == nil {
+			latest[doc.Test] = map[string]result.Document{}
+		}
+		if last, exists := latest[doc.Test][doc.RDBMS]; !exists || doc.StartedAt.After(last.StartedAt) {
+			latest[doc.Test][doc.RDBMS] = doc
+		}
+	}
+	var comparisons []comparison
+	for _, test := range sortedKeys(latest) {
+		db2, db2Exists := latest[test][string(dbclient.DB2)]
+		pg, pgExists := latest[test][string(dbclient.Postgres)]
+		if db2Exists && pgExists {
+			comparisons = append(comparisons, newComparison(test, db2, pg))
+		}
+	}
+	return comparisons
+}
+
+func newComparison(test string, db2 result.Document, pg result.Document) comparison {
+	c := comparison{Test: test, DB2: title(db2), Postgres: title(pg)}
+	var bars []bar
+	for _, name := range operationNames(db2, pg) {
+		row := comparisonRow{Operation: name, DB2: operation(db2, name), Postgres: operation(pg, name)}
+		if row.DB2 != nil {
+			bars = append(bars, bar{Label: "db2: " + name, Value: row.DB2.PerSecond, Color: colorOf(db2.RDBMS)})
+		}
+		if row.Postgres != nil {
+			bars = append(bars, bar{Label: "pg: " + name, Value: row.Postgres.PerSecond, Color: colorOf(pg.RDBMS)})
+		}
+		if row.DB2 != nil && row.Postgres != nil {
+			row.Throughput = ratio(row.Postgres.PerSecond, row.DB2.PerSecond)
+			row.Latency = ratio(row.Postgres.Latency.P99Ms, row.DB2.Latency.P99Ms)
+		}
+		c.Rows = append(c.Rows, row)
+	}
+	c.Chart = barChart("ops/s", bars)
+	return c
+}
+
+// operationNames returns the names of the operations of both documents, in the order of the documents
+func operationNames(docs ...result.Document) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, doc := range docs {
+		for _, op := range doc.Operations {
+			if !seen[op.Name] {
+				seen[op.Name] = true
+				names = append(names, op.Name)
+			}
+		}
+	}
+	return names
+}
+
+func operation(doc result.Document, name string) *stats.OperationSummary {
+	for i := range doc.Operations {
+		if doc.Operations[i].Name == name {
+			return &doc.Operations[i]
+		}
+	}
+	return nil
+}
+
+func ratio(value float64, base float64) string {
+	if base == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.2fx", value/base)
+}
@@ -0,0 +1,198 @@
+// Package report renders result documents (see package result) as one self-contained HTML file, with charts of the
+// throughput and latency, the intervals, parameters and server statistics of every run, and a comparison of DB2 and
+// PostgreSQL for tests which ran on both. The charts are inline SVG, so the report can be opened without network
+// access, or be mailed.
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/result"
+	"github.com/pgvillage-tools/dbtwool/pkg/stats"
+)
+
+type keyValue struct {
+	Key   string
+	Value string
+}
+
+type serverGroup struct {
+	Name   string
+	Values []keyValue
+}
+
+type run struct {
+	ID         string
+	Title      string
+	Document   result.Document
+	Duration   time.Duration
+	Parameters []keyValue
+	Latency    template.HTML
+	// Throughput and IntervalLatency are the charts of the intervals (with --reportInterval)
+	Throughput      template.HTML
+	IntervalLatency template.HTML
+	Server          []serverGroup
+	Client          []keyValue
+	// Environment describes the client, and Settings the server (see package environment)
+	Environment []keyValue
+	Settings    []serverGroup
+}
+
+type page struct {
+	Generated   time.Time
+	Throughput  template.HTML
+	Latency     template.HTML
+	Comparisons []comparison
+	Runs        []run
+}
+
+// Render writes the report of docs as HTML to w
+func Render(w io.Writer, docs []result.Document) error {
+	return reportTemplate.Execute(w, newPage(docs))
+}
+
+// Write writes the report of docs as HTML to path
+func Write(path string, docs []result.Document) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create the report: %w", err)
+	}
+	if err = Render(f, docs); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to render the report: %w", err)
+	}
+	return f.Close()
+}
+
+func newPage(docs []result.Document) page {
+	p := page{Generated: time.Now()}
+	var throughput, latency []bar
+	for i, doc := range docs {
+		r := newRun(i, doc)
+		p.Runs = append(p.Runs, r)
+		for _, op := range doc.Operations {
+			label := fmt.Sprintf("%s: %s", r.Title, op.Name)
+			throughput = append(throughput, bar{Label: label, Value: op.PerSecond, Color: colorOf(doc.RDBMS)})
+			latency = append(latency, bar{Label: label, Value: op.Latency.P99Ms, Color: colorOf(doc.RDBMS)})
+		}
+	}
+	p.Throughput = barChart("ops/s", throughput)
+	p.Latency = barChart("ms", latency)
+	p.Comparisons = compare(docs)
+	return p
+}
+
+func title(doc result.Document) string {
+	return fmt.Sprintf("%s %s %s", doc.Test, doc.RDBMS, doc.StartedAt.Format(time.DateTime))
+}
+
+func newRun(index int, doc result.Document) run {
+	r := run{
+		ID:       fmt.Sprintf("run-%d", index+1),
+		Title:    title(doc),
+		Document: doc,
+		Duration: doc.FinishedAt.Sub(doc.StartedAt).Round(time.Second),
+	}
+	for _, key := range sortedKeys(doc.Parameters) {
+		r.Parameters = append(r.Parameters, keyValue{Key: key, Value: fmt.Sprint(doc.Parameters[key])})
+	}
+	var latency []bar
+	for i, op := range doc.Operations {
+		color := palette[i%len(palette)]
+		latency = append(latency,
+			bar{Label: op.Name + " p50", Value: op.Latency.P50Ms, Color: color},
+			bar{Label: op.Name + " p95", Value: op.Latency.P95Ms, Color: color},
+			bar{Label: op.Name + " p99", Value: op.Latency.P99Ms, Color: color})
+	}
+	r.Latency = barChart("ms", latency)
+	r.Throughput = lineChart("ops/s", intervalSeries(doc.Intervals, func(op stats.OperationSummary) float64 {
+		return op.PerSecond
+	}))
+	r.IntervalLatency = lineChart("p99 ms", intervalSeries(doc.Intervals, func(op stats.OperationSummary) float64 {
+		return op.Latency.P99Ms
+	}))
+	if doc.Server != nil {
+		for _, name := range sortedKeys(doc.Server.Deltas) {
+			group := serverGroup{Name: name}
+			deltas := doc.Server.Deltas[name]
+			for _, key := range sortedKeys(deltas) {
+				group.Values = append(group.Values, keyValue{Key: key, Value: formatValue(deltas[key])})
+			}
+			r.Server = append(r.Server, group)
+		}
+	}
+	if doc.Client != nil {
+		r.Client = clientValues(doc)
+	}
+	if doc.Environment != nil {
+		r.Environment, r.Settings = environmentValues(doc)
+	}
+	return r
+}
+
+// intervalSeries returns a series per operation, with the value of every interval at its end
+func intervalSeries(intervals []result.Interval, value func(stats.OperationSummary) float64) []series {
+	var all []series
+	index := map[string]int{}
+	for _, interval := range intervals {
+		for _, op := range interval.Operations {
+			i, exists := index[op.Name]
+			if !exists {
+				i = len(all)
+				index[op.Name] = i
+				all = append(all, series{Name: op.Name})
+			}
+			all[i].Points = append(all[i].Points, point{X: interval.ElapsedSec, Y: value(op)})
+		}
+	}
+	return all
+}
+
+func clientValues(doc result.Document) []keyValue {
+	client := doc.Client
+	return []keyValue{
+		{Key: "process CPU % (avg / max)", Value: formatValue(client.ProcessCPUPct.Avg) + " / " +
+			formatValue(client.ProcessCPUPct.Max)},
+		{Key: "host CPU % (avg / max)", Value: formatValue(client.HostCPUPct.Avg) + " / " +
+			formatValue(client.HostCPUPct.Max)},
+		{Key: "goroutines (max)", Value: formatValue(client.Goroutines.Max)},
+		{Key: "resident memory bytes (max)", Value: formatValue(client.RSSBytes.Max)},
+		{Key: "garbage collections", Value: fmt.Sprint(client.GCCount)},
+		{Key: "garbage collection pause ms", Value: formatValue(client.GCPauseMs)},
+		{Key: "saturated samples", Value: fmt.Sprint(client.SaturatedSamples)},
+	}
+}
+
+func environmentValues(doc result.Document) ([]keyValue, []serverGroup) {
+	env := doc.Environment
+	client := []keyValue{
+		{Key: "dbtwool version", Value: env.Version},
+		{Key: "client hostname", Value: env.Hostname},
+		{Key: "go version", Value: env.GoVersion},
+		{Key: "platform", Value: env.OS + "/" + env.Arch},
+		{Key: "CPUs", Value: fmt.Sprint(env.CPUs)},
+	}
+	var settings []serverGroup
+	for _, name := range sortedKeys(env.Server) {
+		group := serverGroup{Name: name}
+		for _, key := range sortedKeys(env.Server[name]) {
+			group.Values = append(group.Values, keyValue{Key: key, Value: env.Server[name][key]})
+		}
+		settings = append(settings, group)
+	}
+	return client, settings
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
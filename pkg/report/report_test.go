@@ -0,0 +1,67 @@
+package report_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/environment"
+	"github.com/pgvillage-tools/dbtwool/pkg/report"
+	"github.com/pgvillage-tools/dbtwool/pkg/result"
+	"github.com/pgvillage-tools/dbtwool/pkg/servermetrics"
+	"github.com/pgvillage-tools/dbtwool/pkg/stats"
+)
+
+func document(rdbms string, perSecond float64) result.Document {
+	started := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	op := stats.OperationSummary{Name: "oltp", Count: 100, PerSecond: perSecond,
+		Latency: stats.LatencySummary{P50Ms: 1, P95Ms: 2, P99Ms: 4}}
+	return result.Document{
+		Test:       "ru-performance",
+		RDBMS:      rdbms,
+		StartedAt:  started,
+		FinishedAt: started.Add(time.Minute),
+		Parameters: map[string]any{"parallel": 4, "table": "<script>alert(1)</script>"},
+		Operations: []stats.OperationSummary{op},
+		Intervals: []result.Interval{
+			{Start: started, ElapsedSec: 10, Operations: []stats.OperationSummary{op}},
+			{Start: started.Add(10 * time.Second), ElapsedSec: 20, Operations: []stats.OperationSummary{op}},
+		},
+		Environment: &environment.Environment{Version: "v1.2.3",
+			Server: environment.Settings{"pg_settings": {"shared_buffers": "4GB"}}},
+		Server: &servermetrics.Metrics{Deltas: servermetrics.Groups{"pg_stat_database": {"xact_commit": 42}}},
+	}
+}
+
+var _ = Describe("Report", func() {
+	It("should render charts, intervals and server metrics without external references", func() {
+		var out bytes.Buffer
+		Expect(report.Render(&out, []result.Document{document("pg", 50)})).To(Succeed())
+		html := out.String()
+		Expect(html).To(ContainSubstring("<svg"))
+		Expect(html).To(ContainSubstring("<polyline"))
+		Expect(html).To(ContainSubstring("pg_stat_database"))
+		Expect(html).To(ContainSubstring("xact_commit"))
+		Expect(html).To(ContainSubstring("shared_buffers"))
+		Expect(html).NotTo(ContainSubstring("DB2 compared to PostgreSQL"))
+		Expect(html).NotTo(ContainSubstring("<script"))
+		Expect(html).NotTo(ContainSubstring("<link"))
+		Expect(strings.ReplaceAll(html, `xmlns="http://www.w3.org/2000/svg"`, "")).NotTo(ContainSubstring("http"))
+	})
+	It("should compare DB2 and PostgreSQL", func() {
+		var out bytes.Buffer
+		docs := []result.Document{document("db2", 40), document("pg", 50)}
+		Expect(report.Render(&out, docs)).To(Succeed())
+		Expect(out.String()).To(ContainSubstring("ru-performance: DB2 compared to PostgreSQL"))
+		Expect(out.String()).To(ContainSubstring("1.25x"))
+	})
+	It("should write the report to a file", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "report.html")
+		Expect(report.Write(path, []result.Document{document("db2", 40)})).To(Succeed())
+		Expect(path).To(BeAnExistingFile())
+	})
+})
@@ -0,0 +1,134 @@
+package report
+
+import (
+	"html/template"
+	"time"
+)
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"value":    formatValue,
+	"datetime": func(t time.Time) string { return t.Format(time.DateTime) },
+}).Parse(reportHTML))
+
+// reportHTML is the layout of the report. It has no external references, so that it can be read offline.
+const reportHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>dbtwool report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+h1, h2, h3 { font-weight: normal; }
+h2 { border-bottom: 1px solid #ccc; margin-top: 2em; }
+table { border-collapse: collapse; margin: 0.5em 0 1em; }
+th, td { border: 1px solid #ddd; padding: 0.2em 0.6em; text-align: left; }
+td.number { text-align: right; }
+th { background: #f4f4f4; }
+svg.chart { display: block; margin: 0.5em 0; font-size: 12px; }
+svg.chart .grid { stroke: #e4e4e4; }
+details { margin: 0.5em 0; }
+</style>
+</head>
+<body>
+<h1>dbtwool report</h1>
+<p>Generated at {{datetime .Generated}} from {{len .Runs}} result document(s).</p>
+<ul>
+{{- range .Runs}}
+<li><a href="#{{.ID}}">{{.Title}}</a></li>
+{{- end}}
+</ul>
+
+<h2>Throughput</h2>
+{{.Throughput}}
+<h2>p99 latency</h2>
+{{.Latency}}
+
+{{- range .Comparisons}}
+<h2>{{.Test}}: DB2 compared to PostgreSQL</h2>
+<p>DB2: {{.DB2}}<br>PostgreSQL: {{.Postgres}}</p>
+<table>
+<tr><th>operation</th><th>DB2 ops/s</th><th>PostgreSQL ops/s</th><th>throughput PostgreSQL / DB2</th>
+<th>DB2 p99 ms</th><th>PostgreSQL p99 ms</th><th>p99 PostgreSQL / DB2</th></tr>
+{{- range .Rows}}
+<tr><td>{{.Operation}}</td>
+<td class="number">{{with .DB2}}{{value .PerSecond}}{{else}}-{{end}}</td>
+<td class="number">{{with .Postgres}}{{value .PerSecond}}{{else}}-{{end}}</td>
+<td class="number">{{.Throughput}}</td>
+<td class="number">{{with .DB2}}{{value .Latency.P99Ms}}{{else}}-{{end}}</td>
+<td class="number">{{with .Postgres}}{{value .Latency.P99Ms}}{{else}}-{{end}}</td>
+<td class="number">{{.Latency}}</td></tr>
+{{- end}}
+</table>
+{{.Chart}}
+{{- end}}
+
+{{- range .Runs}}
+<h2 id="{{.ID}}">{{.Title}}</h2>
+{{- $doc := .Document}}
+<p>Started at {{datetime $doc.StartedAt}}, finished at {{datetime $doc.FinishedAt}} ({{.Duration}}).
+Reconnects: {{$doc.Reconnects}}, downtime: {{value $doc.DowntimeMs}} ms.</p>
+<h3>Operations</h3>
+<table>
+<tr><th>operation</th><th>count</th><th>errors</th><th>retries</th><th>ops/s</th><th>mean ms</th><th>p50 ms</th>
+<th>p95 ms</th><th>p99 ms</th><th>max ms</th></tr>
+{{- range $doc.Operations}}
+<tr><td>{{.Name}}</td><td class="number">{{.Count}}</td><td class="number">{{.Errors}}</td>
+<td class="number">{{.Retries}}</td><td class="number">{{value .PerSecond}}</td>
+<td class="number">{{value .Latency.MeanMs}}</td><td class="number">{{value .Latency.P50Ms}}</td>
+<td class="number">{{value .Latency.P95Ms}}</td><td class="number">{{value .Latency.P99Ms}}</td>
+<td class="number">{{value .Latency.MaxMs}}</td></tr>
+{{- end}}
+</table>
+{{.Latency}}
+{{- if .Throughput}}
+<h3>Intervals</h3>
+{{.Throughput}}
+{{.IntervalLatency}}
+{{- end}}
+<h3>Parameters</h3>
+<table>
+{{- range .Parameters}}
+<tr><th>{{.Key}}</th><td>{{.Value}}</td></tr>
+{{- end}}
+</table>
+{{- if .Environment}}
+<h3>Environment</h3>
+<table>
+{{- range .Environment}}
+<tr><th>{{.Key}}</th><td>{{.Value}}</td></tr>
+{{- end}}
+</table>
+{{- range .Settings}}
+<details><summary>{{.Name}}</summary>
+<table>
+{{- range .Values}}
+<tr><th>{{.Key}}</th><td>{{.Value}}</td></tr>
+{{- end}}
+</table>
+</details>
+{{- end}}
+{{- end}}
+{{- if .Server}}
+<h3>Server metrics (increase over the measurement)</h3>
+{{- range .Server}}
+<details><summary>{{.Name}}</summary>
+<table>
+{{- range .Values}}
+<tr><th>{{.Key}}</th><td class="number">{{.Value}}</td></tr>
+{{- end}}
+</table>
+</details>
+{{- end}}
+{{- end}}
+{{- if .Client}}
+<h3>Client metrics</h3>
+<table>
+{{- range .Client}}
+<tr><th>{{.Key}}</th><td class="number">{{.Value}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- end}}
+</body>
+</html>
+`
@@ -0,0 +1,77 @@
+// Package result holds the result document of a test run: the parameters, the results and the statistics which
+// were collected along with it. The document is written as JSON (with --resultFile), so that runs can be compared
+// and explained later.
+package result
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/clientmetrics"
+	"github.com/pgvillage-tools/dbtwool/pkg/environment"
+	"github.com/pgvillage-tools/dbtwool/pkg/logging"
+	"github.com/pgvillage-tools/dbtwool/pkg/secrets"
+	"github.com/pgvillage-tools/dbtwool/pkg/servermetrics"
+	"github.com/pgvillage-tools/dbtwool/pkg/stats"
+)
+
+var logger = logging.Logger()
+
+// Document is the result of one test run
+type Document struct {
+	// Test is the name of the test, e.g. lob-performance
+	Test  string `json:"test"`
+	RDBMS string `json:"rdbms"`
+	// RunID is the id of the run in the log lines (see package logging)
+	RunID      string    `json:"run_id,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	// Environment describes the client and the server the test ran on
+	Environment *environment.Environment `json:"environment,omitempty"`
+	// Parameters holds the flags of the command
+	Parameters map[string]any           `json:"parameters"`
+	Operations []stats.OperationSummary `json:"operations"`
+	Reconnects int                      `json:"reconnects"`
+	DowntimeMs float64                  `json:"downtime_ms"`
+	Server     *servermetrics.Metrics   `json:"server_metrics,omitempty"`
+	Client     *clientmetrics.Metrics   `json:"client_metrics,omitempty"`
+	// Intervals holds the operations per report interval (with --reportInterval)
+	Intervals []Interval `json:"intervals,omitempty"`
+}
+
+// Marshal returns the document as indented JSON, with all registered secrets masked in its string values (e.g. a
+// password in the dsn parameter, or in a server setting like primary_conninfo)
+func (d Document) Marshal() ([]byte, error) {
+	content, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return secrets.RedactJSON(content), nil
+}
+
+// Write writes the document to path
+func (d Document) Write(path string) error {
+	content, err := d.Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to marshal the result document: %w", err)
+	}
+	if err = os.WriteFile(path, append(content, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write the result document: %w", err)
+	}
+	return nil
+}
+
+// Read reads a document which was written with Write
+func Read(path string) (Document, error) {
+	var d Document
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return d, fmt.Errorf("failed to read the result document: %w", err)
+	}
+	if err = json.Unmarshal(content, &d); err != nil {
+		return d, fmt.Errorf("failed to parse the result document %s: %w", path, err)
+	}
+	return d, nil
+}
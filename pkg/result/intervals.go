@@ -0,0 +1,86 @@
+package result
+
+import (
+	"time"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/stats"
+)
+
+// Interval holds the throughput and latency of the operations over one report interval
+type Interval struct {
+	Start time.Time `json:"start"`
+	// ElapsedSec is the time since the start of the measurement at the end of the interval
+	ElapsedSec float64                  `json:"elapsed_sec"`
+	Operations []stats.OperationSummary `json:"operations"`
+}
+
+// intervalReporter reports the tracked operations every interval, from the start of the measurement until stop is
+// called. Intervals are only appended to the document by the reporter, until it is done.
+type intervalReporter struct {
+	interval time.Duration
+	ops      []*stats.Operation
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func (ir *intervalReporter) start(doc *Document) {
+	ir.stop = make(chan struct{})
+	ir.done = make(chan struct{})
+	go ir.loop(doc)
+}
+
+func (ir *intervalReporter) loop(doc *Document) {
+	defer close(ir.done)
+	ticker := time.NewTicker(ir.interval)
+	defer ticker.Stop()
+	measurementStart := time.Now()
+	last, lastTime := ir.snapshot(), measurementStart
+	for {
+		select {
+		case <-ir.stop:
+			return
+		case now := <-ticker.C:
+			current := ir.snapshot()
+			interval := Interval{Start: lastTime, ElapsedSec: now.Sub(measurementStart).Seconds()}
+			for i, snapshot := range current {
+				interval.Operations = append(interval.Operations, snapshot.Since(last[i], now.Sub(lastTime)))
+			}
+			doc.Intervals = append(doc.Intervals, interval)
+			logInterval(interval)
+			last, lastTime = current, now
+		}
+	}
+}
+
+func (ir *intervalReporter) snapshot() []stats.OperationSnapshot {
+	snapshots := make([]stats.OperationSnapshot, 0, len(ir.ops))
+	for _, op := range ir.ops {
+		snapshots = append(snapshots, op.Snapshot())
+	}
+	return snapshots
+}
+
+// finish stops reporting, and waits until the last interval is added
+func (ir *intervalReporter) finish() {
+	if ir.stop == nil {
+		return
+	}
+	close(ir.stop)
+	<-ir.done
+	ir.stop = nil
+}
+
+// logInterval logs one line per operation, like the progress report of pgbench
+func logInterval(interval Interval) {
+	for _, op := range interval.Operations {
+		logger.Info().
+			Str("operation", op.Name).
+			Float64("elapsed_sec", interval.ElapsedSec).
+			Float64("per_second", op.PerSecond).
+			Float64("p50_ms", op.Latency.P50Ms).
+			Float64("p99_ms", op.Latency.P99Ms).
+			Int64("errors", op.Errors).
+			Msgf("progress: %.1f s, %s: %.1f ops/s, p50 %.3f ms, p99 %.3f ms, errors %d",
+				interval.ElapsedSec, op.Name, op.PerSecond, op.Latency.P50Ms, op.Latency.P99Ms, op.Errors)
+	}
+}
@@ -0,0 +1,226 @@
+package result
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/clientmetrics"
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+	"github.com/pgvillage-tools/dbtwool/pkg/environment"
+	"github.com/pgvillage-tools/dbtwool/pkg/logging"
+	"github.com/pgvillage-tools/dbtwool/pkg/servermetrics"
+	"github.com/pgvillage-tools/dbtwool/pkg/stats"
+)
+
+// The phases of a test in the log lines, from Start, MeasurementStarted and Finish
+const (
+	phaseWarmup      = "warmup"
+	phaseMeasurement = "measurement"
+	phaseResults     = "results"
+)
+
+// Run collects the result document while a test runs, and runs the samplers along with the test.
+// A nil *Run is valid and collects nothing, so that tests can run without one.
+type Run struct {
+	Document Document
+	server   *servermetrics.Sampler
+	sampling bool
+	client   *clientmetrics.Sampler
+	reporter *intervalReporter
+	describe []environment.Query
+}
+
+// NewRun returns a Run for a test with the parameters of the command, and the environment of the client
+func NewRun(test string, rdbms string, parameters map[string]any) *Run {
+	env := environment.Client()
+	return &Run{Document: Document{
+		Test:        test,
+		RDBMS:       rdbms,
+		RunID:       logging.RunID(),
+		Environment: &env,
+		Parameters:  parameters,
+	}}
+}
+
+// DescribeServer enables adding the version and settings of the server to the environment when the test starts
+func (r *Run) DescribeServer(queries []environment.Query) {
+	r.describe = queries
+}
+
+// SampleServer enables sampling of server statistics during the test
+func (r *Run) SampleServer(sampler *servermetrics.Sampler) {
+	r.server = sampler
+}
+
+// SampleClient enables sampling of the resource usage of dbtwool itself during the test
+func (r *Run) SampleClient(sampler *clientmetrics.Sampler) {
+	r.client = sampler
+}
+
+// ReportIntervals enables reporting the tracked operations every interval, from the start of the measurement
+func (r *Run) ReportIntervals(interval time.Duration) {
+	r.reporter = &intervalReporter{interval: interval}
+}
+
+// Track adds operations to the interval report. It does nothing when interval reporting is not enabled.
+func (r *Run) Track(ops ...*stats.Operation) {
+	if r == nil || r.reporter == nil {
+		return
+	}
+	r.reporter.ops = append(r.reporter.ops, ops...)
+}
+
+// Conns returns the number of connections the samplers need next to the connections of the test
+func (r *Run) Conns() int {
+	if r == nil || (r.server == nil && len(r.describe) == 0) {
+		return 0
+	}
+	// the server is described before the server sampler connects
+	return 1
+}
+
+// Start describes the server and starts the samplers. A sampler which cannot start (or a server which cannot be
+// described) is logged and skipped, since it should not fail the test.
+func (r *Run) Start(ctx context.Context, pool dbinterface.Pool) {
+	if r == nil {
+		return
+	}
+	r.Document.StartedAt = time.Now()
+	logging.SetPhase(phaseWarmup)
+	if len(r.describe) > 0 {
+		if err := r.describeServer(ctx, pool); err != nil {
+			logger.Warn().Err(err).Msg("The server is not described in the result")
+		}
+	}
+	if r.server != nil {
+		if err := r.server.Start(ctx, pool); err != nil {
+			logger.Warn().Err(err).Msg("Server metrics are not sampled")
+		} else {
+			r.sampling = true
+		}
+	}
+	if r.client != nil {
+		r.client.Start(ctx)
+	}
+}
+
+func (r *Run) describeServer(ctx context.Context, pool dbinterface.Pool) error {
+	conn, err := pool.Connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close(ctx) }()
+	if r.Document.Environment == nil {
+		r.Document.Environment = &environment.Environment{}
+	}
+	return r.Document.Environment.Describe(ctx, conn, r.describe)
+}
+
+// MeasurementStarted marks the end of the warmup, which is where the measurement window of the samplers starts
+func (r *Run) MeasurementStarted() {
+	if r == nil {
+		return
+	}
+	logging.SetPhase(phaseMeasurement)
+	if r.sampling {
+		r.server.MarkStart()
+	}
+	if r.client != nil {
+		r.client.MarkStart()
+	}
+	if r.reporter != nil && len(r.reporter.ops) > 0 {
+		r.reporter.start(&r.Document)
+	}
+}
+
+// Finish stops the samplers and adds the results of the test to the document
+func (r *Run) Finish(
+	ctx context.Context,
+	operations []stats.OperationSummary,
+	reconnects int,
+	downtime time.Duration,
+) {
+	if r == nil {
+		return
+	}
+	if r.reporter != nil {
+		r.reporter.finish()
+	}
+	r.Document.FinishedAt = time.Now()
+	logging.SetPhase(phaseResults)
+	r.Document.Operations = operations
+	r.Document.Reconnects = reconnects
+	r.Document.DowntimeMs = float64(downtime) / float64(time.Millisecond)
+	if r.sampling {
+		metrics := r.server.Stop(ctx)
+		r.Document.Server = &metrics
+		r.sampling = false
+		logServerMetrics(metrics)
+	}
+	if r.client != nil {
+		metrics := r.client.Stop()
+		r.Document.Client = &metrics
+		r.client = nil
+		logClientMetrics(metrics)
+	}
+}
+
+// Stop stops the samplers without adding their results, for a test which failed before Finish
+func (r *Run) Stop(ctx context.Context) {
+	if r == nil {
+		return
+	}
+	if r.reporter != nil {
+		r.reporter.finish()
+	}
+	if r.sampling {
+		r.server.Stop(ctx)
+		r.sampling = false
+	}
+	if r.client != nil {
+		r.client.Stop()
+		r.client = nil
+	}
+}
+
+func logServerMetrics(metrics servermetrics.Metrics) {
+	for _, group := range sortedKeys(metrics.Deltas) {
+		logger.Debug().Str("group", group).Interface("deltas", metrics.Deltas[group]).Msg("Server metrics")
+	}
+	for _, group := range sortedKeys(metrics.Samples) {
+		logger.Debug().Str("group", group).Interface("samples", metrics.Samples[group]).Msg("Server metrics")
+	}
+	logger.Info().
+		Int("samples", metrics.SampleCount).
+		Int("groups", len(metrics.Deltas)+len(metrics.Samples)).
+		Strs("unavailable", metrics.Unavailable).
+		Msg("Server metrics collected")
+}
+
+func logClientMetrics(metrics clientmetrics.Metrics) {
+	event := logger.Info()
+	if metrics.SaturatedSamples > 0 {
+		event = logger.Warn()
+	}
+	event.
+		Int("samples", metrics.SampleCount).
+		Float64("process_cpu_pct_avg", metrics.ProcessCPUPct.Avg).
+		Float64("process_cpu_pct_max", metrics.ProcessCPUPct.Max).
+		Float64("host_cpu_pct_max", metrics.HostCPUPct.Max).
+		Float64("goroutines_max", metrics.Goroutines.Max).
+		Float64("rss_bytes_max", metrics.RSSBytes.Max).
+		Uint32("gc_count", metrics.GCCount).
+		Float64("gc_pause_ms", metrics.GCPauseMs).
+		Int("saturated_samples", metrics.SaturatedSamples).
+		Msg("Client metrics collected")
+}
+
+func sortedKeys(groups servermetrics.Groups) []string {
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
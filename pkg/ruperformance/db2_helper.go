@@ -49,7 +49,7 @@ func (helper DB2Helper) CreateOlapSQL() string {
 	return fmt.Sprintf(`
 SELECT COUNT(*) AS cnt, SUM(amount) AS total_amt
 FROM   %s.%s
-WHERE  acct_id = 50
+WHERE  acct_id BETWEEN 1 AND 50
   AND  txn_ts >= (CURRENT TIMESTAMP - 30 MINUTES)
 `, helper.schemaName, helper.tableName)
 }
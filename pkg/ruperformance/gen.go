@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"math"
 	"strconv"
@@ -12,6 +13,8 @@ import (
 
 	"github.com/pgvillage-tools/dbtwool/pkg/dbclient"
 	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+	"github.com/pgvillage-tools/dbtwool/pkg/livemetrics"
+	"github.com/pgvillage-tools/dbtwool/pkg/tracing"
 	"github.com/rs/zerolog/log"
 )
 
@@ -34,26 +37,32 @@ func Generate(
 	schemaName string,
 	tableName string,
 	numRows int64,
-) {
+) (err error) {
 	logger := log.With().Str("cmd", "gen").Logger()
+	ctx, span := tracing.Start(ctx, "ru-performance gen", tracing.RDBMS(dbType), tracing.Table(schemaName, tableName),
+		tracing.Rows(numRows))
+	defer func() { tracing.End(span, err) }()
+
+	if numRows <= 0 {
+		return errors.New("numRows must be > 0")
+	}
+	if numRows > int64(math.MaxInt) {
+		return fmt.Errorf("numRows %d exceeds maximum supported value", numRows)
+	}
 
 	logger.Info().Msg("Initiating connection pool.")
-	pool, poolErr := client.Pool(ctx)
-	if poolErr != nil {
-		logger.Fatal().Msgf("Failed to connect: %v", poolErr)
+	pool, err := client.Pool(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
 	}
 
 	logger.Info().Msg("Connecting to database.")
 	conn, err := pool.Connect(ctx)
 	if err != nil {
-		logger.Fatal().Msgf("connect error: %v", err)
+		return fmt.Errorf("connect error: %w", err)
 	}
 	defer conn.Close(ctx)
 
-	if numRows <= 0 {
-		logger.Fatal().Msg("numRows must be > 0")
-	}
-
 	const batchSize = 100
 	logger.Info().Msgf("Generating %d rows into %s.%s (batchSize=%d)", numRows, schemaName, tableName, batchSize)
 	insertPrefix := fmt.Sprintf("INSERT INTO %s.%s (acct_id, txn_ts, amount, descr) VALUES ", schemaName, tableName)
@@ -62,50 +71,39 @@ func Generate(
 	baseTS := time.Now().UTC().Truncate(time.Second)
 	const seed uint64 = 0xC0FFEE12345
 
-	if numRows > int64(math.MaxInt) {
-		logger.Fatal().Msgf("numRows %d exceeds maximum supported value", numRows)
-	}
-
 	total := int(numRows)
+	startedAt := time.Now()
 	for start := 0; start < total; start += batchSize {
 		end := minInt(start+batchSize, total)
 
+		sql := buildInsertSQL(dbType, insertPrefix, baseTS, seed, start, end)
 		// one tx per batch
-		if err := conn.Begin(ctx); err != nil {
-			logger.Fatal().Msgf("begin failed: %v", err)
-		}
-
-		committed := false
-		// should probably be separate function:
-		func() {
-			defer func() {
-				if !committed {
-					_ = conn.Rollback(ctx)
-				}
-			}()
-
-			sql := buildInsertSQL(dbType, insertPrefix, baseTS, seed, start, end)
-
-			if _, err := conn.Execute(ctx, sql); err != nil {
-				logger.Error().Msgf("batch insert failed (rows %d..%d): %v", start+1, end, err)
-				return
-			}
-
-			if err := conn.Commit(ctx); err != nil {
-				logger.Error().Msgf("commit failed (batch starting %d): %v", start, err)
-				return
-			}
-			committed = true
-		}()
-
-		if !committed {
-			logger.Fatal().Msg("aborting due to batch failure")
+		if err := insertBatch(ctx, conn, sql); err != nil {
+			return fmt.Errorf("batch insert failed (rows %d..%d): %w", start+1, end, err)
 		}
 
+		livemetrics.AddRowsGenerated(int64(end - start))
+		remaining := time.Duration(float64(time.Since(startedAt)) * float64(total-end) / float64(end))
+		livemetrics.SetProgress(int64(end), int64(total), float64(end)/float64(total)*maxPercent, remaining)
 		logger.Info().Msgf("Inserted rows %d..%d of %d", start+1, end, total)
 	}
 
 	logger.Info().Msg("Generate transactions completed.")
+	return nil
+}
+
+func insertBatch(ctx context.Context, conn dbinterface.Connection, sql string) error {
+	if err := conn.Begin(ctx); err != nil {
+		return fmt.Errorf("begin failed: %w", err)
+	}
+	if _, err := conn.Execute(ctx, sql); err != nil {
+		_ = conn.Rollback(ctx)
+		return err
+	}
+	if err := conn.Commit(ctx); err != nil {
+		return fmt.Errorf("commit failed: %w", err)
+	}
+	return nil
 }
 
 func buildInsertSQL(
@@ -155,8 +153,12 @@ func buildInsertSQL(
 
 // functions to make literals for building the insert statements (sadly some rdbms specific logic heres)
 func timestampLiteral(dbType dbclient.RDBMS, t time.Time) string {
-	if dbType == dbclient.DB2 {
+	switch dbType {
+	case dbclient.DB2:
 		return fmt.Sprintf("TIMESTAMP('%s')", t.UTC().Format("2006-01-02-15.04.05"))
+	case dbclient.SQLite:
+		// SQLite has no timestamp type, but datetime() returns text in this format
+		return fmt.Sprintf("'%s'", t.UTC().Format("2006-01-02 15:04:05"))
 	}
 	return fmt.Sprintf("TIMESTAMP '%s'", t.UTC().Format("2006-01-02 15:04:05"))
 }
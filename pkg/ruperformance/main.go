@@ -1,15 +1,20 @@
 package ruperformance
 
 import (
-	"github.com/rs/zerolog/log"
+	"time"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/logging"
 )
 
-var logger = log.With().Logger()
+var logger = logging.Logger()
 
 const (
 	// stringBufferallocation is the estimated amount of charactars in a row for the stringbuffer.
 	stringBufferallocation = 220
 
+	// maxPercent is the progress of a generation which is done
+	maxPercent = 100.0
+
 	// descriptionLength is the fixed length of generated descriptions.
 	descriptionLength = 100
 
@@ -30,4 +35,7 @@ const (
 
 	// amountZeroThreshold prevents printing "-0.00".
 	amountZeroThreshold = 0.005
+
+	// connectTimeout is the maximum time to acquire a connection for a worker.
+	connectTimeout = 60 * time.Second
 )
@@ -0,0 +1,13 @@
+package ruperformance_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestRuperformance(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Ruperformance Suite")
+}
@@ -0,0 +1,137 @@
+package ruperformance_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbclient"
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface/fake"
+	"github.com/pgvillage-tools/dbtwool/pkg/pg"
+	"github.com/pgvillage-tools/dbtwool/pkg/ruperformance"
+	"github.com/pgvillage-tools/dbtwool/pkg/sqlite"
+	"github.com/pgvillage-tools/dbtwool/pkg/testrunner"
+)
+
+var _ = Describe("RU performance with a fake database", func() {
+	var (
+		ctx    context.Context
+		client *fake.Client
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		client = fake.NewClient()
+	})
+
+	Context("Stage", func() {
+		It("should create the table and index", func() {
+			Expect(ruperformance.Stage(ctx, dbclient.Postgres, client, "s", "t")).To(Succeed())
+			Expect(client.Script.Count("CREATE TABLE s.t")).To(Equal(1))
+			Expect(client.Script.Count("CREATE INDEX")).To(Equal(1))
+			Expect(client.Script.Count(fake.StmtCommit)).To(Equal(1))
+		})
+		It("should return an error when the index cannot be created", func() {
+			client.Script.On("CREATE INDEX").Fail(errors.New("exists"))
+			Expect(ruperformance.Stage(ctx, dbclient.DB2, client, "s", "t")).To(MatchError(ContainSubstring("exists")))
+		})
+	})
+
+	Context("Generate", func() {
+		It("should insert the rows in batches of 100", func() {
+			Expect(ruperformance.Generate(ctx, dbclient.Postgres, client, "s", "t", 250)).To(Succeed())
+			Expect(client.Script.Count("INSERT INTO s.t (acct_id, txn_ts, amount, descr) VALUES")).To(Equal(3))
+			Expect(client.Script.Count(fake.StmtCommit)).To(Equal(3))
+		})
+		It("should roll back and stop at a failing batch", func() {
+			client.Script.On("INSERT INTO").After(1).Fail(errors.New("disk full"))
+			err := ruperformance.Generate(ctx, dbclient.DB2, client, "s", "t", 250)
+			Expect(err).To(MatchError(ContainSubstring("rows 101..200")))
+			Expect(client.Script.Count(fake.StmtRollback)).To(Equal(1))
+			Expect(client.Script.Count("INSERT INTO")).To(Equal(2))
+		})
+		It("should reject an invalid number of rows", func() {
+			Expect(ruperformance.Generate(ctx, dbclient.Postgres, client, "s", "t", 0)).NotTo(Succeed())
+		})
+	})
+
+	Context("ExecuteTest", func() {
+		// rules are tried in order, so scripted failures need to be added before these
+		scriptWorkload := func() {
+			client.Script.On("SELECT COUNT(*)").Delay(time.Millisecond).
+				Return(map[string]any{"cnt": int64(1), "total_amt": 1.0})
+			client.Script.On("UPDATE s.t").Delay(time.Millisecond)
+		}
+		It("should retry expected errors", func() {
+			client.Script.On("UPDATE s.t").Times(5).
+				Fail(fake.NewError(dbinterface.ErrorClassDeadlock, "deadlock detected"))
+			scriptWorkload()
+			policy, err := testrunner.NewRetryPolicy(10, []string{"deadlock"}, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ruperformance.ExecuteTest(ctx, dbclient.Postgres, client, "s", "t", 1, 1, 2, 1,
+				pg.ReadCommitted, policy, dbinterface.SessionSettings{}, testrunner.ReconnectPolicy{}, nil)).
+				To(Succeed())
+			Expect(client.Script.Count(pg.ReadCommitted.AsQuery())).To(Equal(1))
+			var deadlocks int
+			for _, call := range client.Script.Calls() {
+				var fakeErr *fake.Error
+				if errors.As(call.Err, &fakeErr) {
+					deadlocks++
+				}
+			}
+			Expect(deadlocks).To(Equal(5))
+			Expect(client.Script.Count("UPDATE s.t")).To(BeNumerically(">", 5))
+		})
+		It("should fail on unexpected errors", func() {
+			client.Script.On("SELECT COUNT(*)").Fail(errors.New("relation does not exist"))
+			scriptWorkload()
+			policy, err := testrunner.NewRetryPolicy(0, nil, 0)
+			Expect(err).NotTo(HaveOccurred())
+			err = ruperformance.ExecuteTest(ctx, dbclient.Postgres, client, "s", "t", 1, 1, 1, 1,
+				pg.ReadCommitted, policy, dbinterface.SessionSettings{}, testrunner.ReconnectPolicy{}, nil)
+			Expect(err).To(MatchError(ContainSubstring("relation does not exist")))
+		})
+		It("should reconnect both roles when the connections are lost", func() {
+			lost := fake.NewError(dbinterface.ErrorClassConnectionLost,
+				"terminating connection due to administrator command")
+			client.Script.On("UPDATE s.t").After(2).Times(1).Fail(lost)
+			client.Script.On("SELECT COUNT(*)").After(2).Times(1).Fail(lost)
+			scriptWorkload()
+			reconnect := testrunner.ReconnectPolicy{Enabled: true, Backoff: time.Millisecond}
+			Expect(ruperformance.ExecuteTest(ctx, dbclient.Postgres, client, "s", "t", 1, 1, 1, 1,
+				pg.ReadCommitted, testrunner.RetryPolicy{}, dbinterface.SessionSettings{}, reconnect, nil)).
+				To(Succeed())
+			Expect(client.Script.Count(fake.StmtConnect)).To(Equal(4))
+			// the isolation level is set on the new olap connection as well
+			Expect(client.Script.Count(pg.ReadCommitted.AsQuery())).To(Equal(2))
+		})
+		It("should validate the number of workers", func() {
+			Expect(ruperformance.ExecuteTest(ctx, dbclient.Postgres, client, "s", "t", 1, 1, 0, 0,
+				pg.ReadCommitted, testrunner.RetryPolicy{}, dbinterface.SessionSettings{},
+				testrunner.ReconnectPolicy{}, nil)).NotTo(Succeed())
+		})
+	})
+
+	Context("SQLite", func() {
+		It("should create the index in the attached schema", func() {
+			Expect(ruperformance.Stage(ctx, dbclient.SQLite, client, "s", "t")).To(Succeed())
+			Expect(client.Script.Count("txn_ts TEXT NOT NULL")).To(Equal(1))
+			Expect(client.Script.Count("CREATE INDEX s.index_account_transaction_acct_t ON t")).To(Equal(1))
+		})
+		It("should compare timestamps with datetime()", func() {
+			client.Script.On("SELECT COUNT(*)").Delay(time.Millisecond).
+				Return(map[string]any{"cnt": int64(1), "total_amt": 1.0})
+			client.Script.On("UPDATE s.t").Delay(time.Millisecond)
+			Expect(ruperformance.ExecuteTest(ctx, dbclient.SQLite, client, "s", "t", 1, 1, 1, 1,
+				sqlite.Serializable, testrunner.RetryPolicy{}, dbinterface.SessionSettings{},
+				testrunner.ReconnectPolicy{}, nil)).To(Succeed())
+			Expect(client.Script.Count("FROM s.t WHERE acct_id BETWEEN 1 AND 50 AND txn_ts >= datetime(")).
+				To(BeNumerically(">", 0))
+			Expect(client.Script.Count("UPDATE s.t SET amount = amount + 1.00")).To(BeNumerically(">", 0))
+		})
+	})
+})
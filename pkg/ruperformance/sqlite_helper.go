@@ -0,0 +1,63 @@
+package ruperformance
+
+import (
+	"fmt"
+)
+
+// SQLiteHelper is a helper for generating queries for SQLite.
+// SQLite has no schemas; the client attaches a database file per schema instead.
+type SQLiteHelper struct {
+	schemaName string
+	tableName  string
+}
+
+// CreateSchemaSQL returns a no-op query, as the schema is attached by the client when connecting
+func (helper SQLiteHelper) CreateSchemaSQL() string {
+	sql := "SELECT 1;"
+
+	logger.Debug().Msg(sql)
+	return sql
+}
+
+// CreateTableSQL returns a CREATE TABLE query for SQLite.
+// Timestamps are stored as 'YYYY-MM-DD HH:MM:SS' text, which sorts and compares like datetime() output.
+func (helper SQLiteHelper) CreateTableSQL() string {
+	sql := fmt.Sprintf(`
+CREATE TABLE %v.%v (
+    acct_id   INTEGER NOT NULL,
+    txn_ts    TEXT NOT NULL,
+    amount    NUMERIC(12,2) NOT NULL,
+    descr     VARCHAR(100) NOT NULL
+);`, helper.schemaName, helper.tableName)
+	logger.Debug().Msg(sql)
+	return sql
+}
+
+// CreateIndexSQL returns a CREATE Index query for SQLite. The schema goes with the index name, not the table.
+func (helper SQLiteHelper) CreateIndexSQL() string {
+	sql := fmt.Sprintf(`
+CREATE INDEX %v.index_account_transaction_acct_%v
+    ON %v (acct_id, txn_ts);`, helper.schemaName, helper.tableName, helper.tableName)
+	logger.Debug().Msg(sql)
+	return sql
+}
+
+// CreateOlapSQL returns a select query which scanning for SQLite
+func (helper SQLiteHelper) CreateOlapSQL() string {
+	return fmt.Sprintf(`
+SELECT COUNT(*) AS cnt, SUM(amount) AS total_amt
+FROM   %s.%s
+WHERE  acct_id BETWEEN 1 AND 50
+  AND  txn_ts >= datetime('now', '-30 minutes')
+`, helper.schemaName, helper.tableName)
+}
+
+// CreateOltpSQL returns an update query for SQLite
+func (helper SQLiteHelper) CreateOltpSQL(id int64) string {
+	return fmt.Sprintf(`
+UPDATE %s.%s
+   SET amount = amount + 1.00
+ WHERE acct_id = %d
+   AND txn_ts >= datetime('now', '-30 minutes')
+`, helper.schemaName, helper.tableName, id)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/pgvillage-tools/dbtwool/pkg/dbclient"
 	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+	"github.com/pgvillage-tools/dbtwool/pkg/tracing"
 	"github.com/rs/zerolog/log"
 )
 
@@ -13,11 +14,13 @@ import (
 func Stage(ctx context.Context, dbType dbclient.RDBMS, client dbinterface.Client,
 	schemaName string, tableName string) (errorResult error) {
 	var logger = log.With().Logger()
+	ctx, span := tracing.Start(ctx, "ru-performance stage", tracing.RDBMS(dbType), tracing.Table(schemaName, tableName))
+	defer func() { tracing.End(span, errorResult) }()
 
 	logger.Info().Msg("Initiating connection pool.")
 	pool, poolErr := client.Pool(ctx)
 	if poolErr != nil {
-		logger.Fatal().Msgf("Failed to connect: %v", poolErr)
+		return fmt.Errorf("failed to connect: %w", poolErr)
 	}
 
 	logger.Info().Msg("Connecting to database.")
@@ -31,13 +34,7 @@ func Stage(ctx context.Context, dbType dbclient.RDBMS, client dbinterface.Client
 	if beginErr := conn.Begin(ctx); beginErr != nil {
 		return fmt.Errorf("error during begin transaction: %w", beginErr)
 	}
-	var dbHelper DBHelper
-
-	if dbType == dbclient.DB2 {
-		dbHelper = DB2Helper{schemaName: schemaName, tableName: tableName}
-	} else {
-		dbHelper = PGHelper{schemaName: schemaName, tableName: tableName}
-	}
+	dbHelper := getDBHelper(dbType, schemaName, tableName)
 
 	logger.Info().Msg("Executing create schema")
 
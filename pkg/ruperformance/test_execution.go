@@ -12,10 +12,15 @@ import (
 
 	"github.com/pgvillage-tools/dbtwool/pkg/dbclient"
 	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+	"github.com/pgvillage-tools/dbtwool/pkg/livemetrics"
+	"github.com/pgvillage-tools/dbtwool/pkg/result"
+	"github.com/pgvillage-tools/dbtwool/pkg/stats"
+	"github.com/pgvillage-tools/dbtwool/pkg/testrunner"
+	"github.com/pgvillage-tools/dbtwool/pkg/tracing"
 )
 
 // ExecuteTest runs a mixed OLTP (updates) + OLAP (aggregate reads) workload.
-// It reports how many OLAP queries completed during the measurement interval.
+// Every worker runs on its own connection, and throughput and latency are reported per role and aggregated.
 func ExecuteTest(
 	ctx context.Context,
 	dbType dbclient.RDBMS,
@@ -24,14 +29,31 @@ func ExecuteTest(
 	tableName string,
 	warmupTimeSec int,
 	executionTimeSec int,
+	oltpWorkers int,
+	olapWorkers int,
 	readIsolation dbinterface.IsolationLevel,
-) error {
+	retryPolicy testrunner.RetryPolicy,
+	settings dbinterface.SessionSettings,
+	reconnect testrunner.ReconnectPolicy,
+	run *result.Run,
+) (err error) {
+	ctx, span := tracing.Start(ctx, "ru-performance test", tracing.RDBMS(dbType), tracing.Table(schemaName, tableName))
+	defer func() { tracing.End(span, err) }()
 	if err := validateTimes(warmupTimeSec, executionTimeSec); err != nil {
 		return err
 	}
+	if err := validateWorkers(oltpWorkers, olapWorkers); err != nil {
+		return err
+	}
 
-	logger := testLogger(schemaName, tableName, warmupTimeSec, executionTimeSec)
+	logger := testLogger(schemaName, tableName, warmupTimeSec, executionTimeSec).With().
+		Int("oltp_workers", oltpWorkers).
+		Int("olap_workers", olapWorkers).
+		Logger()
 
+	if err := dbinterface.EnsurePoolSize(client, oltpWorkers+olapWorkers+run.Conns()); err != nil {
+		return err
+	}
 	pool, err := client.Pool(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to init pool: %w", err)
@@ -40,42 +62,62 @@ func ExecuteTest(
 	dbHelper := getDBHelper(dbType, schemaName, tableName)
 	olapSQL := dbHelper.CreateOlapSQL()
 
-	totalCtx, cancelTotal := context.WithTimeout(
-		ctx,
-		time.Duration(warmupTimeSec+executionTimeSec)*time.Second,
-	)
-	defer cancelTotal()
-
-	warmupCtx, cancelWarmup := context.WithTimeout(
-		totalCtx,
-		time.Duration(warmupTimeSec)*time.Second,
-	)
-	defer cancelWarmup()
+	warmupCtx, totalCtx, cancel := testrunner.WarmupAndTotalContexts(ctx, warmupTimeSec, executionTimeSec)
+	defer cancel()
 
-	oltpConn, closeOLTP, err := connectConn(totalCtx, pool)
+	oltpConns, err := testrunner.OpenConns(totalCtx, pool, oltpWorkers, connectTimeout)
 	if err != nil {
 		return fmt.Errorf("failed to connect for oltp: %w", err)
 	}
-	defer closeOLTP()
+	defer func() { testrunner.CloseAll(ctx, oltpConns) }()
 
-	olapConn, closeOLAP, err := connectConn(totalCtx, pool)
+	olapConns, err := testrunner.OpenConns(totalCtx, pool, olapWorkers, connectTimeout)
 	if err != nil {
 		return fmt.Errorf("failed to connect for olap: %w", err)
 	}
-	defer closeOLAP()
+	defer func() { testrunner.CloseAll(ctx, olapConns) }()
 
-	if err := olapConn.SetIsolationLevel(totalCtx, readIsolation); err != nil {
-		return fmt.Errorf("failed to set isolation on olap conn: %w", err)
+	for i, conn := range olapConns {
+		if err := conn.SetIsolationLevel(totalCtx, readIsolation); err != nil {
+			return fmt.Errorf("failed to set isolation on olap conn %d: %w", i, err)
+		}
+	}
+
+	if err := settings.ApplyAll(totalCtx, append(oltpConns, olapConns...)); err != nil {
+		return err
 	}
 
-	var metrics testMetrics
+	run.Start(ctx, pool)
+	defer run.Stop(ctx)
+
+	metrics := newTestMetrics()
+	livemetrics.RegisterOperations("ru-performance", metrics.oltp, metrics.olap)
+	run.Track(metrics.oltp, metrics.olap)
+	oltpReconnector := testrunner.NewReconnector(reconnect, pool, settings.Apply)
+	olapReconnector := testrunner.NewReconnector(reconnect, pool,
+		func(ctx context.Context, conn dbinterface.Connection) error {
+			if err := conn.SetIsolationLevel(ctx, readIsolation); err != nil {
+				return err
+			}
+			return settings.Apply(ctx, conn)
+		})
 
+	// workers replace lost connections in the slices, so that they are closed at the end
 	g, gctx := errgroup.WithContext(totalCtx)
-	g.Go(func() error { return runOLTPWorkerErr(gctx, dbHelper, oltpConn, &metrics) })
-	g.Go(func() error { return runOLAPWorkerErr(gctx, olapConn, olapSQL, &metrics) })
+	for i := range oltpConns {
+		g.Go(func() error {
+			return runOLTPWorkerErr(gctx, i, dbHelper, &oltpConns[i], oltpReconnector, metrics, retryPolicy)
+		})
+	}
+	for i := range olapConns {
+		g.Go(func() error {
+			return runOLAPWorkerErr(gctx, i, &olapConns[i], olapReconnector, olapSQL, metrics, retryPolicy)
+		})
+	}
 
 	<-warmupCtx.Done()
-	metrics.measuring.Store(1)
+	metrics.measurement.Start()
+	run.MeasurementStarted()
 
 	<-totalCtx.Done()
 
@@ -83,81 +125,137 @@ func ExecuteTest(
 		return err
 	}
 
-	logResults(logger, &metrics, executionTimeSec)
+	summary := metrics.summarize(executionTimeSec)
+	summary.reconnects = oltpReconnector.Outages().Reconnects() + olapReconnector.Outages().Reconnects()
+	summary.downtime = oltpReconnector.Outages().Downtime() + olapReconnector.Outages().Downtime()
+	run.Finish(ctx, []stats.OperationSummary{summary.oltp, summary.olap}, summary.reconnects, summary.downtime)
+	logResults(logger, summary)
 	return nil
 }
 
-func runOLTPWorkerErr(ctx context.Context, dbHelper DBHelper, conn dbinterface.Connection, m *testMetrics) error {
-	var step int64
+func runOLTPWorkerErr(
+	ctx context.Context,
+	workerID int,
+	dbHelper DBHelper,
+	conn *dbinterface.Connection,
+	reconnector *testrunner.Reconnector,
+	m *testMetrics,
+	policy testrunner.RetryPolicy,
+) error {
 	for {
 		if ctx.Err() != nil {
 			return nil
 		}
 
-		if err := conn.Begin(ctx); err != nil {
+		measuring := m.measurement.Active()
+		start := time.Now()
+		step := m.nextStep()
+		queryCtx, span := tracing.StartQuery(ctx, "ru-performance oltp")
+		class, err := policy.Run(queryCtx, *conn,
+			func() error { return runOLTPTransaction(queryCtx, dbHelper, *conn, step) },
+			failureRecorder(m.oltp, measuring))
+		tracing.End(span, err)
+		if err != nil {
 			if ctx.Err() != nil {
 				return nil
 			}
-			return fmt.Errorf("oltp begin failed: %w", err)
-		}
-
-		sql := dbHelper.CreateOltpSQL(step)
-		step++
-
-		if _, err := conn.Execute(ctx, sql); err != nil {
-			_ = conn.Rollback(ctx)
-			if ctx.Err() != nil {
-				return nil
+			if reconnector.Recover(ctx, workerID, conn, err) {
+				continue
+			}
+			if policy.Expected(class) {
+				continue
 			}
-			return fmt.Errorf("oltp execute failed: %w", err)
+			return fmt.Errorf("oltp worker %d: %w", workerID, err)
 		}
 
-		if err := conn.Commit(ctx); err != nil {
-			if ctx.Err() != nil {
-				return nil
-			}
-			return fmt.Errorf("oltp commit failed: %w", err)
+		if measuring {
+			m.oltp.Observe(time.Since(start))
 		}
+	}
+}
+
+func runOLTPTransaction(ctx context.Context, dbHelper DBHelper, conn dbinterface.Connection, step int64) error {
+	if err := conn.Begin(ctx); err != nil {
+		return fmt.Errorf("oltp begin failed: %w", err)
+	}
 
-		m.oltpOps.Add(1)
+	if _, err := conn.Execute(ctx, dbHelper.CreateOltpSQL(step)); err != nil {
+		_ = conn.Rollback(ctx)
+		return fmt.Errorf("oltp execute failed: %w", err)
 	}
+
+	if err := conn.Commit(ctx); err != nil {
+		return fmt.Errorf("oltp commit failed: %w", err)
+	}
+	return nil
 }
 
-func runOLAPWorkerErr(ctx context.Context, conn dbinterface.Connection, olapSQL string, m *testMetrics) error {
+func runOLAPWorkerErr(
+	ctx context.Context,
+	workerID int,
+	conn *dbinterface.Connection,
+	reconnector *testrunner.Reconnector,
+	olapSQL string,
+	m *testMetrics,
+	policy testrunner.RetryPolicy,
+) error {
 	for {
 		if ctx.Err() != nil {
 			return nil
 		}
 
-		if _, err := conn.QueryOneRow(ctx, olapSQL); err != nil {
+		measuring := m.measurement.Active()
+		start := time.Now()
+		queryCtx, span := tracing.StartQuery(ctx, "ru-performance olap")
+		class, err := policy.Run(queryCtx, *conn,
+			func() error {
+				_, queryErr := (*conn).QueryOneRow(queryCtx, olapSQL)
+				return queryErr
+			},
+			failureRecorder(m.olap, measuring))
+		tracing.End(span, err)
+		if err != nil {
 			if ctx.Err() != nil {
 				return nil
 			}
-			return fmt.Errorf("olap query failed: %w", err)
+			if reconnector.Recover(ctx, workerID, conn, err) {
+				continue
+			}
+			if policy.Expected(class) {
+				continue
+			}
+			return fmt.Errorf("olap worker %d: query failed: %w", workerID, err)
 		}
 
-		if m.measuring.Load() == 1 {
-			m.olapCompleted.Add(1)
-			m.markStart()
+		if measuring {
+			m.olap.Observe(time.Since(start))
 		}
 	}
 }
 
-// getDBHelper makes ExecuteTest just a bit shorter
-func getDBHelper(rdbms dbclient.RDBMS, schemaName, tableName string) DBHelper {
-	if rdbms == dbclient.DB2 {
-		return DB2Helper{schemaName: schemaName, tableName: tableName}
+// failureRecorder returns a callback which counts failed attempts (and retries) while measuring
+func failureRecorder(op *stats.Operation, measuring bool) func(dbinterface.ErrorClass, bool) {
+	return func(class dbinterface.ErrorClass, retrying bool) {
+		if !measuring {
+			return
+		}
+		op.FailWith(string(class))
+		if retrying {
+			op.Retry()
+		}
 	}
-	return PGHelper{schemaName: schemaName, tableName: tableName}
 }
 
-func connectConn(ctx context.Context, pool dbinterface.Pool) (dbinterface.Connection, func(), error) {
-	conn, err := pool.Connect(ctx)
-	if err != nil {
-		return nil, func() {}, err
+// getDBHelper makes ExecuteTest just a bit shorter
+func getDBHelper(rdbms dbclient.RDBMS, schemaName, tableName string) DBHelper {
+	switch rdbms {
+	case dbclient.DB2:
+		return DB2Helper{schemaName: schemaName, tableName: tableName}
+	case dbclient.SQLite:
+		return SQLiteHelper{schemaName: schemaName, tableName: tableName}
+	default:
+		return PGHelper{schemaName: schemaName, tableName: tableName}
 	}
-	cleanup := func() { conn.Close(ctx) }
-	return conn, cleanup, nil
 }
 
 func validateTimes(warmupTimeSec, executionTimeSec int) error {
@@ -170,6 +268,16 @@ func validateTimes(warmupTimeSec, executionTimeSec int) error {
 	return nil
 }
 
+func validateWorkers(oltpWorkers, olapWorkers int) error {
+	if oltpWorkers <= 0 {
+		return errors.New("oltpWorkers must be > 0")
+	}
+	if olapWorkers <= 0 {
+		return errors.New("olapWorkers must be > 0")
+	}
+	return nil
+}
+
 func testLogger(schemaName, tableName string, warmupTimeSec, executionTimeSec int) zerolog.Logger {
 	return log.With().
 		Str("schema", schemaName).
@@ -179,23 +287,33 @@ func testLogger(schemaName, tableName string, warmupTimeSec, executionTimeSec in
 		Logger()
 }
 
-func logResults(
-	logger zerolog.Logger,
-	metrics *testMetrics,
-	executionTimeSec int,
-) {
-	start := metrics.startTimeOrFallback(executionTimeSec)
-	elapsed := time.Since(start)
-	if elapsed <= 0 {
-		elapsed = time.Duration(executionTimeSec) * time.Second
+func logResults(logger zerolog.Logger, summary testSummary) {
+	for _, role := range []stats.OperationSummary{summary.oltp, summary.olap} {
+		logger.Info().
+			Str("role", role.Name).
+			Int64("ops", role.Count).
+			Int64("errors", role.Errors).
+			Interface("errors_by_class", role.ErrorsByClass).
+			Int64("retries", role.Retries).
+			Float64("per_sec", role.PerSecond).
+			Float64("mean_ms", role.Latency.MeanMs).
+			Float64("p50_ms", role.Latency.P50Ms).
+			Float64("p95_ms", role.Latency.P95Ms).
+			Float64("p99_ms", role.Latency.P99Ms).
+			Float64("max_ms", role.Latency.MaxMs).
+			Msgf("Results for %s workers", role.Name)
 	}
 
-	olap := metrics.olapCompleted.Load()
-	oltp := metrics.oltpOps.Load()
-
 	logger.Info().
-		Int64("oltp_ops", oltp).
-		Int64("olap_completed", olap).
-		Float64("olap_per_sec", float64(olap)/elapsed.Seconds()).
+		Int64("oltp_ops", summary.oltp.Count).
+		Int64("olap_completed", summary.olap.Count).
+		Float64("olap_per_sec", summary.olap.PerSecond).
+		Int64("total_ops", summary.totalOps).
+		Int64("total_errors", summary.totalErrors).
+		Interface("errors_by_class", summary.errorsByClass).
+		Int64("total_retries", summary.totalRetries).
+		Float64("total_per_sec", summary.totalPerSecond).
+		Int("reconnects", summary.reconnects).
+		Dur("downtime", summary.downtime).
 		Msg("Isolation read performance test finished")
 }
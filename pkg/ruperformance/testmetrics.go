@@ -1,27 +1,69 @@
 package ruperformance
 
 import (
-	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/stats"
+	"github.com/pgvillage-tools/dbtwool/pkg/testrunner"
+)
+
+const (
+	roleOLTP = "oltp"
+	roleOLAP = "olap"
 )
 
+// testMetrics holds the metrics per role. All workers of a role share the same stats.Operation.
 type testMetrics struct {
-	measuring     atomic.Int32
-	olapCompleted atomic.Int64
-	oltpOps       atomic.Int64
+	measurement testrunner.Measurement
+	// oltpStep is shared by all oltp workers, so that every update hits another row
+	oltpStep atomic.Int64
 
-	startOnce sync.Once
-	startTime atomic.Int64 // unix nano
+	oltp *stats.Operation
+	olap *stats.Operation
 }
 
-func (m *testMetrics) markStart() {
-	m.startOnce.Do(func() { m.startTime.Store(time.Now().UnixNano()) })
+func newTestMetrics() *testMetrics {
+	return &testMetrics{
+		oltp: stats.NewOperation(roleOLTP),
+		olap: stats.NewOperation(roleOLAP),
+	}
 }
 
-func (m *testMetrics) startTimeOrFallback(executionTimeSec int) time.Time {
-	if ns := m.startTime.Load(); ns != 0 {
-		return time.Unix(0, ns)
+// nextStep returns the step for the next oltp transaction
+func (m *testMetrics) nextStep() int64 {
+	return m.oltpStep.Add(1) - 1
+}
+
+// testSummary holds the results per role and aggregated over all roles
+type testSummary struct {
+	oltp           stats.OperationSummary
+	olap           stats.OperationSummary
+	totalOps       int64
+	totalErrors    int64
+	totalRetries   int64
+	totalPerSecond float64
+	errorsByClass  map[string]int64
+	// reconnects and downtime are only set when workers reconnect after a lost connection
+	reconnects int
+	downtime   time.Duration
+}
+
+func (m *testMetrics) summarize(executionTimeSec int) testSummary {
+	elapsed := m.measurement.Elapsed(time.Duration(executionTimeSec) * time.Second)
+	s := testSummary{
+		oltp: m.oltp.Summarize(elapsed),
+		olap: m.olap.Summarize(elapsed),
+	}
+	s.totalOps = s.oltp.Count + s.olap.Count
+	s.totalErrors = s.oltp.Errors + s.olap.Errors
+	s.totalRetries = s.oltp.Retries + s.olap.Retries
+	s.totalPerSecond = s.oltp.PerSecond + s.olap.PerSecond
+	s.errorsByClass = map[string]int64{}
+	for _, role := range []stats.OperationSummary{s.oltp, s.olap} {
+		for class, count := range role.ErrorsByClass {
+			s.errorsByClass[class] += count
+		}
 	}
-	return time.Now().Add(-time.Duration(executionTimeSec) * time.Second)
+	return s
 }
@@ -0,0 +1,143 @@
+// Package secrets keeps track of the passwords dbtwool uses, and masks them in everything that is logged.
+// Secrets are only masked in string values, so that the JSON of log lines, result documents and the history stays
+// valid whatever the secret is. The global logger (see package logging) writes through a redacting writer, and
+// NewWriter and RedactJSON can be used to do the same for any other JSON output.
+package secrets
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+const (
+	// Mask replaces every secret in redacted output
+	Mask = "xxxxx"
+	// MinLength is the minimum length of a secret which is masked. Shorter secrets would mask every value which
+	// happens to contain them, such as numbers and short words.
+	MinLength = 4
+)
+
+// ErrTooShort is returned by Register for secrets which are too short to be masked
+var ErrTooShort = errors.New("secret is too short to be masked")
+
+var (
+	mu       sync.RWMutex
+	secrets  []string
+	replacer = strings.NewReplacer()
+)
+
+// Register adds a secret which is masked from now on. Empty secrets are ignored, and secrets shorter than MinLength
+// are not masked and return an error wrapping ErrTooShort, so that the caller can warn about it.
+func Register(secret string) error {
+	if secret == "" {
+		return nil
+	}
+	if utf8.RuneCountInString(secret) < MinLength {
+		return fmt.Errorf("%w: it has less than %d characters", ErrTooShort, MinLength)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if slices.Contains(secrets, secret) {
+		return nil
+	}
+	secrets = append(secrets, secret)
+	// replace longer secrets first, so that a secret containing another secret is masked completely
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	var oldNew []string
+	for _, s := range secrets {
+		oldNew = append(oldNew, s, Mask)
+	}
+	replacer = strings.NewReplacer(oldNew...)
+	return nil
+}
+
+// Redact returns s with all registered secrets masked
+func Redact(s string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return replacer.Replace(s)
+}
+
+// RedactJSON returns data with all registered secrets masked in its string values. Keys, numbers and the structure
+// of the JSON are left alone.
+func RedactJSON(data []byte) []byte {
+	redacted := make([]byte, 0, len(data))
+	for i := 0; i < len(data); {
+		if data[i] != '"' {
+			redacted = append(redacted, data[i])
+			i++
+			continue
+		}
+		end := stringEnd(data, i)
+		if isKey(data[end:]) {
+			redacted = append(redacted, data[i:end]...)
+		} else {
+			redacted = append(redacted, redactString(data[i:end])...)
+		}
+		i = end
+	}
+	return redacted
+}
+
+// stringEnd returns the index after the closing quote of the JSON string which starts at start
+func stringEnd(data []byte, start int) int {
+	for i := start + 1; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(data)
+}
+
+// isKey returns true when rest (the JSON after a string) starts with a colon, which makes the string a key
+func isKey(rest []byte) bool {
+	rest = bytes.TrimLeft(rest, " \t\r\n")
+	return len(rest) > 0 && rest[0] == ':'
+}
+
+// redactString masks the secrets in a quoted JSON string, and quotes it again
+func redactString(quoted []byte) []byte {
+	var value string
+	if err := json.Unmarshal(quoted, &value); err != nil {
+		return quoted
+	}
+	redacted := Redact(value)
+	if redacted == value {
+		return quoted
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	// encoding a string can not fail
+	_ = encoder.Encode(redacted)
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+}
+
+type writer struct {
+	w io.Writer
+}
+
+// NewWriter returns a writer which masks all registered secrets in the string values of the JSON it writes to w.
+// Secrets are only masked when a JSON document is written in one call, which is the case for zerolog lines.
+func NewWriter(w io.Writer) io.Writer {
+	return writer{w: w}
+}
+
+// Write implements io.Writer. It reports len(p) as written, since the redacted output can have another length.
+func (rw writer) Write(p []byte) (int, error) {
+	if _, err := rw.w.Write(RedactJSON(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,64 @@
+package servermetrics
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/sqlutil"
+)
+
+// DB2Queries returns the statistics queries for DB2, which use the MON_GET_* table functions of all members (-2).
+// Unquoted names are stored in uppercase in the catalog, so schema and table are converted to uppercase. The
+// tablespace is the one the LOBs of the table are stored in (LONG_TBSPACE), or the regular tablespace otherwise.
+// Bufferpool hit ratios follow from the deltas as 1 - p_reads / l_reads.
+func DB2Queries(schema string, table string) []Query {
+	schema, table = strings.ToUpper(schema), strings.ToUpper(table)
+	return []Query{
+		{
+			Name: "mon_get_database",
+			SQL: `SELECT total_app_commits, total_app_rollbacks, rows_read, rows_returned, rows_modified,
+  pool_data_l_reads, pool_data_p_reads, pool_index_l_reads, pool_index_p_reads, direct_reads, direct_writes,
+  lock_waits, lock_wait_time, lock_timeouts, deadlocks, total_cpu_time, total_rqst_time
+FROM TABLE(MON_GET_DATABASE(-2))`,
+		},
+		{
+			Name: "mon_get_bufferpool",
+			SQL: `SELECT bp_name, pool_data_l_reads, pool_data_p_reads, pool_index_l_reads, pool_index_p_reads,
+  pool_xda_l_reads, pool_xda_p_reads, pool_data_writes, pool_index_writes
+FROM TABLE(MON_GET_BUFFERPOOL('', -2))`,
+			Keys: []string{"bp_name"},
+		},
+		{
+			Name: "mon_get_table",
+			SQL: fmt.Sprintf(`SELECT tabschema, tabname, rows_read, rows_inserted, rows_updated, rows_deleted,
+  table_scans, object_data_l_reads, object_data_p_reads, object_lob_l_reads, object_lob_p_reads
+FROM TABLE(MON_GET_TABLE(%s, %s, -2))`, sqlutil.Quote(schema), sqlutil.Quote(table)),
+			Keys: []string{"tabschema", "tabname"},
+		},
+		{
+			Name: "mon_get_tablespace",
+			SQL: fmt.Sprintf(`SELECT tbsp_name, pool_data_l_reads, pool_data_p_reads, direct_reads, direct_writes,
+  direct_read_reqs, direct_write_reqs, direct_read_time, direct_write_time
+FROM TABLE(MON_GET_TABLESPACE('', -2))
+WHERE tbsp_name IN (SELECT COALESCE(long_tbspace, tbspace) FROM syscat.tables
+  WHERE tabschema = %s AND tabname = %s)`, sqlutil.Quote(schema), sqlutil.Quote(table)),
+			Keys: []string{"tbsp_name"},
+		},
+		{
+			// the connections of the test stay open during the measurement window, so their counters are summed
+			Name: "mon_get_connection",
+			SQL: `SELECT SUM(lock_waits) AS lock_waits, SUM(lock_wait_time) AS lock_wait_time,
+  SUM(lock_timeouts) AS lock_timeouts, SUM(lock_escals) AS lock_escals, SUM(deadlocks) AS deadlocks
+FROM TABLE(MON_GET_CONNECTION(NULL, -2))
+WHERE application_handle <> MON_GET_APPLICATION_HANDLE()`,
+		},
+		{
+			Name: "mon_get_pkg_cache_stmt",
+			SQL: fmt.Sprintf(`SELECT VARCHAR(SUBSTR(stmt_text, 1, 120)) AS stmt, num_executions, stmt_exec_time,
+  rows_read, rows_returned, pool_data_l_reads, pool_data_p_reads, total_cpu_time, lock_wait_time
+FROM TABLE(MON_GET_PKG_CACHE_STMT(NULL, NULL, NULL, -2))
+WHERE UPPER(VARCHAR(SUBSTR(stmt_text, 1, 4000))) LIKE %s`, sqlutil.Quote("%"+schema+"."+table+"%")),
+			Keys: []string{"stmt"},
+		},
+	}
+}
@@ -0,0 +1,48 @@
+// Package servermetrics samples statistics of the database server on a separate connection while a test runs.
+// Cumulative counters (blocks read, transactions, rows, etc.) are reported as the delta over the measurement window,
+// and point in time observations (like sessions per wait event) are summed over all samples in the window, so that
+// a difference in throughput can be explained and not only reported.
+package servermetrics
+
+import (
+	"github.com/pgvillage-tools/dbtwool/pkg/dbclient"
+	"github.com/pgvillage-tools/dbtwool/pkg/logging"
+)
+
+var logger = logging.Logger()
+
+const bitSize64 = 64
+
+// Kind defines how the values of a Query are aggregated
+type Kind int
+
+const (
+	// Counters are cumulative values, reported as the delta between the start and the end of the window
+	Counters Kind = iota
+	// Samples are point in time values, which are summed over all samples in the window
+	Samples
+)
+
+// Query is a statistics query. Every row becomes a group, which is named after Name and the values of the Keys
+// columns (e.g. pg_statio_user_tables:dbtwooltests.lobtable). All other numeric columns become values of the group.
+type Query struct {
+	Name string
+	SQL  string
+	Keys []string
+	Kind Kind
+	// Optional queries depend on the server version or an extension. When they fail, they are skipped.
+	Optional bool
+}
+
+// QueriesFor returns the statistics queries for an RDBMS, where schema and table select the table under test.
+// No queries are returned for an RDBMS without server statistics (SQLite).
+func QueriesFor(dbType dbclient.RDBMS, schema string, table string) []Query {
+	switch dbType {
+	case dbclient.Postgres:
+		return PostgresQueries(schema, table)
+	case dbclient.DB2:
+		return DB2Queries(schema, table)
+	default:
+		return nil
+	}
+}
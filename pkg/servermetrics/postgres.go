@@ -0,0 +1,56 @@
+package servermetrics
+
+import (
+	"fmt"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/sqlutil"
+)
+
+// PostgresQueries returns the statistics queries for PostgreSQL. pg_stat_io (PostgreSQL 16 and up) and
+// pg_stat_statements (an extension) are optional. Statements are only collected when they mention the table.
+func PostgresQueries(schema string, table string) []Query {
+	return []Query{
+		{
+			Name: "pg_stat_database",
+			SQL: `SELECT xact_commit, xact_rollback, blks_read, blks_hit, tup_returned, tup_fetched,
+  tup_inserted, tup_updated, tup_deleted, temp_files, temp_bytes, deadlocks
+FROM pg_stat_database WHERE datname = current_database()`,
+		},
+		{
+			Name: "pg_stat_activity",
+			SQL: `SELECT coalesce(wait_event_type || ':' || wait_event, 'CPU') AS wait_event, count(*) AS sessions
+FROM pg_stat_activity
+WHERE datname = current_database() AND state = 'active' AND pid <> pg_backend_pid()
+GROUP BY 1`,
+			Keys: []string{"wait_event"},
+			Kind: Samples,
+		},
+		{
+			Name: "pg_statio_user_tables",
+			SQL: fmt.Sprintf(`SELECT schemaname, relname, heap_blks_read, heap_blks_hit, idx_blks_read, idx_blks_hit,
+  toast_blks_read, toast_blks_hit, tidx_blks_read, tidx_blks_hit
+FROM pg_statio_user_tables WHERE schemaname = %s AND relname = %s`, sqlutil.Quote(schema), sqlutil.Quote(table)),
+			Keys: []string{"schemaname", "relname"},
+		},
+		{
+			Name: "pg_stat_io",
+			SQL: `SELECT backend_type, object, context, reads, writes, extends, hits, evictions, fsyncs
+FROM pg_stat_io`,
+			Keys:     []string{"backend_type", "object", "context"},
+			Optional: true,
+		},
+		{
+			Name: "pg_stat_statements",
+			SQL: fmt.Sprintf(`SELECT left(regexp_replace(query, '\s+', ' ', 'g'), 120) AS query,
+  sum(calls)::float8 AS calls, sum(total_exec_time) AS total_exec_time_ms, sum(rows)::float8 AS rows,
+  sum(shared_blks_hit)::float8 AS shared_blks_hit, sum(shared_blks_read)::float8 AS shared_blks_read,
+  sum(temp_blks_written)::float8 AS temp_blks_written
+FROM pg_stat_statements
+WHERE dbid = (SELECT oid FROM pg_database WHERE datname = current_database())
+  AND query ILIKE %s
+GROUP BY 1`, sqlutil.Quote("%"+schema+"."+table+"%")),
+			Keys:     []string{"query"},
+			Optional: true,
+		},
+	}
+}
@@ -0,0 +1,238 @@
+package servermetrics
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+	"github.com/pgvillage-tools/dbtwool/pkg/sqlutil"
+)
+
+// Groups holds values by group and name
+type Groups map[string]map[string]float64
+
+func (g Groups) add(group string, name string, value float64) {
+	if g[group] == nil {
+		g[group] = map[string]float64{}
+	}
+	g[group][name] += value
+}
+
+// Snapshot holds the results of all queries at one moment
+type Snapshot struct {
+	Time     time.Time
+	Counters Groups
+	Samples  Groups
+}
+
+// Metrics holds the server statistics over the measurement window
+type Metrics struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	// SampleCount is the number of samples in the window, which the Samples are summed over
+	SampleCount int `json:"sample_count"`
+	// Deltas holds the increase of every counter over the window
+	Deltas Groups `json:"deltas"`
+	// Samples holds the sum of every sampled value over the window
+	Samples Groups `json:"samples,omitempty"`
+	// Unavailable lists the optional queries which could not be run (e.g. an extension which is not installed)
+	Unavailable []string `json:"unavailable,omitempty"`
+}
+
+// Sampler runs the statistics queries on its own connection every interval, from Start until Stop. All samples are
+// taken on one go routine, so the state needs no locking.
+type Sampler struct {
+	interval time.Duration
+	queries  []Query
+
+	conn     dbinterface.Connection
+	cancel   context.CancelFunc
+	done     chan struct{}
+	mark     chan struct{}
+	skipped  map[string]bool
+	baseline *Snapshot
+	last     *Snapshot
+	samples  Groups
+	count    int
+}
+
+// NewSampler returns a Sampler for queries, which samples every interval
+func NewSampler(interval time.Duration, queries []Query) *Sampler {
+	return &Sampler{
+		interval: interval,
+		queries:  queries,
+		skipped:  map[string]bool{},
+		samples:  Groups{},
+		done:     make(chan struct{}),
+		mark:     make(chan struct{}, 1),
+	}
+}
+
+// Start connects, takes the first sample and keeps sampling in the background until Stop is called. The first
+// sample is the baseline until MarkStart is called.
+func (s *Sampler) Start(ctx context.Context, pool dbinterface.Pool) error {
+	conn, err := pool.Connect(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect for server metrics: %w", err)
+	}
+	s.conn = conn
+	first, err := s.sample(ctx)
+	if err != nil {
+		_ = conn.Close(ctx)
+		return err
+	}
+	s.baseline = &first
+	ctx, s.cancel = context.WithCancel(ctx)
+	go s.loop(ctx)
+	return nil
+}
+
+// MarkStart makes the next sample, which is taken right away, the baseline of the measurement window.
+// Samples which were summed before are discarded.
+func (s *Sampler) MarkStart() {
+	select {
+	case s.mark <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Sampler) loop(ctx context.Context) {
+	defer close(s.done)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		marked := false
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.mark:
+			marked = true
+		case <-ticker.C:
+		}
+		snapshot, err := s.sample(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Warn().Err(err).Msg("Sampling server metrics failed")
+			}
+			continue
+		}
+		if marked {
+			s.baseline = &snapshot
+			s.samples = Groups{}
+			s.count = 0
+		} else {
+			s.record(snapshot)
+		}
+	}
+}
+
+func (s *Sampler) record(snapshot Snapshot) {
+	s.last = &snapshot
+	s.count++
+	for group, values := range snapshot.Samples {
+		for name, value := range values {
+			s.samples.add(group, name, value)
+		}
+	}
+}
+
+// Stop stops sampling, takes a last sample and closes the connection. It returns the metrics between the baseline
+// and the last sample.
+func (s *Sampler) Stop(ctx context.Context) Metrics {
+	s.cancel()
+	<-s.done
+	defer func() { _ = s.conn.Close(ctx) }()
+	if snapshot, err := s.sample(ctx); err != nil {
+		logger.Warn().Err(err).Msg("Sampling server metrics at the end of the test failed")
+	} else {
+		s.record(snapshot)
+	}
+	metrics := Metrics{Start: s.baseline.Time, SampleCount: s.count, Samples: s.samples, Deltas: Groups{}}
+	if s.last != nil {
+		metrics.End = s.last.Time
+		metrics.Deltas = Delta(*s.baseline, *s.last)
+	}
+	for name := range s.skipped {
+		metrics.Unavailable = append(metrics.Unavailable, name)
+	}
+	sort.Strings(metrics.Unavailable)
+	return metrics
+}
+
+// Delta returns the increase of every counter from start to end. Counters which were reset in between (e.g. a
+// statement which was evicted from pg_stat_statements) count from 0.
+func Delta(start Snapshot, end Snapshot) Groups {
+	deltas := Groups{}
+	for group, values := range end.Counters {
+		for name, value := range values {
+			delta := value - start.Counters[group][name]
+			if delta < 0 {
+				delta = value
+			}
+			deltas.add(group, name, delta)
+		}
+	}
+	return deltas
+}
+
+// sample runs all queries once. Optional queries which fail are skipped from then on, other failures are returned.
+func (s *Sampler) sample(ctx context.Context) (Snapshot, error) {
+	snapshot := Snapshot{Time: time.Now(), Counters: Groups{}, Samples: Groups{}}
+	for _, query := range s.queries {
+		if s.skipped[query.Name] {
+			continue
+		}
+		rows, err := s.conn.Query(ctx, query.SQL)
+		if err != nil {
+			if query.Optional && ctx.Err() == nil {
+				logger.Info().Err(err).Str("query", query.Name).Msg("Server metrics are not available, skipping")
+				s.skipped[query.Name] = true
+				continue
+			}
+			return Snapshot{}, fmt.Errorf("failed to query %s: %w", query.Name, err)
+		}
+		target := snapshot.Counters
+		if query.Kind == Samples {
+			target = snapshot.Samples
+		}
+		for _, row := range rows {
+			group := sqlutil.GroupName(query.Name, query.Keys, row)
+			for column, value := range row {
+				if sqlutil.IsKey(query.Keys, column) {
+					continue
+				}
+				if number, ok := toFloat(value); ok {
+					target.add(group, column, number)
+				}
+			}
+		}
+	}
+	return snapshot, nil
+}
+
+// toFloat converts the numeric types drivers return. Values which are not numeric (or NULL) are skipped.
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case string:
+		number, err := strconv.ParseFloat(strings.TrimSpace(v), bitSize64)
+		return number, err == nil
+	default:
+		return 0, false
+	}
+}
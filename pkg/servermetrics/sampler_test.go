@@ -0,0 +1,111 @@
+package servermetrics_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbclient"
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface/fake"
+	"github.com/pgvillage-tools/dbtwool/pkg/servermetrics"
+)
+
+var _ = Describe("Server metrics", func() {
+	Context("Delta", func() {
+		It("should return the increase of every counter", func() {
+			start := servermetrics.Snapshot{Counters: servermetrics.Groups{"db": {"blks_hit": 10, "blks_read": 5}}}
+			end := servermetrics.Snapshot{Counters: servermetrics.Groups{"db": {"blks_hit": 25, "blks_read": 5}}}
+			Expect(servermetrics.Delta(start, end)).To(Equal(servermetrics.Groups{
+				"db": {"blks_hit": 15, "blks_read": 0},
+			}))
+		})
+		It("should count a counter which was reset from 0", func() {
+			start := servermetrics.Snapshot{Counters: servermetrics.Groups{"stmt:q": {"calls": 100}}}
+			end := servermetrics.Snapshot{Counters: servermetrics.Groups{
+				"stmt:q": {"calls": 7},
+				"stmt:r": {"calls": 3},
+			}}
+			Expect(servermetrics.Delta(start, end)).To(Equal(servermetrics.Groups{
+				"stmt:q": {"calls": 7},
+				"stmt:r": {"calls": 3},
+			}))
+		})
+	})
+
+	Context("QueriesFor", func() {
+		It("should quote the schema and table", func() {
+			queries := servermetrics.QueriesFor(dbclient.Postgres, "s", "t'x")
+			Expect(queries).NotTo(BeEmpty())
+			var statio string
+			for _, query := range queries {
+				if query.Name == "pg_statio_user_tables" {
+					statio = query.SQL
+				}
+			}
+			Expect(statio).To(ContainSubstring("relname = 't''x'"))
+		})
+		It("should select the table under test in uppercase for DB2", func() {
+			queries := servermetrics.QueriesFor(dbclient.DB2, "dbtwooltests", "lobtable")
+			Expect(queries).NotTo(BeEmpty())
+			for _, query := range queries {
+				Expect(query.SQL).To(ContainSubstring("MON_GET_"))
+				Expect(query.SQL).NotTo(ContainSubstring("lobtable"))
+			}
+		})
+		It("should return no queries for SQLite", func() {
+			Expect(servermetrics.QueriesFor(dbclient.SQLite, "s", "t")).To(BeEmpty())
+		})
+	})
+
+	Context("Sampler", func() {
+		var (
+			ctx    context.Context
+			client *fake.Client
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			client = fake.NewClient()
+		})
+
+		It("should report the deltas and samples of the measurement window", func() {
+			// the first sample at Start and the sample at MarkStart
+			client.Script.On("FROM pg_stat_database").Times(2).Return(map[string]any{"blks_hit": int64(10)})
+			client.Script.On("FROM pg_stat_database").Return(map[string]any{"blks_hit": int64(25)})
+			client.Script.On("FROM pg_stat_activity").Return(
+				map[string]any{"wait_event": "Lock:tuple", "sessions": int64(2)},
+				map[string]any{"wait_event": "CPU", "sessions": int64(1)})
+			client.Script.On("FROM pg_stat_io").Fail(errors.New(`relation "pg_stat_io" does not exist`))
+			pool, err := client.Pool(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			sampler := servermetrics.NewSampler(5*time.Millisecond, servermetrics.PostgresQueries("s", "t"))
+			Expect(sampler.Start(ctx, pool)).To(Succeed())
+			sampler.MarkStart()
+			time.Sleep(30 * time.Millisecond)
+			metrics := sampler.Stop(ctx)
+
+			Expect(metrics.SampleCount).To(BeNumerically(">", 0))
+			Expect(metrics.Deltas["pg_stat_database"]).To(HaveKeyWithValue("blks_hit", 15.0))
+			Expect(metrics.Samples["pg_stat_activity:Lock:tuple"]).To(
+				HaveKeyWithValue("sessions", 2.0*float64(metrics.SampleCount)))
+			Expect(metrics.Samples["pg_stat_activity:CPU"]).To(
+				HaveKeyWithValue("sessions", float64(metrics.SampleCount)))
+			Expect(metrics.Unavailable).To(Equal([]string{"pg_stat_io"}))
+			// optional queries which fail are not retried
+			Expect(client.Script.Count("FROM pg_stat_io")).To(Equal(1))
+			Expect(pool.(*fake.Pool).Open()).To(Equal(0))
+		})
+		It("should fail to start when a query which is not optional fails", func() {
+			client.Script.On("FROM pg_stat_database").Fail(errors.New("permission denied"))
+			pool, err := client.Pool(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			sampler := servermetrics.NewSampler(time.Second, servermetrics.PostgresQueries("s", "t"))
+			Expect(sampler.Start(ctx, pool)).To(MatchError(ContainSubstring("permission denied")))
+			Expect(pool.(*fake.Pool).Open()).To(Equal(0))
+		})
+	})
+})
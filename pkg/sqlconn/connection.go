@@ -0,0 +1,212 @@
+// Package sqlconn implements dbinterface.Connection on top of database/sql. The DB2 and SQLite clients embed its
+// Connection, and only add what differs per RDBMS (lock timeouts, error classification, bulk loads).
+package sqlconn
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+)
+
+// Connection is a wrapper over sql.Conn, so that we can add methods
+type Connection struct {
+	conn *sql.Conn
+	tx   *sql.Tx
+	// statementTimeout is enforced client side, by canceling the context of the statement
+	statementTimeout time.Duration
+}
+
+// New returns a Connection for a connection from a sql.DB
+func New(conn *sql.Conn) *Connection {
+	return &Connection{conn: conn}
+}
+
+// Conn returns the underlying connection, for statements which should run outside of the transaction
+func (c *Connection) Conn() *sql.Conn {
+	return c.conn
+}
+
+// Tx returns the active transaction, or nil when there is none
+func (c *Connection) Tx() *sql.Tx {
+	return c.tx
+}
+
+// Close closes the connection
+func (c *Connection) Close(_ context.Context) error {
+	return c.conn.Close()
+}
+
+// SetIsolationLevel can be used to change the isolation level on a connection
+func (c *Connection) SetIsolationLevel(ctx context.Context, isoLevel dbinterface.IsolationLevel) error {
+	qryIsoLevel := isoLevel.AsQuery()
+	logger.Info().Msgf("Set Isolation Level: %s", qryIsoLevel)
+	_, err := c.Execute(ctx, qryIsoLevel)
+	return err
+}
+
+// SetStatementTimeout sets how long a statement may run before its context is canceled. 0 means no limit.
+func (c *Connection) SetStatementTimeout(_ context.Context, timeout time.Duration) error {
+	logger.Info().Msgf("Set client side statement timeout: %v", timeout)
+	c.statementTimeout = timeout
+	return nil
+}
+
+// statementContext returns a context which is canceled after the statement timeout (if set)
+func (c *Connection) statementContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.statementTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, c.statementTimeout)
+}
+
+// Execute will execute a query with its arguments and return number of affected rows
+func (c *Connection) Execute(ctx context.Context, query string, args ...any) (int64, error) {
+	var (
+		r   sql.Result
+		err error
+	)
+	ctx, cancel := c.statementContext(ctx)
+	defer cancel()
+
+	if c.tx != nil {
+		r, err = c.tx.ExecContext(ctx, query, args...)
+	} else {
+		r, err = c.conn.ExecContext(ctx, query, args...)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return r.RowsAffected()
+}
+
+// Query will execute a query and return a list of maps where every list item is a row and every map item is a column
+func (c *Connection) Query(ctx context.Context, query string, args ...any) ([]map[string]any, error) {
+	var (
+		rows *sql.Rows
+		err  error
+	)
+	ctx, cancel := c.statementContext(ctx)
+	defer cancel()
+
+	if c.tx != nil {
+		rows, err = c.tx.QueryContext(ctx, query, args...)
+	} else {
+		rows, err = c.conn.QueryContext(ctx, query, args...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rowsToMaps(rows)
+}
+
+// QueryOneRow executes a query and expects one row, or fails. On success it returns the row.
+func (c *Connection) QueryOneRow(ctx context.Context, query string, args ...any) (map[string]any, error) {
+	rows, queryErr := c.Query(ctx, query, args...)
+	if queryErr != nil {
+		return nil, fmt.Errorf("error while executing query: %w", queryErr)
+	}
+	if len(rows) != 1 {
+		return nil, fmt.Errorf("expected 1 row, got %d", len(rows))
+	}
+	return rows[0], nil
+}
+
+// Begin starts a transaction. In this case there is a one-on-one relation between the transaction and the connection
+func (c *Connection) Begin(ctx context.Context) error {
+	if c.tx != nil {
+		return errors.New("transaction already active")
+	}
+	tx, err := c.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	c.tx = tx
+	return nil
+}
+
+// Commit will commit the connection
+func (c *Connection) Commit(_ context.Context) error {
+	if c.tx == nil {
+		return errors.New("no active transaction")
+	}
+	err := c.tx.Commit()
+	c.tx = nil
+	return err
+}
+
+// Rollback will rollback the transaction
+func (c *Connection) Rollback(_ context.Context) error {
+	if c.tx == nil {
+		return errors.New("no active transaction")
+	}
+	err := c.tx.Rollback()
+	c.tx = nil
+	return err
+}
+
+// ExecuteWithPayload executes a query with adding a payload
+func (c *Connection) ExecuteWithPayload(ctx context.Context, qry string, payload any, args ...any) (int64, error) {
+	if c.tx == nil {
+		return 0, errors.New("ExecuteWithPayload requires an active transaction; call Begin() first")
+	}
+
+	allArgs := make([]any, 0, len(args)+1)
+	allArgs = append(allArgs, args...)
+	allArgs = append(allArgs, payload)
+
+	ctx, cancel := c.statementContext(ctx)
+	defer cancel()
+	res, err := c.tx.ExecContext(ctx, qry, allArgs...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func rowsToMaps(rows *sql.Rows) ([]map[string]any, error) {
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get columns: %w", err)
+	}
+
+	var results []map[string]any
+
+	for rows.Next() {
+		values := make([]any, len(cols))
+		scanArgs := make([]any, len(cols))
+
+		for i := range values {
+			scanArgs[i] = &values[i]
+		}
+
+		if err := rows.Scan(scanArgs...); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+
+		rowMap := make(map[string]any, len(cols))
+		for i, colName := range cols {
+			key := strings.ToLower(colName) // Makes uppercase column names not break everything
+			val := values[i]
+
+			if b, ok := val.([]byte); ok {
+				rowMap[key] = string(b)
+			} else {
+				rowMap[key] = val
+			}
+		}
+		results = append(results, rowMap)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration failed: %w", err)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,101 @@
+package sqlconn_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/sqlconn"
+	_ "modernc.org/sqlite"
+)
+
+type isoLevel string
+
+func (i isoLevel) AsQuery() string { return string(i) }
+
+var _ = Describe("Connection", func() {
+	var (
+		ctx  context.Context
+		conn *sqlconn.Connection
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		db, err := sql.Open("sqlite", filepath.Join(GinkgoT().TempDir(), "test.sqlite"))
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(db.Close)
+		sqlConn, err := db.Conn(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		conn = sqlconn.New(sqlConn)
+		DeferCleanup(func() { Expect(conn.Close(ctx)).To(Succeed()) })
+
+		_, err = conn.Execute(ctx, "CREATE TABLE t (id INTEGER PRIMARY KEY, val TEXT)")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should execute statements with arguments and return rows with lower case columns", func() {
+		n, err := conn.Execute(ctx, "INSERT INTO t (id, val) VALUES (?, ?)", 1, "one")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(BeEquivalentTo(1))
+
+		row, err := conn.QueryOneRow(ctx, "SELECT id AS ID, val AS Val FROM t WHERE id = ?", 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(row).To(HaveKeyWithValue("id", BeEquivalentTo(1)))
+		Expect(row).To(HaveKeyWithValue("val", "one"))
+	})
+
+	It("should fail QueryOneRow unless there is exactly one row", func() {
+		_, err := conn.QueryOneRow(ctx, "SELECT id FROM t")
+		Expect(err).To(MatchError("expected 1 row, got 0"))
+	})
+
+	It("should bind the payload after the arguments", func() {
+		Expect(conn.Begin(ctx)).To(Succeed())
+		Expect(conn.Tx()).NotTo(BeNil())
+		_, err := conn.ExecuteWithPayload(ctx, "INSERT INTO t (id, val) VALUES (?, ?)", "one", 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(conn.Commit(ctx)).To(Succeed())
+		Expect(conn.Tx()).To(BeNil())
+
+		rows, err := conn.Query(ctx, "SELECT val FROM t")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rows).To(Equal([]map[string]any{{"val": "one"}}))
+	})
+
+	It("should require an active transaction for ExecuteWithPayload, Commit and Rollback", func() {
+		_, err := conn.ExecuteWithPayload(ctx, "INSERT INTO t (id, val) VALUES (?, ?)", "one", 1)
+		Expect(err).To(HaveOccurred())
+		Expect(conn.Commit(ctx)).NotTo(Succeed())
+		Expect(conn.Rollback(ctx)).NotTo(Succeed())
+	})
+
+	It("should roll back a transaction", func() {
+		Expect(conn.Begin(ctx)).To(Succeed())
+		Expect(conn.Begin(ctx)).NotTo(Succeed())
+		_, err := conn.Execute(ctx, "INSERT INTO t (id, val) VALUES (1, 'one')")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(conn.Rollback(ctx)).To(Succeed())
+
+		rows, err := conn.Query(ctx, "SELECT id FROM t")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rows).To(BeEmpty())
+	})
+
+	It("should run the query of the isolation level", func() {
+		Expect(conn.SetIsolationLevel(ctx, isoLevel("PRAGMA read_uncommitted = 1"))).To(Succeed())
+		row, err := conn.QueryOneRow(ctx, "PRAGMA read_uncommitted")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(row).To(HaveKeyWithValue("read_uncommitted", BeEquivalentTo(1)))
+	})
+
+	It("should cancel statements which run longer than the statement timeout", func() {
+		Expect(conn.SetStatementTimeout(ctx, 10*time.Millisecond)).To(Succeed())
+		_, err := conn.Query(ctx, `WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c)
+			SELECT COUNT(*) FROM c`)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,5 @@
+package sqlconn
+
+import "github.com/pgvillage-tools/dbtwool/pkg/logging"
+
+var logger = logging.Logger()
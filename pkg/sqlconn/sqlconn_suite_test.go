@@ -0,0 +1,13 @@
+package sqlconn_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestSqlconn(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Sqlconn Suite")
+}
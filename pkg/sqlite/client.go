@@ -0,0 +1,90 @@
+// Package sqlite holds all code to use an embedded SQLite database, which allows running the tests without a
+// database server
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+	sqlitedriver "modernc.org/sqlite"
+)
+
+const sqliteTestQuery = "SELECT 1"
+
+// Client is the main object to connect to SQLite
+type Client struct {
+	ConnectParams ConnParams
+	PoolSettings  dbinterface.PoolSettings
+	pool          *Pool
+}
+
+// NewClient returns a new Client
+func NewClient(connectionParams ConnParams) Client {
+	return Client{
+		ConnectParams: connectionParams,
+	}
+}
+
+// Pool will open the database file and return a new SQLite pool
+func (cl *Client) Pool(ctx context.Context) (dbinterface.Pool, error) {
+	if cl.pool != nil {
+		return cl.pool, nil
+	}
+
+	pool := sql.OpenDB(newConnector(cl.ConnectParams))
+	cl.PoolSettings.ConfigureDB(pool)
+	if err := pool.PingContext(ctx); err != nil {
+		_ = pool.Close()
+		return nil, err
+	} else if _, err := pool.ExecContext(ctx, sqliteTestQuery); err != nil {
+		_ = pool.Close()
+		return nil, err
+	}
+	cl.pool = &Pool{pool: pool}
+	return cl.pool, nil
+}
+
+// EnsurePoolSize implements dbinterface.PoolSizer. database/sql pools are unlimited unless maxConns is set.
+func (cl *Client) EnsurePoolSize(conns int) error {
+	return cl.PoolSettings.CheckSize(conns)
+}
+
+// connector opens new sqlite connections, and attaches the schema database files to every one of them
+type connector struct {
+	dsn    string
+	driver *sqlitedriver.Driver
+}
+
+func newConnector(params ConnParams) connector {
+	schemas := params.attachedSchemas()
+	sqliteDriver := &sqlitedriver.Driver{}
+	sqliteDriver.RegisterConnectionHook(func(conn sqlitedriver.ExecQuerierContext, _ string) error {
+		for _, schema := range schemas {
+			qry := fmt.Sprintf("ATTACH DATABASE ? AS %s", quoteIdentifier(schema))
+			path := []driver.NamedValue{{Ordinal: 1, Value: params.SchemaPath(schema)}}
+			if _, err := conn.ExecContext(context.Background(), qry, path); err != nil {
+				return fmt.Errorf("failed to attach schema %s: %w", schema, err)
+			}
+		}
+		return nil
+	})
+	return connector{dsn: params.GetConnString(), driver: sqliteDriver}
+}
+
+// Connect implements driver.Connector
+func (c connector) Connect(_ context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+// Driver implements driver.Connector
+func (c connector) Driver() driver.Driver {
+	return c.driver
+}
+
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
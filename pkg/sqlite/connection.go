@@ -0,0 +1,23 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/sqlconn"
+)
+
+// Connection is a database/sql connection (see package sqlconn) with the SQLite specific methods
+type Connection struct {
+	*sqlconn.Connection
+}
+
+// SetLockTimeout sets how long a statement waits for a locked database before it fails with SQLITE_BUSY.
+// SQLite locks the whole database file, so this is the busy timeout.
+func (c *Connection) SetLockTimeout(ctx context.Context, timeout time.Duration) error {
+	qry := fmt.Sprintf("PRAGMA busy_timeout = %d", timeout.Milliseconds())
+	logger.Info().Msgf("Set timeout: %s", qry)
+	_, err := c.Execute(ctx, qry)
+	return err
+}
@@ -0,0 +1,98 @@
+package sqlite_test
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+	"github.com/pgvillage-tools/dbtwool/pkg/sqlite"
+)
+
+var _ = Describe("Connection", func() {
+	var (
+		ctx  context.Context
+		pool dbinterface.Pool
+		conn dbinterface.Connection
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		client := sqlite.NewClient(sqlite.ConnParams{
+			Path:        filepath.Join(GinkgoT().TempDir(), "test.sqlite"),
+			Schemas:     []string{"bench"},
+			BusyTimeout: 1000,
+		})
+		var err error
+		pool, err = client.Pool(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		conn, err = pool.Connect(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(func() { Expect(conn.Close(ctx)).To(Succeed()) })
+
+		_, err = conn.Execute(ctx, "CREATE TABLE bench.t (id INTEGER PRIMARY KEY, val TEXT)")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should insert and query rows in an attached schema", func() {
+		Expect(conn.Begin(ctx)).To(Succeed())
+		n, err := conn.ExecuteWithPayload(ctx, "INSERT INTO bench.t (id, val) VALUES (?, ?)", "one", 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(BeEquivalentTo(1))
+		Expect(conn.Commit(ctx)).To(Succeed())
+
+		row, err := conn.QueryOneRow(ctx, "SELECT id, val FROM bench.t WHERE id = ?", 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(row).To(HaveKeyWithValue("id", BeEquivalentTo(1)))
+		Expect(row).To(HaveKeyWithValue("val", "one"))
+	})
+
+	It("should roll back a transaction", func() {
+		Expect(conn.Begin(ctx)).To(Succeed())
+		_, err := conn.Execute(ctx, "INSERT INTO bench.t (id, val) VALUES (1, 'one')")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(conn.Rollback(ctx)).To(Succeed())
+
+		rows, err := conn.Query(ctx, "SELECT id FROM bench.t")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rows).To(BeEmpty())
+	})
+
+	It("should require an active transaction for ExecuteWithPayload", func() {
+		_, err := conn.ExecuteWithPayload(ctx, "INSERT INTO bench.t (id, val) VALUES (?, ?)", "one", 1)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should set the isolation level", func() {
+		Expect(conn.SetIsolationLevel(ctx, sqlite.ReadUncommitted)).To(Succeed())
+		row, err := conn.QueryOneRow(ctx, "PRAGMA read_uncommitted")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(row).To(HaveKeyWithValue("read_uncommitted", BeEquivalentTo(1)))
+	})
+
+	It("should classify a busy database as a lock timeout", func() {
+		writer, err := pool.Connect(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		defer writer.Close(ctx)
+		Expect(writer.Begin(ctx)).To(Succeed())
+		_, err = writer.Execute(ctx, "INSERT INTO bench.t (id, val) VALUES (1, 'one')")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(conn.SetLockTimeout(ctx, 10*time.Millisecond)).To(Succeed())
+		_, err = conn.Execute(ctx, "INSERT INTO bench.t (id, val) VALUES (2, 'two')")
+		Expect(err).To(HaveOccurred())
+		Expect(dbinterface.ClassifyError(conn, err)).To(Equal(dbinterface.ErrorClassLockTimeout))
+		Expect(writer.Rollback(ctx)).To(Succeed())
+	})
+
+	It("should classify a canceled statement as a statement timeout", func() {
+		Expect(conn.SetStatementTimeout(ctx, 10*time.Millisecond)).To(Succeed())
+		_, err := conn.Query(ctx, `WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c)
+			SELECT COUNT(*) FROM c`)
+		Expect(err).To(HaveOccurred())
+		Expect(dbinterface.ClassifyError(conn, err)).To(Equal(dbinterface.ErrorClassStatementTimeout))
+	})
+})
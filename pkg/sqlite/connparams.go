@@ -0,0 +1,68 @@
+package sqlite
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/utils"
+)
+
+// ConnParams objects define connection parameters for a SQLite database file.
+// SQLite has no schemas, so every schema is attached as a separate database file next to Path.
+type ConnParams struct {
+	Path        string
+	Schemas     []string
+	BusyTimeout int // milliseconds
+}
+
+// GetConnString builds and returns a string that can be used to open the database with the sqlite driver.
+// WAL mode is used so that readers and a writer do not block each other.
+func (cp ConnParams) GetConnString() string {
+	return fmt.Sprintf("file:%s?_pragma=busy_timeout(%d)&_pragma=journal_mode(WAL)", cp.Path, cp.BusyTimeout)
+}
+
+// SchemaPath returns the path of the database file which is attached for a schema
+func (cp ConnParams) SchemaPath(schema string) string {
+	ext := filepath.Ext(cp.Path)
+	return fmt.Sprintf("%s_%s%s", strings.TrimSuffix(cp.Path, ext), schema, ext)
+}
+
+// attachedSchemas returns the schemas which need to be attached. main and temp always exist.
+func (cp ConnParams) attachedSchemas() []string {
+	var schemas []string
+	for _, schema := range cp.Schemas {
+		switch strings.ToLower(schema) {
+		case "", "main", "temp":
+			continue
+		}
+		schemas = append(schemas, schema)
+	}
+	return schemas
+}
+
+// ConnParamsFromEnv generates a new default ConnParams from env variables with defaults
+func ConnParamsFromEnv() ConnParams {
+	return ConnParamsFromEnvWithDefaults(ConnParams{})
+}
+
+// ConnParamsFromEnvWithDefaults generates a new ConnParams from env variables. Fields which are not set in the
+// environment are taken from defaults (e.a. a connection profile), and from the built-in defaults when empty there.
+func ConnParamsFromEnvWithDefaults(defaults ConnParams) ConnParams {
+	if defaults.Path == "" {
+		defaults.Path = defaultDatabase
+	}
+	if defaults.BusyTimeout <= 0 {
+		defaults.BusyTimeout = defaultBusyTimeout
+	}
+	busyTimeout, err := strconv.Atoi(utils.GetEnv("SQLITE_BUSY_TIMEOUT", strconv.Itoa(defaults.BusyTimeout)))
+	if err != nil {
+		busyTimeout = defaults.BusyTimeout
+	}
+	return ConnParams{
+		Path:        utils.GetEnv("SQLITE_DATABASE", defaults.Path),
+		Schemas:     defaults.Schemas,
+		BusyTimeout: busyTimeout,
+	}
+}
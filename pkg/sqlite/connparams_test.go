@@ -0,0 +1,63 @@
+package sqlite_test
+
+import (
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/sqlite"
+)
+
+var _ = Describe("ConnParams", func() {
+	Describe("GetConnString", func() {
+		It("should return a dsn with busy timeout and WAL mode", func() {
+			params := sqlite.ConnParams{Path: "/tmp/test.sqlite", BusyTimeout: 1000}
+			Expect(params.GetConnString()).To(
+				Equal("file:/tmp/test.sqlite?_pragma=busy_timeout(1000)&_pragma=journal_mode(WAL)"))
+		})
+	})
+
+	Describe("SchemaPath", func() {
+		It("should put the schema file next to the database file", func() {
+			params := sqlite.ConnParams{Path: "/tmp/test.sqlite"}
+			Expect(params.SchemaPath("lobs")).To(Equal("/tmp/test_lobs.sqlite"))
+		})
+		It("should work without an extension", func() {
+			params := sqlite.ConnParams{Path: "/tmp/test"}
+			Expect(params.SchemaPath("lobs")).To(Equal("/tmp/test_lobs"))
+		})
+	})
+
+	Describe("ConnParamsFromEnv", func() {
+		var originalEnv map[string]string
+
+		BeforeEach(func() {
+			originalEnv = make(map[string]string)
+			for _, v := range []string{"SQLITE_DATABASE", "SQLITE_BUSY_TIMEOUT"} {
+				originalEnv[v] = os.Getenv(v)
+				os.Unsetenv(v)
+			}
+		})
+
+		AfterEach(func() {
+			for k, v := range originalEnv {
+				os.Setenv(k, v)
+			}
+		})
+
+		It("should return defaults when env variables are not set", func() {
+			params := sqlite.ConnParamsFromEnv()
+			Expect(params.Path).To(Equal("dbtwool.sqlite"))
+			Expect(params.BusyTimeout).To(Equal(5000))
+		})
+
+		It("should use env variables when set", func() {
+			os.Setenv("SQLITE_DATABASE", "/data/bench.db")
+			os.Setenv("SQLITE_BUSY_TIMEOUT", "250")
+			params := sqlite.ConnParamsFromEnv()
+			Expect(params.Path).To(Equal("/data/bench.db"))
+			Expect(params.BusyTimeout).To(Equal(250))
+		})
+	})
+})
@@ -0,0 +1,48 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+	sqlitedriver "modernc.org/sqlite"
+	sqlitelib "modernc.org/sqlite/lib"
+)
+
+const primaryCodeMask = 0xff
+
+// ClassifyError returns the class of a SQLite error, based on its (extended) result code
+func ClassifyError(err error) dbinterface.ErrorClass {
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) {
+		return dbinterface.ErrorClassConnectionLost
+	}
+	// the statement timeout is enforced client side
+	if errors.Is(err, context.DeadlineExceeded) {
+		return dbinterface.ErrorClassStatementTimeout
+	}
+	var sqliteErr *sqlitedriver.Error
+	if !errors.As(err, &sqliteErr) {
+		return dbinterface.ErrorClassOther
+	}
+	return classifyCode(sqliteErr.Code())
+}
+
+// classifyCode returns the class of an extended result code, of which the lowest byte is the primary result code
+func classifyCode(code int) dbinterface.ErrorClass {
+	switch {
+	// a read transaction in WAL mode can not be upgraded when another connection committed in the mean time
+	case code == sqlitelib.SQLITE_BUSY_SNAPSHOT:
+		return dbinterface.ErrorClassSerialization
+	// the busy timeout expired while waiting for the database (or table) lock
+	case code&primaryCodeMask == sqlitelib.SQLITE_BUSY, code&primaryCodeMask == sqlitelib.SQLITE_LOCKED:
+		return dbinterface.ErrorClassLockTimeout
+	}
+	return dbinterface.ErrorClassOther
+}
+
+// ClassifyError implements dbinterface.ErrorClassifier
+func (c *Connection) ClassifyError(err error) dbinterface.ErrorClass {
+	return ClassifyError(err)
+}
@@ -0,0 +1,54 @@
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	sqlitelib "modernc.org/sqlite/lib"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+	"github.com/pgvillage-tools/dbtwool/pkg/sqlite"
+)
+
+var _ = Describe("ClassifyError", func() {
+	DescribeTable("should classify errors by result code",
+		func(code int, expected dbinterface.ErrorClass) {
+			Expect(sqlite.ClassifyCode(code)).To(Equal(expected))
+		},
+		Entry("busy", sqlitelib.SQLITE_BUSY, dbinterface.ErrorClassLockTimeout),
+		Entry("locked", sqlitelib.SQLITE_LOCKED, dbinterface.ErrorClassLockTimeout),
+		Entry("locked shared cache", sqlitelib.SQLITE_LOCKED_SHAREDCACHE, dbinterface.ErrorClassLockTimeout),
+		Entry("busy snapshot", sqlitelib.SQLITE_BUSY_SNAPSHOT, dbinterface.ErrorClassSerialization),
+		Entry("constraint", sqlitelib.SQLITE_CONSTRAINT, dbinterface.ErrorClassOther),
+	)
+	DescribeTable("should classify other errors",
+		func(err error, expected dbinterface.ErrorClass) {
+			Expect(sqlite.ClassifyError(fmt.Errorf("oltp execute failed: %w", err))).To(Equal(expected))
+		},
+		Entry("deadline exceeded", context.DeadlineExceeded, dbinterface.ErrorClassStatementTimeout),
+		Entry("bad connection", driver.ErrBadConn, dbinterface.ErrorClassConnectionLost),
+		Entry("anything else", errors.New("boom"), dbinterface.ErrorClassOther),
+	)
+	It("should classify the busy errors returned by the driver", func(ctx SpecContext) {
+		dsn := fmt.Sprintf("file:%s/busy.sqlite?_pragma=busy_timeout(0)", GinkgoT().TempDir())
+		db, err := sql.Open("sqlite", dsn)
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { Expect(db.Close()).To(Succeed()) }()
+		_, err = db.ExecContext(ctx, "create table t (i int)")
+		Expect(err).NotTo(HaveOccurred())
+		locker, err := db.Conn(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		defer func() { Expect(locker.Close()).To(Succeed()) }()
+		_, err = locker.ExecContext(ctx, "begin immediate")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = db.ExecContext(ctx, "insert into t values (1)")
+		Expect(sqlite.ClassifyError(err)).To(Equal(dbinterface.ErrorClassLockTimeout))
+		_, err = locker.ExecContext(ctx, "rollback")
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
@@ -0,0 +1,4 @@
+package sqlite
+
+// ClassifyCode exposes classifyCode to the tests
+var ClassifyCode = classifyCode
@@ -0,0 +1,69 @@
+package sqlite
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// IsolationLevel is used to get rdbms specific queries for basic functions.
+// SQLite transactions are always serializable. Uncommitted reads are only possible with a shared cache, which
+// means that in WAL mode readers just keep reading the last committed snapshot.
+type IsolationLevel int
+
+const (
+	// ReadUncommitted allows reading uncommitted changes of other connections that share the same cache
+	ReadUncommitted IsolationLevel = iota
+	// Serializable is the default (and only other) isolation level of SQLite
+	Serializable
+)
+
+var (
+	levelToString = map[IsolationLevel]string{
+		ReadUncommitted: "READ UNCOMMITTED",
+		Serializable:    "SERIALIZABLE",
+	}
+	// nameToLevel maps DB2 and ANSI names to the SQLite level. All levels above read uncommitted map to
+	// serializable, as that is what SQLite offers.
+	nameToLevel = map[string]IsolationLevel{
+		"UR":               ReadUncommitted,
+		"UNCOMMITTED READ": ReadUncommitted,
+		"READ UNCOMMITTED": ReadUncommitted,
+		"CS":               Serializable,
+		"CURSOR STABILITY": Serializable,
+		"RS":               Serializable,
+		"READ STABILITY":   Serializable,
+		"RR":               Serializable,
+		"READ COMMITTED":   Serializable,
+		"REPEATABLE READ":  Serializable,
+		"SERIALIZABLE":     Serializable,
+	}
+)
+
+// AsQuery can be used to return a query for the isolation level
+func (i IsolationLevel) AsQuery() string {
+	value := 0
+	if i == ReadUncommitted {
+		value = 1
+	}
+	return fmt.Sprintf("PRAGMA read_uncommitted = %d", value)
+}
+
+// AsString can be used to return a string version of the isolation level
+func (i IsolationLevel) AsString() string {
+	return levelToString[i]
+}
+
+// ParseIsolationLevel returns the isolation level for a name (e.g. "UR", "read committed").
+// Invalid values return an error.
+func ParseIsolationLevel(value string) (IsolationLevel, error) {
+	// an index means a different level on every RDBMS (e.g. 1 is REPEATABLE READ on PostgreSQL and CS on DB2)
+	if _, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+		return Serializable, fmt.Errorf("isolation level %q is an index, use a name like 'read committed' instead", value)
+	}
+	name := strings.Join(strings.Fields(strings.ToUpper(strings.NewReplacer("_", " ", "-", " ").Replace(value))), " ")
+	if level, exists := nameToLevel[name]; exists {
+		return level, nil
+	}
+	return Serializable, fmt.Errorf("invalid isolation level %q", value)
+}
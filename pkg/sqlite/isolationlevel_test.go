@@ -0,0 +1,41 @@
+package sqlite_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/sqlite"
+)
+
+var _ = Describe("IsolationLevel", func() {
+	It("should return the correct query", func() {
+		Expect(sqlite.ReadUncommitted.AsQuery()).To(Equal("PRAGMA read_uncommitted = 1"))
+		Expect(sqlite.Serializable.AsQuery()).To(Equal("PRAGMA read_uncommitted = 0"))
+	})
+
+	DescribeTable("ParseIsolationLevel should map valid values",
+		func(value string, expected sqlite.IsolationLevel) {
+			level, err := sqlite.ParseIsolationLevel(value)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(level).To(Equal(expected))
+		},
+		Entry("UR", "UR", sqlite.ReadUncommitted),
+		Entry("read uncommitted", "read_uncommitted", sqlite.ReadUncommitted),
+		Entry("CS", "cs", sqlite.Serializable),
+		Entry("read committed", "read committed", sqlite.Serializable),
+		Entry("serializable", "SERIALIZABLE", sqlite.Serializable),
+	)
+
+	DescribeTable("ParseIsolationLevel should reject invalid values",
+		func(value string) {
+			_, err := sqlite.ParseIsolationLevel(value)
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("empty", ""),
+		Entry("unknown name", "snapshot"),
+		Entry("index 0", "0"),
+		Entry("index 1", "1"),
+		Entry("index out of range", "2"),
+		Entry("negative index", "-1"),
+	)
+})
@@ -0,0 +1,90 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+)
+
+// InsertLOBRowsBulk inserts large objects with one prepared statement in a single transaction,
+// which is the fastest way to load data into SQLite
+func (c *Connection) InsertLOBRowsBulk(
+	ctx context.Context,
+	schema,
+	table string,
+	rows []dbinterface.LobRow) (int64, int64, error) {
+	if len(rows) == 0 {
+		return 0, 0, nil
+	}
+
+	totalBytes, err := calculateTotalBytes(rows)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if err := c.Begin(ctx); err != nil {
+		return 0, 0, err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			_ = c.Rollback(ctx)
+		}
+	}()
+
+	stmt, err := c.Tx().PrepareContext(ctx, fmt.Sprintf(
+		"INSERT INTO %s.%s (tenant_id, doc_type, payload_bin, payload_text) VALUES (?, ?, ?, ?)",
+		quoteIdentifier(schema), quoteIdentifier(table)))
+	if err != nil {
+		return 0, 0, fmt.Errorf("prepare failed: %w", err)
+	}
+	defer stmt.Close()
+
+	var inserted int64
+	for i, r := range rows {
+		var bin, txt any
+		switch r.LobType {
+		case "blob", "bytea":
+			bin = r.Payload
+		case "clob", "text":
+			txt = r.Payload
+		default:
+			return 0, 0, fmt.Errorf("unsupported lobType %q", r.LobType)
+		}
+		res, err := stmt.ExecContext(ctx, r.TenantID, r.DocType, bin, txt)
+		if err != nil {
+			return 0, 0, fmt.Errorf("insert failed at row %d: %w", i, err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return 0, 0, err
+		}
+		inserted += n
+	}
+
+	if err := c.Commit(ctx); err != nil {
+		return 0, 0, err
+	}
+	committed = true
+
+	return inserted, totalBytes, nil
+}
+
+func calculateTotalBytes(rows []dbinterface.LobRow) (int64, error) {
+	var totalBytes int64
+
+	for _, r := range rows {
+		switch v := r.Payload.(type) {
+		case []byte:
+			totalBytes += int64(len(v))
+		case string:
+			totalBytes += int64(len(v))
+		case nil:
+		default:
+			return 0, fmt.Errorf("unexpected payload type %T for lobType=%q", r.Payload, r.LobType)
+		}
+	}
+
+	return totalBytes, nil
+}
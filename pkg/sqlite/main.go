@@ -0,0 +1,10 @@
+package sqlite
+
+import "github.com/pgvillage-tools/dbtwool/pkg/logging"
+
+var logger = logging.Logger()
+
+const (
+	defaultDatabase    = "dbtwool.sqlite"
+	defaultBusyTimeout = 5000 // milliseconds
+)
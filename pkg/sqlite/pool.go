@@ -0,0 +1,23 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+	"github.com/pgvillage-tools/dbtwool/pkg/sqlconn"
+)
+
+// Pool is a wrapper around sql.DB, so we can add methods on top of it
+type Pool struct {
+	pool *sql.DB
+}
+
+// Connect will create and return a new connection in the pool
+func (p Pool) Connect(ctx context.Context) (dbinterface.Connection, error) {
+	conn, err := p.pool.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Connection{Connection: sqlconn.New(conn)}, nil
+}
@@ -0,0 +1,56 @@
+package sqlite_test
+
+import (
+	"context"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbclient"
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+	"github.com/pgvillage-tools/dbtwool/pkg/lobperformance"
+	"github.com/pgvillage-tools/dbtwool/pkg/ruperformance"
+	"github.com/pgvillage-tools/dbtwool/pkg/sqlite"
+	"github.com/pgvillage-tools/dbtwool/pkg/testrunner"
+)
+
+// These specs run stage, gen and test of the performance tests end-to-end, without a database server
+var _ = Describe("Performance tests on SQLite", func() {
+	const schema = "dbtwooltests"
+
+	var (
+		ctx    context.Context
+		client sqlite.Client
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		client = sqlite.NewClient(sqlite.ConnParams{
+			Path:        filepath.Join(GinkgoT().TempDir(), "test.sqlite"),
+			Schemas:     []string{schema},
+			BusyTimeout: 1000,
+		})
+	})
+
+	It("should run the lob-performance test", func() {
+		Expect(lobperformance.Stage(ctx, dbclient.SQLite, &client, schema, "lobtable")).To(Succeed())
+		Expect(lobperformance.GenerateBulk(ctx, dbclient.SQLite, &client, schema, "lobtable",
+			[]string{"100%:8b"}, 0, "1kb", 50, "blob")).To(Succeed())
+
+		Expect(lobperformance.ExecuteTest(ctx, dbclient.SQLite, &client, schema, "lobtable",
+			"1", 2, 1, 1, "scattered", "blob", dbinterface.SessionSettings{},
+			testrunner.ReconnectPolicy{}, nil)).To(Succeed())
+	})
+
+	It("should run the ru-performance test", func() {
+		Expect(ruperformance.Stage(ctx, dbclient.SQLite, &client, schema, "rutable")).To(Succeed())
+		Expect(ruperformance.Generate(ctx, dbclient.SQLite, &client, schema, "rutable", 1000)).To(Succeed())
+
+		policy, err := testrunner.NewRetryPolicy(3, []string{"lock_timeout", "serialization"}, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ruperformance.ExecuteTest(ctx, dbclient.SQLite, &client, schema, "rutable", 1, 1, 1, 1,
+			sqlite.ReadUncommitted, policy, dbinterface.SessionSettings{}, testrunner.ReconnectPolicy{}, nil)).
+			To(Succeed())
+	})
+})
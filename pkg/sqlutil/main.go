@@ -0,0 +1,36 @@
+// Package sqlutil holds the helpers which the environment and servermetrics packages share, to build their queries
+// and to turn the rows they return into named groups of values.
+package sqlutil
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Quote returns s as an SQL string literal
+func Quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// GroupName returns the name of the group of a row: name, followed by the values of the keys columns
+// (e.g. bufferpools:IBMDEFAULTBP). Without keys, all rows belong to the group name.
+func GroupName(name string, keys []string, row map[string]any) string {
+	if len(keys) == 0 {
+		return name
+	}
+	values := make([]string, 0, len(keys))
+	for _, key := range keys {
+		values = append(values, strings.TrimSpace(fmt.Sprint(row[key])))
+	}
+	return name + ":" + strings.Join(values, ".")
+}
+
+// IsKey returns true if column is one of the keys columns
+func IsKey(keys []string, column string) bool {
+	for _, key := range keys {
+		if strings.EqualFold(key, column) {
+			return true
+		}
+	}
+	return false
+}
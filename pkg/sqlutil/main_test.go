@@ -0,0 +1,27 @@
+package sqlutil_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/sqlutil"
+)
+
+var _ = Describe("sqlutil", func() {
+	It("should quote string literals", func() {
+		Expect(sqlutil.Quote("it's")).To(Equal("'it''s'"))
+	})
+	DescribeTable("should name the group of a row",
+		func(keys []string, expected string) {
+			row := map[string]any{"bpname": "IBMDEFAULTBP ", "member": int64(0), "pages": int64(1000)}
+			Expect(sqlutil.GroupName("bufferpools", keys, row)).To(Equal(expected))
+		},
+		Entry("without keys", nil, "bufferpools"),
+		Entry("with one key", []string{"bpname"}, "bufferpools:IBMDEFAULTBP"),
+		Entry("with more keys", []string{"bpname", "member"}, "bufferpools:IBMDEFAULTBP.0"),
+	)
+	It("should recognize key columns regardless of their case", func() {
+		Expect(sqlutil.IsKey([]string{"BPNAME"}, "bpname")).To(BeTrue())
+		Expect(sqlutil.IsKey([]string{"BPNAME"}, "pages")).To(BeFalse())
+	})
+})
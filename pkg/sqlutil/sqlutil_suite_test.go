@@ -0,0 +1,13 @@
+package sqlutil_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestSQLUtil(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "SQLUtil Suite")
+}
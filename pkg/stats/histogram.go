@@ -0,0 +1,178 @@
+// Package stats holds lock-free counters and latency histograms which workers can update while a test is running
+package stats
+
+import (
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// Every power of two is split into subBucketCount linear buckets, which keeps the relative error below 12.5%.
+	subBucketBits  = 3
+	subBucketCount = 1 << subBucketBits
+	bucketCount    = (bits.UintSize - subBucketBits + 1) * subBucketCount
+
+	percent       = 100.0
+	nanosPerMilli = float64(time.Millisecond)
+)
+
+// Histogram records latencies in logarithmic buckets. It is safe for concurrent use.
+type Histogram struct {
+	buckets [bucketCount]atomic.Int64
+	count   atomic.Int64
+	sum     atomic.Int64
+	max     atomic.Int64
+}
+
+// Record adds a duration to the histogram
+func (h *Histogram) Record(d time.Duration) {
+	ns := max(int64(d), 0)
+	h.buckets[bucketIndex(uint64(ns))].Add(1)
+	h.count.Add(1)
+	h.sum.Add(ns)
+	for {
+		current := h.max.Load()
+		if ns <= current || h.max.CompareAndSwap(current, ns) {
+			return
+		}
+	}
+}
+
+// Count returns the number of recorded durations
+func (h *Histogram) Count() int64 {
+	return h.count.Load()
+}
+
+// Snapshot returns a point in time copy of the histogram
+func (h *Histogram) Snapshot() Snapshot {
+	s := Snapshot{
+		Buckets: make([]int64, bucketCount),
+		Count:   h.count.Load(),
+		Sum:     h.sum.Load(),
+		Max:     h.max.Load(),
+	}
+	for i := range h.buckets {
+		s.Buckets[i] = h.buckets[i].Load()
+	}
+	return s
+}
+
+// Snapshot is a point in time copy of a Histogram
+type Snapshot struct {
+	Buckets []int64
+	Count   int64
+	Sum     int64
+	Max     int64
+}
+
+// Percentile returns an estimate of the p-th percentile (0-100)
+func (s Snapshot) Percentile(p float64) time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	rank := int64(p / percent * float64(s.Count))
+	if rank < 1 {
+		rank = 1
+	}
+	var seen int64
+	for i, n := range s.Buckets {
+		seen += n
+		if seen >= rank {
+			low, width := bucketBounds(i)
+			return time.Duration(min(int64(low+width/2), s.Max))
+		}
+	}
+	return time.Duration(s.Max)
+}
+
+// Sub returns the durations which were recorded between an earlier snapshot and this one. The maximum of those is
+// not known, so it is estimated as the upper bound of the highest bucket which holds any.
+func (s Snapshot) Sub(earlier Snapshot) Snapshot {
+	delta := Snapshot{
+		Buckets: make([]int64, len(s.Buckets)),
+		Count:   s.Count - earlier.Count,
+		Sum:     s.Sum - earlier.Sum,
+	}
+	for i := range s.Buckets {
+		if i < len(earlier.Buckets) {
+			delta.Buckets[i] = s.Buckets[i] - earlier.Buckets[i]
+		} else {
+			delta.Buckets[i] = s.Buckets[i]
+		}
+		if delta.Buckets[i] > 0 {
+			low, width := bucketBounds(i)
+			delta.Max = min(int64(low+width-1), s.Max)
+		}
+	}
+	return delta
+}
+
+// CountAtMost returns the number of durations up to d. Durations are counted per bucket, so a bucket which
+// holds d is only counted when all of it is up to d.
+func (s Snapshot) CountAtMost(d time.Duration) int64 {
+	var count int64
+	for i, n := range s.Buckets {
+		low, width := bucketBounds(i)
+		if low+width-1 > uint64(max(int64(d), 0)) {
+			break
+		}
+		count += n
+	}
+	return count
+}
+
+// Mean returns the average of all recorded durations
+func (s Snapshot) Mean() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return time.Duration(s.Sum / s.Count)
+}
+
+// Summary reduces the snapshot to the numbers we report
+func (s Snapshot) Summary() LatencySummary {
+	const (
+		p50 = 50
+		p95 = 95
+		p99 = 99
+	)
+	return LatencySummary{
+		MeanMs: toMillis(s.Mean()),
+		P50Ms:  toMillis(s.Percentile(p50)),
+		P95Ms:  toMillis(s.Percentile(p95)),
+		P99Ms:  toMillis(s.Percentile(p99)),
+		MaxMs:  toMillis(time.Duration(s.Max)),
+	}
+}
+
+// LatencySummary holds the most important latency figures in milliseconds
+type LatencySummary struct {
+	MeanMs float64 `json:"mean_ms"`
+	P50Ms  float64 `json:"p50_ms"`
+	P95Ms  float64 `json:"p95_ms"`
+	P99Ms  float64 `json:"p99_ms"`
+	MaxMs  float64 `json:"max_ms"`
+}
+
+func toMillis(d time.Duration) float64 {
+	return float64(d) / nanosPerMilli
+}
+
+func bucketIndex(v uint64) int {
+	if v < subBucketCount {
+		return int(v)
+	}
+	shift := bits.Len64(v) - 1 - subBucketBits
+	sub := (v >> shift) & (subBucketCount - 1)
+	return (shift+1)*subBucketCount + int(sub)
+}
+
+func bucketBounds(i int) (low uint64, width uint64) {
+	if i < subBucketCount {
+		return uint64(i), 1
+	}
+	shift := i/subBucketCount - 1
+	sub := uint64(i % subBucketCount)
+	return (subBucketCount + sub) << shift, 1 << shift
+}
@@ -0,0 +1,103 @@
+package stats
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Histogram", func() {
+	Context("bucketIndex", func() {
+		It("should map every value into the bucket that contains it", func() {
+			for _, v := range []uint64{0, 1, 7, 8, 9, 15, 16, 17, 1000, 123456789, 1 << 40} {
+				low, width := bucketBounds(bucketIndex(v))
+				Ω(v).To(BeNumerically(">=", low))
+				Ω(v).To(BeNumerically("<", low+width))
+			}
+		})
+	})
+	Context("Snapshot", func() {
+		It("should be empty for a new histogram", func() {
+			var h Histogram
+			s := h.Snapshot()
+			Ω(s.Count).To(BeZero())
+			Ω(s.Mean()).To(BeZero())
+			Ω(s.Percentile(99)).To(BeZero())
+		})
+		It("should estimate percentiles within the bucket error", func() {
+			var h Histogram
+			for i := 1; i <= 1000; i++ {
+				h.Record(time.Duration(i) * time.Millisecond)
+			}
+			s := h.Snapshot()
+			Ω(s.Count).To(BeEquivalentTo(1000))
+			Ω(s.Max).To(BeEquivalentTo(time.Second))
+			Ω(s.Mean()).To(BeNumerically("~", 500*time.Millisecond, time.Millisecond))
+			Ω(s.Percentile(50)).To(BeNumerically("~", 500*time.Millisecond, 63*time.Millisecond))
+			Ω(s.Percentile(99)).To(BeNumerically("~", 990*time.Millisecond, 124*time.Millisecond))
+			Ω(s.Percentile(100)).To(BeNumerically("<=", time.Second))
+		})
+		It("should count the durations up to a bound within the bucket error", func() {
+			var h Histogram
+			for i := 1; i <= 1000; i++ {
+				h.Record(time.Duration(i) * time.Millisecond)
+			}
+			s := h.Snapshot()
+			Ω(s.CountAtMost(0)).To(BeZero())
+			Ω(s.CountAtMost(100 * time.Millisecond)).To(BeNumerically("~", 100, 13))
+			Ω(s.CountAtMost(time.Minute)).To(BeEquivalentTo(1000))
+		})
+		It("should treat negative durations as zero", func() {
+			var h Histogram
+			h.Record(-time.Second)
+			Ω(h.Snapshot().Max).To(BeZero())
+		})
+	})
+})
+
+var _ = Describe("Operation", func() {
+	It("should summarize counts, errors and throughput", func() {
+		o := NewOperation("select")
+		for range 10 {
+			o.Observe(2 * time.Millisecond)
+		}
+		o.Fail()
+		summary := o.Summarize(2 * time.Second)
+		Ω(summary.Name).To(Equal("select"))
+		Ω(summary.Count).To(BeEquivalentTo(10))
+		Ω(summary.Errors).To(BeEquivalentTo(1))
+		Ω(summary.PerSecond).To(BeNumerically("~", 5.0))
+		Ω(summary.Latency.MaxMs).To(BeNumerically("~", 2.0))
+	})
+	It("should count errors per class and retries", func() {
+		o := NewOperation("update")
+		o.FailWith("deadlock")
+		o.FailWith("deadlock")
+		o.FailWith("lock_timeout")
+		o.Retry()
+		summary := o.Summarize(time.Second)
+		Ω(summary.Errors).To(BeEquivalentTo(3))
+		Ω(summary.Retries).To(BeEquivalentTo(1))
+		Ω(summary.ErrorsByClass).To(Equal(map[string]int64{"deadlock": 2, "lock_timeout": 1}))
+	})
+	It("should summarize the executions since an earlier snapshot", func() {
+		o := NewOperation("read")
+		o.Observe(time.Second)
+		o.FailWith("deadlock")
+		earlier := o.Snapshot()
+		for range 4 {
+			o.Observe(2 * time.Millisecond)
+		}
+		o.FailWith("deadlock")
+		o.FailWith("lock_timeout")
+		summary := o.Snapshot().Since(earlier, 2*time.Second)
+		Ω(summary.Count).To(BeEquivalentTo(4))
+		Ω(summary.Errors).To(BeEquivalentTo(2))
+		Ω(summary.ErrorsByClass).To(Equal(map[string]int64{"deadlock": 1, "lock_timeout": 1}))
+		Ω(summary.PerSecond).To(BeNumerically("~", 2.0))
+		// the slow execution before the earlier snapshot does not count
+		Ω(summary.Latency.MaxMs).To(BeNumerically("~", 2.0, 0.25))
+		Ω(summary.Latency.P99Ms).To(BeNumerically("~", 2.0, 0.25))
+	})
+})
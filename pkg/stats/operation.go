@@ -0,0 +1,161 @@
+package stats
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Operation counts executions, failures and latencies of one kind of database operation
+// (a statement, a transaction, a worker role, etc.)
+type Operation struct {
+	name    string
+	errors  atomic.Int64
+	retries atomic.Int64
+	latency Histogram
+
+	classMutex    sync.Mutex
+	errorsByClass map[string]int64
+}
+
+// NewOperation returns a new, empty Operation
+func NewOperation(name string) *Operation {
+	return &Operation{name: name}
+}
+
+// Name returns the name of the operation
+func (o *Operation) Name() string {
+	return o.name
+}
+
+// Observe registers one successful execution which took d
+func (o *Operation) Observe(d time.Duration) {
+	o.latency.Record(d)
+}
+
+// Fail registers one failed execution
+func (o *Operation) Fail() {
+	o.errors.Add(1)
+}
+
+// FailWith registers one failed execution, and counts it for the class of the error
+func (o *Operation) FailWith(class string) {
+	o.errors.Add(1)
+	o.classMutex.Lock()
+	defer o.classMutex.Unlock()
+	if o.errorsByClass == nil {
+		o.errorsByClass = map[string]int64{}
+	}
+	o.errorsByClass[class]++
+}
+
+// Retry registers that a failed execution is retried
+func (o *Operation) Retry() {
+	o.retries.Add(1)
+}
+
+// Count returns the number of successful executions
+func (o *Operation) Count() int64 {
+	return o.latency.Count()
+}
+
+// Errors returns the number of failed executions
+func (o *Operation) Errors() int64 {
+	return o.errors.Load()
+}
+
+// Retries returns the number of retried executions
+func (o *Operation) Retries() int64 {
+	return o.retries.Load()
+}
+
+// ErrorsByClass returns a copy of the number of failed executions per error class, or nil without any
+func (o *Operation) ErrorsByClass() map[string]int64 {
+	o.classMutex.Lock()
+	defer o.classMutex.Unlock()
+	if len(o.errorsByClass) == 0 {
+		return nil
+	}
+	errorsByClass := make(map[string]int64, len(o.errorsByClass))
+	for class, count := range o.errorsByClass {
+		errorsByClass[class] = count
+	}
+	return errorsByClass
+}
+
+// Latency returns a point in time copy of the latency histogram
+func (o *Operation) Latency() Snapshot {
+	return o.latency.Snapshot()
+}
+
+// Summarize returns the figures for this operation, where elapsed is the duration of the measurement
+func (o *Operation) Summarize(elapsed time.Duration) OperationSummary {
+	snapshot := o.latency.Snapshot()
+	summary := OperationSummary{
+		Name:          o.name,
+		Count:         snapshot.Count,
+		Errors:        o.errors.Load(),
+		ErrorsByClass: o.ErrorsByClass(),
+		Retries:       o.retries.Load(),
+		Latency:       snapshot.Summary(),
+	}
+	if elapsed > 0 {
+		summary.PerSecond = float64(snapshot.Count) / elapsed.Seconds()
+	}
+	return summary
+}
+
+// OperationSnapshot is a point in time copy of the figures of an Operation
+type OperationSnapshot struct {
+	Name          string
+	Errors        int64
+	ErrorsByClass map[string]int64
+	Retries       int64
+	Latency       Snapshot
+}
+
+// Snapshot returns a point in time copy of the figures of the operation
+func (o *Operation) Snapshot() OperationSnapshot {
+	return OperationSnapshot{
+		Name:          o.name,
+		Errors:        o.errors.Load(),
+		ErrorsByClass: o.ErrorsByClass(),
+		Retries:       o.retries.Load(),
+		Latency:       o.latency.Snapshot(),
+	}
+}
+
+// Since returns the figures between an earlier snapshot and this one, where elapsed is the time between them
+func (s OperationSnapshot) Since(earlier OperationSnapshot, elapsed time.Duration) OperationSummary {
+	latency := s.Latency.Sub(earlier.Latency)
+	summary := OperationSummary{
+		Name:    s.Name,
+		Count:   latency.Count,
+		Errors:  s.Errors - earlier.Errors,
+		Retries: s.Retries - earlier.Retries,
+		Latency: latency.Summary(),
+	}
+	for class, count := range s.ErrorsByClass {
+		if delta := count - earlier.ErrorsByClass[class]; delta > 0 {
+			if summary.ErrorsByClass == nil {
+				summary.ErrorsByClass = map[string]int64{}
+			}
+			summary.ErrorsByClass[class] = delta
+		}
+	}
+	if elapsed > 0 {
+		summary.PerSecond = float64(latency.Count) / elapsed.Seconds()
+	}
+	return summary
+}
+
+// OperationSummary holds the reported figures of an Operation
+type OperationSummary struct {
+	Name          string           `json:"name"`
+	Count         int64            `json:"count"`
+	Errors        int64            `json:"errors"`
+	ErrorsByClass map[string]int64 `json:"errors_by_class,omitempty"`
+	Retries       int64            `json:"retries"`
+	PerSecond     float64          `json:"per_second"`
+	Latency       LatencySummary   `json:"latency"`
+}
@@ -0,0 +1,111 @@
+// Package testrunner holds the plumbing to run a test with a number of parallel workers, each on its own
+// connection, with a warmup and a measurement window.
+package testrunner
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+)
+
+// OpenConns acquires one connection per worker from the pool
+func OpenConns(
+	parent context.Context,
+	pool dbinterface.Pool,
+	parallel int,
+	timeout time.Duration,
+) ([]dbinterface.Connection, error) {
+	conns := make([]dbinterface.Connection, 0, parallel)
+	for i := 0; i < parallel; i++ {
+		connectCtx, cancel := context.WithTimeout(parent, timeout)
+		conn, err := pool.Connect(connectCtx)
+		cancel()
+		if err != nil {
+			CloseAll(parent, conns)
+			return nil, fmt.Errorf("worker %d connect failed: %w", i, err)
+		}
+		conns = append(conns, conn)
+	}
+	return conns, nil
+}
+
+// CloseAll closes all connections, ignoring errors
+func CloseAll(ctx context.Context, conns []dbinterface.Connection) {
+	for _, c := range conns {
+		_ = c.Close(ctx)
+	}
+}
+
+// WarmupAndTotalContexts returns a context which is done after the warmup and one which is done after warmup and
+// execution
+func WarmupAndTotalContexts(
+	parent context.Context,
+	warmupTime int,
+	executionTime int,
+) (context.Context, context.Context, func()) {
+	warmupCtx, cancelWarmup := context.WithTimeout(parent, time.Duration(warmupTime)*time.Second)
+	totalCtx, cancelTotal := context.WithTimeout(parent, time.Duration(warmupTime+executionTime)*time.Second)
+
+	cancel := func() {
+		cancelWarmup()
+		cancelTotal()
+	}
+	return warmupCtx, totalCtx, cancel
+}
+
+// StartWorkers runs fn once per connection in its own go routine.
+// The returned channel receives exactly one result per worker.
+func StartWorkers(
+	parallel int,
+	conns []dbinterface.Connection,
+	fn func(workerID int, conn dbinterface.Connection) error,
+) <-chan error {
+	errCh := make(chan error, parallel)
+	for i := 0; i < parallel; i++ {
+		workerID := i
+		conn := conns[i]
+		go func() { errCh <- fn(workerID, conn) }()
+	}
+	return errCh
+}
+
+// CollectFirstError waits for all workers and returns the first error (if any)
+func CollectFirstError(errCh <-chan error, parallel int) error {
+	var firstErr error
+	for i := 0; i < parallel; i++ {
+		if wErr := <-errCh; wErr != nil && firstErr == nil {
+			firstErr = wErr
+		}
+	}
+	return firstErr
+}
+
+// Measurement tracks if the warmup has finished and when the measurement started
+type Measurement struct {
+	startedAt atomic.Int64 // unix nano, 0 during warmup
+}
+
+// Start marks the end of the warmup. Only the first call has effect.
+func (m *Measurement) Start() {
+	m.startedAt.CompareAndSwap(0, time.Now().UnixNano())
+}
+
+// Active returns true once the warmup has finished
+func (m *Measurement) Active() bool {
+	return m.startedAt.Load() != 0
+}
+
+// Elapsed returns how long we have been measuring, or fallback when the measurement never started
+func (m *Measurement) Elapsed(fallback time.Duration) time.Duration {
+	ns := m.startedAt.Load()
+	if ns == 0 {
+		return fallback
+	}
+	if elapsed := time.Since(time.Unix(0, ns)); elapsed > 0 {
+		return elapsed
+	}
+	return fallback
+}
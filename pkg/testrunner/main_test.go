@@ -0,0 +1,82 @@
+package testrunner
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface/fake"
+)
+
+var _ = Describe("OpenConns", func() {
+	ctx := context.Background()
+
+	It("should open one connection per worker", func() {
+		client := fake.NewClient()
+		p, err := client.Pool(ctx)
+		Ω(err).NotTo(HaveOccurred())
+		pool := p.(*fake.Pool)
+		conns, err := OpenConns(ctx, pool, 3, time.Second)
+		Ω(err).NotTo(HaveOccurred())
+		Ω(conns).To(HaveLen(3))
+		Ω(pool.Open()).To(Equal(3))
+		CloseAll(ctx, conns)
+		Ω(pool.Open()).To(BeZero())
+	})
+	It("should close the opened connections when one can not be opened", func() {
+		client := fake.NewClient()
+		client.Script.On(fake.StmtConnect).After(2).Fail(errors.New("too many clients already"))
+		p, err := client.Pool(ctx)
+		Ω(err).NotTo(HaveOccurred())
+		_, err = OpenConns(ctx, p, 3, time.Second)
+		Ω(err).To(MatchError(ContainSubstring("worker 2 connect failed")))
+		Ω(p.(*fake.Pool).Open()).To(BeZero())
+	})
+})
+
+var _ = Describe("StartWorkers", func() {
+	It("should run every worker and return the first error", func() {
+		conns := []dbinterface.Connection{&closingConn{}, &closingConn{}, &closingConn{}}
+		failed := errors.New("worker failed")
+		errCh := StartWorkers(len(conns), conns, func(workerID int, _ dbinterface.Connection) error {
+			if workerID == 1 {
+				return failed
+			}
+			return nil
+		})
+		Ω(CollectFirstError(errCh, len(conns))).To(MatchError(failed))
+	})
+})
+
+var _ = Describe("WarmupAndTotalContexts", func() {
+	It("should end the warmup before the total", func() {
+		warmupCtx, totalCtx, cancel := WarmupAndTotalContexts(context.Background(), 1, 1)
+		defer cancel()
+		warmupDeadline, ok := warmupCtx.Deadline()
+		Ω(ok).To(BeTrue())
+		totalDeadline, ok := totalCtx.Deadline()
+		Ω(ok).To(BeTrue())
+		Ω(totalDeadline.Sub(warmupDeadline)).To(BeNumerically("~", time.Second, 100*time.Millisecond))
+	})
+})
+
+var _ = Describe("Measurement", func() {
+	It("should return the fallback during the warmup", func() {
+		var m Measurement
+		Ω(m.Active()).To(BeFalse())
+		Ω(m.Elapsed(time.Minute)).To(Equal(time.Minute))
+	})
+	It("should measure from the first start", func() {
+		var m Measurement
+		m.Start()
+		time.Sleep(10 * time.Millisecond)
+		m.Start()
+		Ω(m.Active()).To(BeTrue())
+		Ω(m.Elapsed(time.Minute)).To(BeNumerically(">=", 10*time.Millisecond))
+		Ω(m.Elapsed(time.Minute)).To(BeNumerically("<", time.Minute))
+	})
+})
@@ -0,0 +1,185 @@
+package testrunner
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+	"github.com/pgvillage-tools/dbtwool/pkg/logging"
+)
+
+var logger = logging.Logger()
+
+// minReconnectBackoff keeps workers from hammering a database which is down
+const minReconnectBackoff = 10 * time.Millisecond
+
+// ReconnectPolicy defines if workers replace a lost connection (instead of failing the test), and how long they
+// wait between attempts. The wait starts at Backoff and doubles after every failed attempt, up to MaxBackoff.
+type ReconnectPolicy struct {
+	Enabled    bool
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// BackoffAfter returns the time to wait after failed attempt number attempt (starting at 1)
+func (p ReconnectPolicy) BackoffAfter(attempt int) time.Duration {
+	backoff := max(p.Backoff, minReconnectBackoff)
+	maxBackoff := max(p.MaxBackoff, backoff)
+	for ; attempt > 1 && backoff < maxBackoff; attempt-- {
+		backoff = min(2*backoff, maxBackoff)
+	}
+	return backoff
+}
+
+// Wait waits after failed attempt number attempt (starting at 1). It returns false when ctx is done first.
+func (p ReconnectPolicy) Wait(ctx context.Context, attempt int) bool {
+	return sleep(ctx, p.BackoffAfter(attempt))
+}
+
+// Outage is a period in which a worker had no working connection
+type Outage struct {
+	WorkerID int
+	// Start is the moment the worker noticed the connection was lost
+	Start time.Time
+	// End is the moment the new connection was ready, or the end of the test when it never was
+	End time.Time
+	// Attempts is the number of connects it took
+	Attempts int
+}
+
+// Duration returns how long the outage lasted
+func (o Outage) Duration() time.Duration {
+	return o.End.Sub(o.Start)
+}
+
+// Outages collects the outages of all workers. It is safe for concurrent use.
+type Outages struct {
+	mutex   sync.Mutex
+	outages []Outage
+}
+
+// Add records an outage
+func (o *Outages) Add(outage Outage) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.outages = append(o.outages, outage)
+}
+
+// All returns the outages of all workers, ordered by start time
+func (o *Outages) All() []Outage {
+	o.mutex.Lock()
+	all := append([]Outage(nil), o.outages...)
+	o.mutex.Unlock()
+	sort.Slice(all, func(i, j int) bool { return all[i].Start.Before(all[j].Start) })
+	return all
+}
+
+// Reconnects returns the number of times a worker lost its connection
+func (o *Outages) Reconnects() int {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	return len(o.outages)
+}
+
+// Downtime returns the total time workers were without a connection, summed over all workers
+func (o *Outages) Downtime() time.Duration {
+	var total time.Duration
+	for _, outage := range o.All() {
+		total += outage.Duration()
+	}
+	return total
+}
+
+// Windows merges overlapping outages of all workers, which returns the periods in which at least one worker had no
+// connection
+func (o *Outages) Windows() []Outage {
+	var windows []Outage
+	for _, outage := range o.All() {
+		last := len(windows) - 1
+		if last >= 0 && !outage.Start.After(windows[last].End) {
+			if outage.End.After(windows[last].End) {
+				windows[last].End = outage.End
+			}
+			windows[last].Attempts += outage.Attempts
+			continue
+		}
+		outage.WorkerID = -1
+		windows = append(windows, outage)
+	}
+	return windows
+}
+
+// Reconnector replaces the lost connections of workers
+type Reconnector struct {
+	policy  ReconnectPolicy
+	pool    dbinterface.Pool
+	setup   func(context.Context, dbinterface.Connection) error
+	outages *Outages
+}
+
+// NewReconnector returns a Reconnector which takes new connections from pool. setup prepares a new connection like
+// the original ones (isolation level, session settings), and may be nil.
+func NewReconnector(
+	policy ReconnectPolicy,
+	pool dbinterface.Pool,
+	setup func(context.Context, dbinterface.Connection) error,
+) *Reconnector {
+	return &Reconnector{policy: policy, pool: pool, setup: setup, outages: &Outages{}}
+}
+
+// Outages returns the outages of all workers which used this Reconnector
+func (r *Reconnector) Outages() *Outages {
+	return r.outages
+}
+
+// Recover replaces *conn with a new connection from the pool when err means that the connection was lost and
+// reconnecting is enabled. It keeps trying until it succeeds or ctx is done.
+// Recover returns true when it handled the error: *conn was replaced, or ctx was done while reconnecting. Otherwise
+// the error should be handled by the caller.
+func (r *Reconnector) Recover(
+	ctx context.Context,
+	workerID int,
+	conn *dbinterface.Connection,
+	err error,
+) bool {
+	if r == nil || !r.policy.Enabled || dbinterface.ClassifyError(*conn, err) != dbinterface.ErrorClassConnectionLost {
+		return false
+	}
+	outage := Outage{WorkerID: workerID, Start: time.Now()}
+	logger.Warn().Err(err).Int("worker", workerID).Msg("Connection lost, reconnecting")
+	_ = (*conn).Close(ctx)
+	for {
+		outage.Attempts++
+		newConn, connectErr := r.connect(ctx)
+		if connectErr == nil {
+			*conn = newConn
+			outage.End = time.Now()
+			r.outages.Add(outage)
+			logger.Info().Int("worker", workerID).Int("attempts", outage.Attempts).
+				Dur("downtime", outage.Duration()).Msg("Reconnected")
+			return true
+		}
+		if !r.policy.Wait(ctx, outage.Attempts) {
+			// the test is over, so this worker stays down until the end
+			outage.End = time.Now()
+			r.outages.Add(outage)
+			return true
+		}
+	}
+}
+
+func (r *Reconnector) connect(ctx context.Context) (dbinterface.Connection, error) {
+	conn, err := r.pool.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if r.setup != nil {
+		if err = r.setup(ctx, conn); err != nil {
+			_ = conn.Close(ctx)
+			return nil, err
+		}
+	}
+	return conn, nil
+}
@@ -0,0 +1,119 @@
+package testrunner
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+)
+
+// closingConn records if it was closed
+type closingConn struct {
+	classifyingConn
+	closed bool
+}
+
+func (c *closingConn) Close(context.Context) error {
+	c.closed = true
+	return nil
+}
+
+// flakyPool fails the first failures connects
+type flakyPool struct {
+	failures int
+	connects int
+}
+
+func (p *flakyPool) Connect(context.Context) (dbinterface.Connection, error) {
+	p.connects++
+	if p.connects <= p.failures {
+		return nil, errors.New("the database system is starting up")
+	}
+	return &closingConn{}, nil
+}
+
+var _ = Describe("ReconnectPolicy", func() {
+	It("should double the backoff after every attempt, up to the maximum", func() {
+		policy := ReconnectPolicy{Backoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+		Ω(policy.BackoffAfter(1)).To(Equal(100 * time.Millisecond))
+		Ω(policy.BackoffAfter(2)).To(Equal(200 * time.Millisecond))
+		Ω(policy.BackoffAfter(3)).To(Equal(300 * time.Millisecond))
+		Ω(policy.BackoffAfter(1000)).To(Equal(300 * time.Millisecond))
+	})
+	It("should not wait less than the minimum backoff", func() {
+		Ω(ReconnectPolicy{}.BackoffAfter(1)).To(Equal(minReconnectBackoff))
+	})
+	It("should stop waiting when the context is done", func() {
+		canceled, cancel := context.WithCancel(context.Background())
+		cancel()
+		Ω(ReconnectPolicy{Backoff: time.Hour}.Wait(canceled, 1)).To(BeFalse())
+	})
+})
+
+var _ = Describe("Reconnector", func() {
+	ctx := context.Background()
+	lost := errors.New("server closed the connection unexpectedly")
+	enabled := ReconnectPolicy{Enabled: true, Backoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+
+	It("should replace a lost connection after a few attempts", func() {
+		pool := &flakyPool{failures: 3}
+		var setups int
+		r := NewReconnector(enabled, pool, func(context.Context, dbinterface.Connection) error {
+			setups++
+			return nil
+		})
+		old := &closingConn{classifyingConn: classifyingConn{class: dbinterface.ErrorClassConnectionLost}}
+		var conn dbinterface.Connection = old
+		Ω(r.Recover(ctx, 1, &conn, lost)).To(BeTrue())
+		Ω(old.closed).To(BeTrue())
+		Ω(conn).NotTo(BeIdenticalTo(old))
+		Ω(setups).To(Equal(1))
+		Ω(r.Outages().Reconnects()).To(Equal(1))
+		Ω(r.Outages().All()[0].Attempts).To(Equal(4))
+		Ω(r.Outages().Downtime()).To(BeNumerically(">", 0))
+	})
+	It("should leave other errors to the caller", func() {
+		r := NewReconnector(enabled, &flakyPool{}, nil)
+		var conn dbinterface.Connection = &closingConn{
+			classifyingConn: classifyingConn{class: dbinterface.ErrorClassDeadlock},
+		}
+		Ω(r.Recover(ctx, 1, &conn, errors.New("deadlock detected"))).To(BeFalse())
+	})
+	It("should not reconnect when it is disabled", func() {
+		r := NewReconnector(ReconnectPolicy{}, &flakyPool{}, nil)
+		var conn dbinterface.Connection = &closingConn{
+			classifyingConn: classifyingConn{class: dbinterface.ErrorClassConnectionLost},
+		}
+		Ω(r.Recover(ctx, 1, &conn, lost)).To(BeFalse())
+	})
+	It("should stop when the test is over", func() {
+		r := NewReconnector(enabled, &flakyPool{failures: 1 << 30}, nil)
+		var conn dbinterface.Connection = &closingConn{
+			classifyingConn: classifyingConn{class: dbinterface.ErrorClassConnectionLost},
+		}
+		shortCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		Ω(r.Recover(shortCtx, 1, &conn, lost)).To(BeTrue())
+		Ω(r.Outages().Reconnects()).To(Equal(1))
+	})
+})
+
+var _ = Describe("Outages", func() {
+	It("should merge overlapping outages of workers into windows", func() {
+		start := time.Now()
+		outages := &Outages{}
+		outages.Add(Outage{WorkerID: 0, Start: start, End: start.Add(2 * time.Second), Attempts: 2})
+		outages.Add(Outage{WorkerID: 1, Start: start.Add(time.Second), End: start.Add(3 * time.Second), Attempts: 3})
+		outages.Add(Outage{WorkerID: 0, Start: start.Add(10 * time.Second), End: start.Add(11 * time.Second)})
+		windows := outages.Windows()
+		Ω(windows).To(HaveLen(2))
+		Ω(windows[0].Duration()).To(Equal(3 * time.Second))
+		Ω(windows[0].Attempts).To(Equal(5))
+		Ω(windows[1].Duration()).To(Equal(time.Second))
+		Ω(outages.Downtime()).To(Equal(5 * time.Second))
+	})
+})
@@ -0,0 +1,79 @@
+package testrunner
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+)
+
+// RetryPolicy defines which classes of errors are expected during a test, and how often a failed transaction is
+// retried. Expected errors are counted, but never abort the test.
+type RetryPolicy struct {
+	MaxRetries int
+	Backoff    time.Duration
+	RetryOn    []dbinterface.ErrorClass
+}
+
+// NewRetryPolicy returns a RetryPolicy from command line values
+func NewRetryPolicy(maxRetries int, retryOn []string, backoff time.Duration) (RetryPolicy, error) {
+	policy := RetryPolicy{MaxRetries: maxRetries, Backoff: backoff}
+	for _, name := range retryOn {
+		if name == "" {
+			continue
+		}
+		class, err := dbinterface.ParseErrorClass(name)
+		if err != nil {
+			return RetryPolicy{}, err
+		}
+		policy.RetryOn = append(policy.RetryOn, class)
+	}
+	return policy, nil
+}
+
+// Expected returns true if errors of this class should not abort the test
+func (p RetryPolicy) Expected(class dbinterface.ErrorClass) bool {
+	return slices.Contains(p.RetryOn, class)
+}
+
+// Run runs fn until it succeeds, fails with an unexpected error, or MaxRetries is reached.
+// onError is called for every failed attempt with the class of the error, and if it will be retried.
+// Run returns the class of the last error (if any) and the error itself.
+func (p RetryPolicy) Run(
+	ctx context.Context,
+	conn dbinterface.Connection,
+	fn func() error,
+	onError func(class dbinterface.ErrorClass, retrying bool),
+) (dbinterface.ErrorClass, error) {
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return "", nil
+		}
+		class := dbinterface.ClassifyError(conn, err)
+		retrying := p.Expected(class) && attempt < p.MaxRetries && ctx.Err() == nil
+		onError(class, retrying)
+		if !retrying {
+			return class, err
+		}
+		if !sleep(ctx, p.Backoff) {
+			return class, err
+		}
+	}
+}
+
+// sleep waits for d, or until ctx is done. It returns false if ctx is done.
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
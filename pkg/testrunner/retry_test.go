@@ -0,0 +1,81 @@
+package testrunner
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+)
+
+// classifyingConn is a connection which classifies every error as its class
+type classifyingConn struct {
+	dbinterface.Connection
+	class dbinterface.ErrorClass
+}
+
+func (c classifyingConn) ClassifyError(error) dbinterface.ErrorClass {
+	return c.class
+}
+
+var _ = Describe("RetryPolicy", func() {
+	var (
+		ctx      context.Context
+		failures []bool
+		onError  = func(_ dbinterface.ErrorClass, retrying bool) { failures = append(failures, retrying) }
+		errBoom  = errors.New("boom")
+	)
+	BeforeEach(func() {
+		ctx = context.Background()
+		failures = nil
+	})
+	Context("NewRetryPolicy", func() {
+		It("should parse error classes", func() {
+			policy, err := NewRetryPolicy(3, []string{"deadlock", "", "Serialization"}, 0)
+			Ω(err).NotTo(HaveOccurred())
+			Ω(policy.RetryOn).To(Equal([]dbinterface.ErrorClass{
+				dbinterface.ErrorClassDeadlock, dbinterface.ErrorClassSerialization}))
+			Ω(policy.Expected(dbinterface.ErrorClassDeadlock)).To(BeTrue())
+			Ω(policy.Expected(dbinterface.ErrorClassOther)).To(BeFalse())
+		})
+		It("should reject unknown error classes", func() {
+			_, err := NewRetryPolicy(3, []string{"oops"}, 0)
+			Ω(err).To(HaveOccurred())
+		})
+	})
+	Context("Run", func() {
+		It("should retry expected errors until it succeeds", func() {
+			policy := RetryPolicy{MaxRetries: 3, RetryOn: []dbinterface.ErrorClass{dbinterface.ErrorClassDeadlock}}
+			conn := classifyingConn{class: dbinterface.ErrorClassDeadlock}
+			attempts := 0
+			class, err := policy.Run(ctx, conn, func() error {
+				attempts++
+				if attempts < 3 {
+					return errBoom
+				}
+				return nil
+			}, onError)
+			Ω(err).NotTo(HaveOccurred())
+			Ω(class).To(BeEmpty())
+			Ω(failures).To(Equal([]bool{true, true}))
+		})
+		It("should give up after MaxRetries", func() {
+			policy := RetryPolicy{MaxRetries: 2, RetryOn: []dbinterface.ErrorClass{dbinterface.ErrorClassDeadlock}}
+			conn := classifyingConn{class: dbinterface.ErrorClassDeadlock}
+			class, err := policy.Run(ctx, conn, func() error { return errBoom }, onError)
+			Ω(err).To(MatchError(errBoom))
+			Ω(class).To(Equal(dbinterface.ErrorClassDeadlock))
+			Ω(failures).To(Equal([]bool{true, true, false}))
+		})
+		It("should not retry unexpected errors", func() {
+			policy := RetryPolicy{MaxRetries: 2, RetryOn: []dbinterface.ErrorClass{dbinterface.ErrorClassDeadlock}}
+			conn := classifyingConn{class: dbinterface.ErrorClassOther}
+			class, err := policy.Run(ctx, conn, func() error { return errBoom }, onError)
+			Ω(err).To(HaveOccurred())
+			Ω(class).To(Equal(dbinterface.ErrorClassOther))
+			Ω(failures).To(Equal([]bool{false}))
+		})
+	})
+})
@@ -0,0 +1,13 @@
+package testrunner
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestTestrunner(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Testrunner Suite")
+}
@@ -0,0 +1,85 @@
+// Package tracing exports OpenTelemetry traces of the stage, gen and test phases, of every generated batch and of a
+// sample of the queries of the workers, so that the activity of dbtwool can be correlated with application traces
+// and the slowest operations can be drilled into. Without Setup, spans are not recorded.
+package tracing
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbclient"
+	"github.com/pgvillage-tools/dbtwool/pkg/logging"
+)
+
+var logger = logging.Logger()
+
+const tracerName = "github.com/pgvillage-tools/dbtwool"
+
+// querySample is the number of queries of which one is traced, or 0 to trace none
+var querySample atomic.Uint64
+
+// Start starts a span, which is a child of the span in ctx
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartQuery starts a span for a query of a worker, but only for one in every --traceQuerySample queries. Other
+// queries get a span which is not recorded.
+func StartQuery(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	sample := querySample.Load()
+	if sample == 0 || rand.Uint64N(sample) != 0 {
+		return ctx, noop.Span{}
+	}
+	return Start(ctx, name, attrs...)
+}
+
+// End ends a span, and marks it as failed when err is set
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// RDBMS returns the attribute for the RDBMS a span ran against
+func RDBMS(dbType dbclient.RDBMS) attribute.KeyValue {
+	return attribute.String("db.system.name", string(dbType))
+}
+
+// Table returns the attribute for the table a span worked on
+func Table(schema string, table string) attribute.KeyValue {
+	return attribute.String("db.collection.name", schema+"."+table)
+}
+
+// LobType returns the attribute for the type of LOB a span worked on
+func LobType(lobType string) attribute.KeyValue {
+	return attribute.String("dbtwool.lob_type", lobType)
+}
+
+// Bytes returns the attribute for the number of bytes a span read or wrote
+func Bytes(n int64) attribute.KeyValue {
+	return attribute.Int64("dbtwool.bytes", n)
+}
+
+// Rows returns the attribute for the number of rows a span read or wrote
+func Rows(n int64) attribute.KeyValue {
+	return attribute.Int64("dbtwool.rows", n)
+}
+
+// RowID returns the attribute for the id of the row a span read
+func RowID(id int64) attribute.KeyValue {
+	return attribute.Int64("dbtwool.row_id", id)
+}
+
+// Batch returns the attribute for the index of a generated batch
+func Batch(index int) attribute.KeyValue {
+	return attribute.Int("dbtwool.batch_index", index)
+}
@@ -0,0 +1,78 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/pgvillage-tools/dbtwool/internal/version"
+)
+
+// Config defines where traces are exported to
+type Config struct {
+	// Service is the name of the service in the traces, e.g. pgtwool
+	Service string
+	// Endpoint is the url of an OTLP/HTTP collector, e.g. http://localhost:4318
+	Endpoint string
+	// File is a file which the spans are appended to as JSON, one span per line
+	File string
+	// QuerySample is the number of queries of the workers of which one is traced. 0 traces no queries.
+	QuerySample uint
+}
+
+// Setup installs a tracer provider which exports to the endpoint and file of cfg. Nothing is installed when neither
+// is set. The returned function flushes the remaining spans and stops exporting.
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	if cfg.Endpoint == "" && cfg.File == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+	options := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", cfg.Service),
+			attribute.String("service.version", version.GetAppVersion()),
+		)),
+	}
+	if cfg.Endpoint != "" {
+		exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the trace exporter for %s: %w", cfg.Endpoint, err)
+		}
+		options = append(options, sdktrace.WithBatcher(exporter))
+	}
+	var file *os.File
+	if cfg.File != "" {
+		var err error
+		file, err = os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open the trace file: %w", err)
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("failed to create the trace file exporter: %w", err)
+		}
+		options = append(options, sdktrace.WithBatcher(exporter))
+	}
+	provider := sdktrace.NewTracerProvider(options...)
+	otel.SetTracerProvider(provider)
+	querySample.Store(uint64(cfg.QuerySample))
+	logger.Info().Str("endpoint", cfg.Endpoint).Str("file", cfg.File).Uint("query_sample", cfg.QuerySample).
+		Msg("Exporting traces")
+
+	return func(ctx context.Context) error {
+		querySample.Store(0)
+		err := provider.Shutdown(ctx)
+		if file != nil {
+			err = errors.Join(err, file.Close())
+		}
+		return err
+	}, nil
+}
@@ -0,0 +1,58 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbclient"
+	"github.com/pgvillage-tools/dbtwool/pkg/tracing"
+)
+
+var _ = Describe("Tracing", func() {
+	ctx := context.Background()
+
+	It("should not record spans without an exporter", func() {
+		shutdown, err := tracing.Setup(ctx, tracing.Config{Service: "pgtwool"})
+		Expect(err).NotTo(HaveOccurred())
+		_, span := tracing.StartQuery(ctx, "lob-performance read")
+		Expect(span.IsRecording()).To(BeFalse())
+		Expect(shutdown(ctx)).To(Succeed())
+	})
+
+	It("should write spans with their attributes and errors to a file", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "traces.json")
+		shutdown, err := tracing.Setup(ctx, tracing.Config{Service: "pgtwool", File: path, QuerySample: 1})
+		Expect(err).NotTo(HaveOccurred())
+
+		testCtx, test := tracing.Start(ctx, "lob-performance test", tracing.RDBMS(dbclient.Postgres),
+			tracing.Table("s", "t"))
+		_, read := tracing.StartQuery(testCtx, "lob-performance read", tracing.RowID(42))
+		Expect(read.IsRecording()).To(BeTrue())
+		read.SetAttributes(tracing.Bytes(1024))
+		tracing.End(read, nil)
+		tracing.End(test, errors.New("corrupt page"))
+		Expect(shutdown(ctx)).To(Succeed())
+
+		content, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(content)).To(ContainSubstring(`"Name":"lob-performance read"`))
+		Expect(string(content)).To(ContainSubstring(`"dbtwool.row_id"`))
+		Expect(string(content)).To(ContainSubstring(`"db.collection.name"`))
+		Expect(string(content)).To(ContainSubstring("corrupt page"))
+		Expect(string(content)).To(ContainSubstring(`"service.name"`))
+
+		// queries are no longer traced after the shutdown
+		_, span := tracing.StartQuery(ctx, "lob-performance read")
+		Expect(span.IsRecording()).To(BeFalse())
+	})
+
+	It("should fail when the trace file cannot be opened", func() {
+		_, err := tracing.Setup(ctx, tracing.Config{File: filepath.Join(GinkgoT().TempDir(), "missing", "t.json")})
+		Expect(err).To(MatchError(ContainSubstring("failed to open the trace file")))
+	})
+})
@@ -0,0 +1,136 @@
+// Package workload runs a user defined mix of SQL statements against a database
+package workload
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// StatementKind defines how a statement is executed
+type StatementKind string
+
+const (
+	// KindQuery means the statement returns rows (SELECT, WITH, VALUES, ...)
+	KindQuery StatementKind = "query"
+	// KindExec means the statement only returns the number of affected rows (INSERT, UPDATE, DELETE, ...)
+	KindExec StatementKind = "exec"
+)
+
+var queryKeywords = []string{"SELECT", "WITH", "VALUES", "SHOW", "EXPLAIN"}
+
+// Definition is the content of a workload file
+type Definition struct {
+	Transactions []Transaction `yaml:"transactions"`
+}
+
+// Transaction is a unit of work which is executed between BEGIN and COMMIT.
+// Weight defines how often it is picked relative to the other transactions (defaults to 1).
+type Transaction struct {
+	Name       string      `yaml:"name"`
+	Weight     int         `yaml:"weight"`
+	Statements []Statement `yaml:"statements"`
+}
+
+// Statement is one SQL statement within a transaction, with generators for its parameters
+type Statement struct {
+	Name   string        `yaml:"name"`
+	SQL    string        `yaml:"sql"`
+	Kind   StatementKind `yaml:"kind"`
+	Params []ParamSpec   `yaml:"params"`
+}
+
+// LoadDefinition reads and validates a workload file
+func LoadDefinition(path string) (Definition, error) {
+	// #nosec G304 -- reading a user supplied workload file is the whole point
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Definition{}, fmt.Errorf("could not read workload file: %w", err)
+	}
+	return ParseDefinition(data)
+}
+
+// ParseDefinition parses and validates a workload definition
+func ParseDefinition(data []byte) (Definition, error) {
+	var def Definition
+	if err := yaml.Unmarshal(data, &def); err != nil {
+		return Definition{}, fmt.Errorf("could not parse workload definition: %w", err)
+	}
+	if err := def.normalize(); err != nil {
+		return Definition{}, err
+	}
+	return def, nil
+}
+
+// normalize validates the definition and fills in defaults
+func (def *Definition) normalize() error {
+	if len(def.Transactions) == 0 {
+		return errors.New("workload definition contains no transactions")
+	}
+	seen := map[string]bool{}
+	for i := range def.Transactions {
+		tx := &def.Transactions[i]
+		if tx.Name == "" {
+			tx.Name = fmt.Sprintf("tx%d", i+1)
+		}
+		if tx.Weight < 0 {
+			return fmt.Errorf("transaction %s has a negative weight", tx.Name)
+		} else if tx.Weight == 0 {
+			tx.Weight = 1
+		}
+		if len(tx.Statements) == 0 {
+			return fmt.Errorf("transaction %s contains no statements", tx.Name)
+		}
+		for j := range tx.Statements {
+			stmt := &tx.Statements[j]
+			if err := stmt.normalize(tx.Name, j); err != nil {
+				return err
+			}
+			if seen[stmt.Name] {
+				return fmt.Errorf("statement name %s is not unique", stmt.Name)
+			}
+			seen[stmt.Name] = true
+		}
+	}
+	return nil
+}
+
+func (stmt *Statement) normalize(txName string, index int) error {
+	if stmt.Name == "" {
+		stmt.Name = fmt.Sprintf("%s.%d", txName, index+1)
+	}
+	if strings.TrimSpace(stmt.SQL) == "" {
+		return fmt.Errorf("statement %s has no sql", stmt.Name)
+	}
+	switch stmt.Kind {
+	case "":
+		stmt.Kind = detectKind(stmt.SQL)
+	case KindQuery, KindExec:
+	default:
+		return fmt.Errorf("statement %s has invalid kind %q (expected %s or %s)", stmt.Name, stmt.Kind, KindQuery,
+			KindExec)
+	}
+	for k, spec := range stmt.Params {
+		if err := spec.validate(); err != nil {
+			return fmt.Errorf("statement %s, parameter %d: %w", stmt.Name, k+1, err)
+		}
+	}
+	return nil
+}
+
+func detectKind(sql string) StatementKind {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return KindExec
+	}
+	first := strings.ToUpper(strings.TrimLeft(fields[0], "("))
+	for _, keyword := range queryKeywords {
+		if first == keyword {
+			return KindQuery
+		}
+	}
+	return KindExec
+}
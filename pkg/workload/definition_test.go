@@ -0,0 +1,56 @@
+package workload
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Definition", func() {
+	Context("ParseDefinition", func() {
+		It("should fill in defaults", func() {
+			def, err := ParseDefinition([]byte(`
+transactions:
+  - statements:
+      - sql: SELECT * FROM accounts WHERE id = $1
+        params:
+          - type: uniform
+            min: 1
+            max: 100
+      - sql: UPDATE accounts SET balance = balance + 1 WHERE id = $1
+        params:
+          - type: zipf
+            min: 1
+            max: 100
+  - name: report
+    weight: 3
+    statements:
+      - name: total
+        sql: "  with t as (select 1) select * from t"
+`))
+			Ω(err).NotTo(HaveOccurred())
+			Ω(def.Transactions).To(HaveLen(2))
+			tx := def.Transactions[0]
+			Ω(tx.Name).To(Equal("tx1"))
+			Ω(tx.Weight).To(Equal(1))
+			Ω(tx.Statements[0].Name).To(Equal("tx1.1"))
+			Ω(tx.Statements[0].Kind).To(Equal(KindQuery))
+			Ω(tx.Statements[1].Kind).To(Equal(KindExec))
+			Ω(def.Transactions[1].Weight).To(Equal(3))
+			Ω(def.Transactions[1].Statements[0].Kind).To(Equal(KindQuery))
+		})
+		DescribeTable("should reject invalid definitions",
+			func(yaml string) {
+				_, err := ParseDefinition([]byte(yaml))
+				Ω(err).To(HaveOccurred())
+			},
+			Entry("no transactions", `transactions: []`),
+			Entry("no statements", `transactions: [{name: a}]`),
+			Entry("negative weight", `transactions: [{weight: -1, statements: [{sql: SELECT 1}]}]`),
+			Entry("empty sql", `transactions: [{statements: [{sql: " "}]}]`),
+			Entry("invalid kind", `transactions: [{statements: [{sql: SELECT 1, kind: fetch}]}]`),
+			Entry("duplicate names", `transactions: [{statements: [{name: a, sql: SELECT 1}, {name: a, sql: SELECT 2}]}]`),
+			Entry("unknown param", `transactions: [{statements: [{sql: SELECT 1, params: [{type: gauss}]}]}]`),
+			Entry("invalid yaml", `transactions: {`),
+		)
+	})
+})
@@ -0,0 +1,17 @@
+package workload
+
+import (
+	"time"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/logging"
+)
+
+var logger = logging.Logger()
+
+const (
+	decimalSystem        = 10
+	bitSize64            = 64
+	defaultWarmupTime    = 10
+	defaultExecutionTime = 20
+	connectTimeout       = 60 * time.Second
+)
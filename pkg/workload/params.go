@@ -0,0 +1,132 @@
+package workload
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// ParamType defines which generator is used for a statement parameter
+type ParamType string
+
+const (
+	// ParamUniform generates integers evenly distributed between min and max
+	ParamUniform ParamType = "uniform"
+	// ParamZipf generates integers between min and max where low values are much more common (hot rows)
+	ParamZipf ParamType = "zipf"
+	// ParamList picks a random value from a list
+	ParamList ParamType = "list"
+	// ParamString generates a random alphanumeric string
+	ParamString ParamType = "string"
+	// ParamTimestamp generates a random timestamp within a window before now
+	ParamTimestamp ParamType = "timestamp"
+)
+
+const (
+	defaultZipfS = 1.1
+	defaultZipfV = 1.0
+)
+
+var stringRunes = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789")
+
+// ParamSpec defines how values for a statement parameter are generated
+type ParamSpec struct {
+	Type ParamType `yaml:"type"`
+	// uniform and zipf
+	Min int64 `yaml:"min"`
+	Max int64 `yaml:"max"`
+	// zipf only: s > 1 controls the skew, v >= 1
+	S float64 `yaml:"s"`
+	V float64 `yaml:"v"`
+	// list
+	Values []any `yaml:"values"`
+	// string: either a fixed length, or a range
+	Length    int `yaml:"length"`
+	MinLength int `yaml:"minLength"`
+	MaxLength int `yaml:"maxLength"`
+	// timestamp: a Go duration (e.g. 30m, 24h)
+	Window string `yaml:"window"`
+}
+
+func (spec ParamSpec) validate() error {
+	switch spec.Type {
+	case ParamUniform:
+		if spec.Max < spec.Min {
+			return errors.New("max must be >= min")
+		}
+	case ParamZipf:
+		if spec.Max < spec.Min {
+			return errors.New("max must be >= min")
+		}
+		if spec.S != 0 && spec.S <= 1 {
+			return errors.New("s must be > 1")
+		}
+		if spec.V != 0 && spec.V < 1 {
+			return errors.New("v must be >= 1")
+		}
+	case ParamList:
+		if len(spec.Values) == 0 {
+			return errors.New("values cannot be empty")
+		}
+	case ParamString:
+		if spec.Length < 0 || spec.MinLength < 0 || spec.MaxLength < spec.MinLength {
+			return errors.New("invalid string length (use length, or minLength <= maxLength)")
+		}
+	case ParamTimestamp:
+		window, err := time.ParseDuration(spec.Window)
+		if err != nil {
+			return fmt.Errorf("invalid window: %w", err)
+		}
+		if window <= 0 {
+			return errors.New("window must be > 0")
+		}
+	default:
+		return fmt.Errorf("unknown parameter type %q", spec.Type)
+	}
+	return nil
+}
+
+// generator returns a new value every time it is called
+type generator func() any
+
+// newGenerator returns a generator for a (validated) spec. The generator is not safe for concurrent use, as it
+// shares r.
+func newGenerator(spec ParamSpec, r *rand.Rand) generator {
+	switch spec.Type {
+	case ParamUniform:
+		span := spec.Max - spec.Min + 1
+		return func() any { return spec.Min + r.Int63n(span) }
+	case ParamZipf:
+		s, v := spec.S, spec.V
+		if s == 0 {
+			s = defaultZipfS
+		}
+		if v == 0 {
+			v = defaultZipfV
+		}
+		z := rand.NewZipf(r, s, v, uint64(spec.Max-spec.Min))
+		return func() any { return spec.Min + int64(z.Uint64()) }
+	case ParamList:
+		return func() any { return spec.Values[r.Intn(len(spec.Values))] }
+	case ParamString:
+		minLength, maxLength := spec.MinLength, spec.MaxLength
+		if spec.Length > 0 {
+			minLength, maxLength = spec.Length, spec.Length
+		}
+		return func() any { return randomString(r, minLength+r.Intn(maxLength-minLength+1)) }
+	case ParamTimestamp:
+		window, _ := time.ParseDuration(spec.Window)
+		return func() any { return time.Now().Add(-time.Duration(r.Int63n(int64(window)))) }
+	default:
+		panic(fmt.Sprintf("unsupported parameter type %q", spec.Type))
+	}
+}
+
+func randomString(r *rand.Rand, n int) string {
+	b := make([]rune, n)
+	for i := range b {
+		b[i] = stringRunes[r.Intn(len(stringRunes))]
+	}
+	return string(b)
+}
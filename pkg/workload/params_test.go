@@ -0,0 +1,81 @@
+package workload
+
+import (
+	"math/rand"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Params", func() {
+	const samples = 1000
+	var r *rand.Rand
+	BeforeEach(func() {
+		r = rand.New(rand.NewSource(42))
+	})
+	Context("validate", func() {
+		DescribeTable("should reject invalid specs",
+			func(spec ParamSpec) {
+				Ω(spec.validate()).To(HaveOccurred())
+			},
+			Entry("uniform max < min", ParamSpec{Type: ParamUniform, Min: 2, Max: 1}),
+			Entry("zipf s <= 1", ParamSpec{Type: ParamZipf, Max: 10, S: 0.5}),
+			Entry("zipf v < 1", ParamSpec{Type: ParamZipf, Max: 10, V: 0.5}),
+			Entry("empty list", ParamSpec{Type: ParamList}),
+			Entry("string maxLength < minLength", ParamSpec{Type: ParamString, MinLength: 3, MaxLength: 2}),
+			Entry("timestamp without window", ParamSpec{Type: ParamTimestamp}),
+			Entry("timestamp with negative window", ParamSpec{Type: ParamTimestamp, Window: "-1h"}),
+		)
+	})
+	Context("newGenerator", func() {
+		It("should generate uniform values within range", func() {
+			gen := newGenerator(ParamSpec{Type: ParamUniform, Min: 5, Max: 10}, r)
+			for range samples {
+				Ω(gen()).To(And(BeNumerically(">=", 5), BeNumerically("<=", 10)))
+			}
+		})
+		It("should generate skewed zipf values within range", func() {
+			gen := newGenerator(ParamSpec{Type: ParamZipf, Min: 1, Max: 100}, r)
+			low := 0
+			for range samples {
+				v := gen().(int64)
+				Ω(v).To(And(BeNumerically(">=", 1), BeNumerically("<=", 100)))
+				if v <= 10 {
+					low++
+				}
+			}
+			Ω(low).To(BeNumerically(">", samples/2))
+		})
+		It("should pick values from the list", func() {
+			values := []any{"a", "b", 3}
+			gen := newGenerator(ParamSpec{Type: ParamList, Values: values}, r)
+			for range samples {
+				Ω(values).To(ContainElement(gen()))
+			}
+		})
+		It("should generate strings of the requested length", func() {
+			gen := newGenerator(ParamSpec{Type: ParamString, Length: 12}, r)
+			Ω(gen()).To(HaveLen(12))
+			gen = newGenerator(ParamSpec{Type: ParamString, MinLength: 2, MaxLength: 4}, r)
+			for range samples {
+				Ω(len(gen().(string))).To(And(BeNumerically(">=", 2), BeNumerically("<=", 4)))
+			}
+		})
+		It("should generate timestamps within the window", func() {
+			gen := newGenerator(ParamSpec{Type: ParamTimestamp, Window: "1h"}, r)
+			for range samples {
+				ts := gen().(time.Time)
+				Ω(ts).To(BeTemporally("~", time.Now().Add(-30*time.Minute), 31*time.Minute))
+			}
+		})
+		It("should be deterministic for the same seed", func() {
+			spec := ParamSpec{Type: ParamUniform, Min: 1, Max: 1000000}
+			a := newGenerator(spec, rand.New(rand.NewSource(1)))
+			b := newGenerator(spec, rand.New(rand.NewSource(1)))
+			for range 10 {
+				Ω(a()).To(Equal(b()))
+			}
+		})
+	})
+})
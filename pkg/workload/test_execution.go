@@ -0,0 +1,283 @@
+package workload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+	"github.com/pgvillage-tools/dbtwool/pkg/stats"
+	"github.com/pgvillage-tools/dbtwool/pkg/testrunner"
+)
+
+// ExecuteTest runs the workload on parallel connections. After the warmup, every statement and transaction is
+// measured separately.
+func ExecuteTest(
+	ctx context.Context,
+	client dbinterface.Client,
+	def Definition,
+	seed string,
+	parallel int,
+	warmupTime int,
+	executionTime int,
+	retryPolicy testrunner.RetryPolicy,
+	reconnect testrunner.ReconnectPolicy,
+) error {
+	logger := logger.With().Int("parallel", parallel).Logger()
+
+	if parallel <= 0 {
+		return errors.New("parallel must be > 0")
+	}
+	if warmupTime <= 0 {
+		warmupTime = defaultWarmupTime
+	}
+	if executionTime <= 0 {
+		executionTime = defaultExecutionTime
+	}
+	seedInt, err := parseSeed(seed)
+	if err != nil {
+		return err
+	}
+
+	if err = dbinterface.EnsurePoolSize(client, parallel); err != nil {
+		return err
+	}
+	pool, err := client.Pool(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to init pool: %w", err)
+	}
+
+	logger.Info().Msgf("Acquiring %v connections from pool.", parallel)
+	conns, err := testrunner.OpenConns(ctx, pool, parallel, connectTimeout)
+	if err != nil {
+		return err
+	}
+	defer func() { testrunner.CloseAll(ctx, conns) }()
+
+	metrics := newWorkloadMetrics(def)
+	warmupCtx, totalCtx, cancel := testrunner.WarmupAndTotalContexts(ctx, warmupTime, executionTime)
+	defer cancel()
+
+	var measurement testrunner.Measurement
+	logger.Info().Msg("Starting workers.")
+	reconnector := testrunner.NewReconnector(reconnect, pool, nil)
+	errCh := testrunner.StartWorkers(parallel, conns, func(workerID int, _ dbinterface.Connection) error {
+		w := newWorker(def, metrics, rand.New(rand.NewSource(seedInt+int64(workerID))), retryPolicy)
+		// a replaced connection is stored in conns, so that it is closed at the end
+		return w.run(totalCtx, workerID, &conns[workerID], reconnector, &measurement)
+	})
+
+	<-warmupCtx.Done()
+	logger.Info().Msg("Warmup finished. Starting measurements.")
+	measurement.Start()
+
+	<-totalCtx.Done()
+
+	if firstErr := testrunner.CollectFirstError(errCh, parallel); firstErr != nil {
+		return firstErr
+	}
+
+	metrics.log(logger, def, measurement.Elapsed(time.Duration(executionTime)*time.Second), reconnector.Outages())
+	return nil
+}
+
+func parseSeed(seed string) (int64, error) {
+	if seed == "" {
+		return time.Now().UnixNano(), nil
+	}
+	seedInt, err := strconv.ParseInt(seed, decimalSystem, bitSize64)
+	if err != nil {
+		return 0, fmt.Errorf("seed must be an integer (got %q): %w", seed, err)
+	}
+	return seedInt, nil
+}
+
+// workloadMetrics holds the metrics of all statements and transactions, shared by all workers
+type workloadMetrics struct {
+	transactions map[string]*stats.Operation
+	statements   map[string]*stats.Operation
+}
+
+func newWorkloadMetrics(def Definition) workloadMetrics {
+	m := workloadMetrics{
+		transactions: map[string]*stats.Operation{},
+		statements:   map[string]*stats.Operation{},
+	}
+	for _, tx := range def.Transactions {
+		m.transactions[tx.Name] = stats.NewOperation(tx.Name)
+		for _, stmt := range tx.Statements {
+			m.statements[stmt.Name] = stats.NewOperation(stmt.Name)
+		}
+	}
+	return m
+}
+
+func (m workloadMetrics) log(
+	logger zerolog.Logger,
+	def Definition,
+	elapsed time.Duration,
+	outages *testrunner.Outages,
+) {
+	for _, tx := range def.Transactions {
+		logOperation(logger, "transaction", m.transactions[tx.Name].Summarize(elapsed))
+		for _, stmt := range tx.Statements {
+			logOperation(logger, "statement", m.statements[stmt.Name].Summarize(elapsed))
+		}
+	}
+	logger.Info().
+		Int("reconnects", outages.Reconnects()).
+		Dur("downtime", outages.Downtime()).
+		Msg("Workload test finished")
+}
+
+func logOperation(logger zerolog.Logger, kind string, summary stats.OperationSummary) {
+	logger.Info().
+		Str(kind, summary.Name).
+		Int64("count", summary.Count).
+		Int64("errors", summary.Errors).
+		Interface("errors_by_class", summary.ErrorsByClass).
+		Int64("retries", summary.Retries).
+		Float64("per_sec", summary.PerSecond).
+		Float64("mean_ms", summary.Latency.MeanMs).
+		Float64("p50_ms", summary.Latency.P50Ms).
+		Float64("p95_ms", summary.Latency.P95Ms).
+		Float64("p99_ms", summary.Latency.P99Ms).
+		Float64("max_ms", summary.Latency.MaxMs).
+		Msgf("Results for %s %s", kind, summary.Name)
+}
+
+type workerStatement struct {
+	def     Statement
+	params  []generator
+	metrics *stats.Operation
+}
+
+type workerTransaction struct {
+	name       string
+	statements []workerStatement
+	metrics    *stats.Operation
+}
+
+// worker holds the state of one worker: its own random generator and parameter generators
+type worker struct {
+	r            *rand.Rand
+	policy       testrunner.RetryPolicy
+	transactions []workerTransaction
+	cumulative   []int64
+}
+
+func newWorker(def Definition, metrics workloadMetrics, r *rand.Rand, policy testrunner.RetryPolicy) *worker {
+	w := &worker{r: r, policy: policy}
+	var total int64
+	for _, tx := range def.Transactions {
+		wtx := workerTransaction{name: tx.Name, metrics: metrics.transactions[tx.Name]}
+		for _, stmt := range tx.Statements {
+			ws := workerStatement{def: stmt, metrics: metrics.statements[stmt.Name]}
+			for _, spec := range stmt.Params {
+				ws.params = append(ws.params, newGenerator(spec, r))
+			}
+			wtx.statements = append(wtx.statements, ws)
+		}
+		total += int64(tx.Weight)
+		w.transactions = append(w.transactions, wtx)
+		w.cumulative = append(w.cumulative, total)
+	}
+	return w
+}
+
+// pick returns a random transaction, taking the weights into account
+func (w *worker) pick() *workerTransaction {
+	n := w.r.Int63n(w.cumulative[len(w.cumulative)-1])
+	for i, limit := range w.cumulative {
+		if n < limit {
+			return &w.transactions[i]
+		}
+	}
+	return &w.transactions[len(w.transactions)-1]
+}
+
+func (w *worker) run(
+	ctx context.Context,
+	workerID int,
+	conn *dbinterface.Connection,
+	reconnector *testrunner.Reconnector,
+	measurement *testrunner.Measurement,
+) error {
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+		tx := w.pick()
+		measuring := measurement.Active()
+		start := time.Now()
+		class, err := w.policy.Run(ctx, *conn,
+			func() error { return tx.execute(ctx, *conn, measuring) },
+			func(class dbinterface.ErrorClass, retrying bool) {
+				if !measuring {
+					return
+				}
+				tx.metrics.FailWith(string(class))
+				if retrying {
+					tx.metrics.Retry()
+				}
+			})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if reconnector.Recover(ctx, workerID, conn, err) {
+				continue
+			}
+			if w.policy.Expected(class) {
+				continue
+			}
+			return fmt.Errorf("worker %d: %w", workerID, err)
+		}
+		if measuring {
+			tx.metrics.Observe(time.Since(start))
+		}
+	}
+}
+
+// execute runs the transaction once. Failed statements are counted here, the transaction itself is measured by
+// the caller, so that retries are included in its latency.
+func (tx *workerTransaction) execute(ctx context.Context, conn dbinterface.Connection, measuring bool) error {
+	if err := conn.Begin(ctx); err != nil {
+		return fmt.Errorf("begin of transaction %s failed: %w", tx.name, err)
+	}
+	for _, stmt := range tx.statements {
+		stmtStart := time.Now()
+		if err := stmt.execute(ctx, conn); err != nil {
+			_ = conn.Rollback(ctx)
+			if measuring {
+				stmt.metrics.FailWith(string(dbinterface.ClassifyError(conn, err)))
+			}
+			return fmt.Errorf("statement %s failed: %w", stmt.def.Name, err)
+		}
+		if measuring {
+			stmt.metrics.Observe(time.Since(stmtStart))
+		}
+	}
+	if err := conn.Commit(ctx); err != nil {
+		return fmt.Errorf("commit of transaction %s failed: %w", tx.name, err)
+	}
+	return nil
+}
+
+func (stmt *workerStatement) execute(ctx context.Context, conn dbinterface.Connection) error {
+	args := make([]any, len(stmt.params))
+	for i, gen := range stmt.params {
+		args[i] = gen()
+	}
+	if stmt.def.Kind == KindQuery {
+		_, err := conn.Query(ctx, stmt.def.SQL, args...)
+		return err
+	}
+	_, err := conn.Execute(ctx, stmt.def.SQL, args...)
+	return err
+}
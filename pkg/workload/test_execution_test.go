@@ -0,0 +1,122 @@
+package workload
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface"
+	"github.com/pgvillage-tools/dbtwool/pkg/dbinterface/fake"
+	"github.com/pgvillage-tools/dbtwool/pkg/testrunner"
+)
+
+var _ = Describe("Worker", func() {
+	It("should pick transactions according to their weight", func() {
+		def, err := ParseDefinition([]byte(`
+transactions:
+  - name: rare
+    statements: [{sql: SELECT 1}]
+  - name: common
+    weight: 9
+    statements: [{sql: SELECT 2}]
+`))
+		Ω(err).NotTo(HaveOccurred())
+		w := newWorker(def, newWorkloadMetrics(def), rand.New(rand.NewSource(1)), testrunner.RetryPolicy{})
+		picked := map[string]int{}
+		for range 10000 {
+			picked[w.pick().name]++
+		}
+		Ω(picked["common"]).To(BeNumerically("~", 9000, 300))
+		Ω(picked["rare"]).To(BeNumerically("~", 1000, 300))
+	})
+	It("should bind all parameters of a statement as arguments", func() {
+		def, err := ParseDefinition([]byte(`
+transactions:
+  - statements:
+      - sql: UPDATE t SET a = $1 WHERE b = $2
+        kind: exec
+        params: [{type: list, values: [x]}, {type: list, values: [7]}]
+`))
+		Ω(err).NotTo(HaveOccurred())
+		ctx := context.Background()
+		client := fake.NewClient()
+		pool, err := client.Pool(ctx)
+		Ω(err).NotTo(HaveOccurred())
+		conn, err := pool.Connect(ctx)
+		Ω(err).NotTo(HaveOccurred())
+		w := newWorker(def, newWorkloadMetrics(def), rand.New(rand.NewSource(1)), testrunner.RetryPolicy{})
+		Ω(w.transactions[0].statements[0].execute(ctx, conn)).To(Succeed())
+		calls := client.Script.Calls()
+		Ω(calls[len(calls)-1].Args).To(Equal([]any{"x", 7}))
+		Ω(calls[len(calls)-1].Payload).To(BeNil())
+	})
+	Context("ExecuteTest", func() {
+		ctx := context.Background()
+		def, err := ParseDefinition([]byte(`
+transactions:
+  - name: transfer
+    statements:
+      - {name: debit, sql: "UPDATE accounts SET balance = balance - 1 WHERE id = $1", kind: exec,
+         params: [{type: uniform, min: 1, max: 10}]}
+      - {name: balance, sql: "SELECT balance FROM accounts WHERE id = 1"}
+`))
+		Ω(err).NotTo(HaveOccurred())
+
+		It("should run the transactions on every connection until the test ends", func() {
+			client := fake.NewClient()
+			Ω(ExecuteTest(ctx, client, def, "1", 2, 1, 1, testrunner.RetryPolicy{}, testrunner.ReconnectPolicy{})).
+				To(Succeed())
+			Ω(client.Script.Count(fake.StmtConnect)).To(Equal(2))
+			Ω(client.Script.Count("UPDATE ACCOUNTS")).To(BeNumerically(">", 0))
+			Ω(client.Script.Count(fake.StmtCommit)).To(BeNumerically(">", 0))
+			pool, poolErr := client.Pool(ctx)
+			Ω(poolErr).NotTo(HaveOccurred())
+			Ω(pool.(*fake.Pool).Open()).To(BeZero())
+		})
+		It("should keep going on errors which are expected", func() {
+			client := fake.NewClient()
+			client.Script.On("UPDATE accounts").Times(1).
+				Fail(fake.NewError(dbinterface.ErrorClassDeadlock, "deadlock detected"))
+			policy := testrunner.RetryPolicy{RetryOn: []dbinterface.ErrorClass{dbinterface.ErrorClassDeadlock}}
+			Ω(ExecuteTest(ctx, client, def, "1", 1, 1, 1, policy, testrunner.ReconnectPolicy{})).To(Succeed())
+			// the end of the test may roll back one more transaction
+			Ω(client.Script.Count(fake.StmtRollback)).To(BeNumerically(">=", 1))
+			Ω(client.Script.Count("UPDATE accounts")).To(BeNumerically(">", 1))
+		})
+		It("should return the error of a failing statement", func() {
+			client := fake.NewClient()
+			client.Script.On("SELECT balance").Fail(errors.New("relation accounts does not exist"))
+			err := ExecuteTest(ctx, client, def, "1", 1, 1, 1, testrunner.RetryPolicy{}, testrunner.ReconnectPolicy{})
+			Ω(err).To(MatchError(ContainSubstring("statement balance failed")))
+		})
+		It("should return an error when a connection can not be opened", func() {
+			client := fake.NewClient()
+			client.Script.On(fake.StmtConnect).Fail(errors.New("connection refused"))
+			err := ExecuteTest(ctx, client, def, "1", 1, 1, 1, testrunner.RetryPolicy{}, testrunner.ReconnectPolicy{})
+			Ω(err).To(MatchError(ContainSubstring("connection refused")))
+		})
+		It("should reject invalid arguments", func() {
+			client := fake.NewClient()
+			Ω(ExecuteTest(ctx, client, def, "1", 0, 1, 1, testrunner.RetryPolicy{}, testrunner.ReconnectPolicy{})).
+				NotTo(Succeed())
+			Ω(ExecuteTest(ctx, client, def, "abc", 1, 1, 1, testrunner.RetryPolicy{}, testrunner.ReconnectPolicy{})).
+				NotTo(Succeed())
+			client.PoolErr = errors.New("invalid dsn")
+			Ω(ExecuteTest(ctx, client, def, "1", 1, 1, 1, testrunner.RetryPolicy{}, testrunner.ReconnectPolicy{})).
+				NotTo(Succeed())
+			Ω(client.Script.Calls()).To(BeEmpty())
+		})
+	})
+	Context("parseSeed", func() {
+		It("should parse integer seeds", func() {
+			Ω(parseSeed("42")).To(BeEquivalentTo(42))
+		})
+		It("should reject other seeds", func() {
+			_, err := parseSeed("abc")
+			Ω(err).To(HaveOccurred())
+		})
+	})
+})
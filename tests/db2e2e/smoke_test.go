@@ -130,6 +130,10 @@ var _ = Describe("Smoke", Ordered, func() {
 					dbtwoolLogs, logErr := containerLogs(ctx, dbtwoolCnt)
 					Ω(logErr).NotTo(HaveOccurred())
 					Ω(dbtwoolLogs).To(MatchRegexp(".*info.*finished.*"))
+					// The default isolation level is read committed, which DB2 runs as CS
+					if jobType == "ru-performance" && phase == "test" {
+						Ω(dbtwoolLogs).To(ContainSubstring("SET CURRENT ISOLATION CS"))
+					}
 				}
 			}
 		})
@@ -9,6 +9,8 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/pgvillage-tools/dbtwool/pkg/history"
+	"github.com/pgvillage-tools/dbtwool/pkg/result"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/network"
 )
@@ -104,6 +106,10 @@ var _ = Describe("Smoke", Ordered, func() {
 					dbtwoolLogs, logErr := containerLogs(ctx, dbtwoolCnt)
 					Ω(logErr).NotTo(HaveOccurred())
 					Ω(dbtwoolLogs).To(MatchRegexp(".*info.*finished.*"))
+					// The default isolation level is read committed, which PostgreSQL runs as READ COMMITTED
+					if jobType == "ru-performance" && phase == "test" {
+						Ω(dbtwoolLogs).To(ContainSubstring("ISOLATION LEVEL READ COMMITTED"))
+					}
 				}
 			}
 		})
@@ -123,4 +129,22 @@ var _ = Describe("Smoke", Ordered, func() {
 			Ω(isReadyErr).NotTo(HaveOccurred())
 		})
 	})
+	Context("when keeping the history in Postgres", func() {
+		It("should create the history, and save, list and delete runs", func() {
+			natPort, err := pgCnt.MappedPort(ctx,
+				fmt.Sprintf("%d/tcp", pgPort))
+			Ω(err).NotTo(HaveOccurred())
+			dsn := pgConn.setParam("port", natPort.Port()).connString()
+			store, err := history.Open(ctx, history.Config{DSN: dsn})
+			Ω(err).NotTo(HaveOccurred())
+			defer func() { Ω(store.Close(ctx)).To(Succeed()) }()
+
+			id, err := store.Save(ctx, result.Document{Test: "lob-performance", RDBMS: "pg"})
+			Ω(err).NotTo(HaveOccurred())
+			entries, err := store.List(ctx, "lob-performance")
+			Ω(err).NotTo(HaveOccurred())
+			Ω(entries).To(ContainElement(HaveField("ID", id)))
+			Ω(store.Delete(ctx, id)).To(Succeed())
+		})
+	})
 })